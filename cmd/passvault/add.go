@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// entryFlags are the flags shared by add and edit. Secrets are never taken
+// from flags, where other users could see them in the process list; they
+// are read from stdin instead.
+type entryFlags struct {
+	asJSON    *bool
+	title     *string
+	username  *string
+	url       *string
+	notes     *string
+	folder    *string
+	tags      stringList
+	generate  *bool
+	totpStdin *bool
+}
+
+func newEntryFlags(fs *flag.FlagSet) *entryFlags {
+	ef := &entryFlags{
+		asJSON:    fs.Bool("json", false, "print the entry ID as JSON"),
+		title:     fs.String("title", "", "title of the entry"),
+		username:  fs.String("username", "", "username"),
+		url:       fs.String("url", "", "URL"),
+		notes:     fs.String("notes", "", "notes"),
+		folder:    fs.String("folder", "", "folder path, empty for the top level"),
+		generate:  fs.Bool("generate", false, "generate the password instead of reading it"),
+		totpStdin: fs.Bool("totp", false, "read a TOTP secret or otpauth URI from stdin after the password"),
+	}
+	fs.Var(&ef.tags, "tag", "tag, may be repeated")
+	return ef
+}
+
+// readEntryPassword generates a password or reads it from stdin.
+func readEntryPassword(passwordGen *domain.PasswordGenerator, generate bool) (string, error) {
+	if generate {
+		return passwordGen.GenerateWithOptions(domain.DefaultPasswordOptions())
+	}
+
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	if len(password) == 0 {
+		return "", errors.New("password must not be empty")
+	}
+	return string(password), nil
+}
+
+func printEntryID(asJSON bool, id, action string) error {
+	if asJSON {
+		return writeJSON(map[string]string{"id": id})
+	}
+	fmt.Printf("%s entry %s.\n", action, id)
+	return nil
+}
+
+// runAdd creates a login entry.
+func runAdd(createEntryUc *service.CreateEntryUsecase, listFoldersUc *service.ListFoldersUsecase, passwordGen *domain.PasswordGenerator, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	ef := newEntryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *ef.title == "" {
+		return newUsageError("passvault add [-json] -title title [-username name] [-url url] [-notes text] [-folder path] [-tag tag] [-generate] [-totp]")
+	}
+
+	folderID, err := resolveFolder(listFoldersUc, *ef.folder)
+	if err != nil {
+		return err
+	}
+
+	input := service.EntryInput{
+		Type:     domain.EntryLogin,
+		Title:    *ef.title,
+		Username: *ef.username,
+		URL:      *ef.url,
+		Notes:    *ef.notes,
+		Tags:     ef.tags,
+		FolderID: folderID,
+	}
+	if input.Password, err = readEntryPassword(passwordGen, *ef.generate); err != nil {
+		return err
+	}
+	if *ef.totpStdin {
+		secret, err := readPassword("TOTP secret: ")
+		if err != nil {
+			return fmt.Errorf("failed to read TOTP secret: %w", err)
+		}
+		input.TOTP = string(secret)
+	}
+
+	id, err := createEntryUc.Execute(input)
+	if err != nil {
+		return err
+	}
+	return printEntryID(*ef.asJSON, id, "Created")
+}
+
+// runEdit changes the fields given by flags and keeps the others.
+func runEdit(findEntryUc *service.FindEntryUsecase, updateEntryUc *service.UpdateEntryUsecase, listFoldersUc *service.ListFoldersUsecase, passwordGen *domain.PasswordGenerator, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	ef := newEntryFlags(fs)
+	password := fs.Bool("password", false, "read a new password from stdin")
+	noTOTP := fs.Bool("no-totp", false, "remove the TOTP secret")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*password && *ef.generate) || (*noTOTP && *ef.totpStdin) {
+		return newUsageError("passvault edit [-json] [-title title] [-username name] [-url url] [-notes text] [-folder path] [-tag tag] [-password | -generate] [-totp | -no-totp] <id|title>")
+	}
+
+	en, err := findEntryUc.Execute(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Prompts and folder lookups happen before the vault is locked; only the
+	// fields given by flags are applied to the entry as it is when saved.
+	var folderID string
+	var folderErr error
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "folder" {
+			folderID, folderErr = resolveFolder(listFoldersUc, *ef.folder)
+		}
+	})
+	if folderErr != nil {
+		return folderErr
+	}
+	var newPassword, newTOTP string
+	if *password || *ef.generate {
+		if newPassword, err = readEntryPassword(passwordGen, *ef.generate); err != nil {
+			return err
+		}
+	}
+	if *ef.totpStdin {
+		secret, err := readPassword("TOTP secret: ")
+		if err != nil {
+			return fmt.Errorf("failed to read TOTP secret: %w", err)
+		}
+		newTOTP = string(secret)
+	}
+
+	err = updateEntryUc.Edit(en.ID, func(input *service.EntryInput) {
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				input.Title = *ef.title
+			case "username":
+				input.Username = *ef.username
+			case "url":
+				input.URL = *ef.url
+			case "notes":
+				input.Notes = *ef.notes
+			case "tag":
+				input.Tags = ef.tags
+			case "folder":
+				input.FolderID = folderID
+			}
+		})
+		if *noTOTP {
+			input.TOTP = ""
+		}
+		if *password || *ef.generate {
+			input.Password = newPassword
+		}
+		if *ef.totpStdin {
+			input.TOTP = newTOTP
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return printEntryID(*ef.asJSON, en.ID, "Updated")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// runAudit prints the vault audit. It fails when issues are found, so that
+// it can be used as a check in scripts.
+func runAudit(auditVaultUc *service.AuditVaultUsecase, opts domain.AuditOptions, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	maxAge := fs.Int("max-age", int(opts.MaxPasswordAge/(24*time.Hour)), "report passwords older than this many days, 0 to disable")
+	minScore := fs.Int("min-score", opts.MinScore, "report passwords with a lower strength score (0-4)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newUsageError("passvault audit [-json] [-max-age days] [-min-score n]")
+	}
+	if *maxAge < 0 || *minScore < 0 || *minScore > 4 {
+		return errors.New("max-age must not be negative and min-score must be between 0 and 4")
+	}
+
+	opts.MaxPasswordAge = time.Duration(*maxAge) * 24 * time.Hour
+	opts.MinScore = *minScore
+
+	report, err := auditVaultUc.Execute(opts)
+	if err != nil {
+		return fmt.Errorf("failed to audit vault: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if len(report.Findings) == 0 {
+		fmt.Printf("Checked %d entries, no issues found.\n", report.Entries)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ISSUE\tID\tTITLE\tDETAIL")
+		for _, finding := range report.Findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", finding.Issue, finding.EntryID, finding.Title, finding.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(report.Findings) > 0 {
+		return fmt.Errorf("audit found %d issues", len(report.Findings))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ritarock/passvault/storage"
+)
+
+func runBackup(vaultRepo *storage.FileVaultRepository, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("passvault backup <list|restore>")
+	}
+
+	switch args[0] {
+	case "list":
+		return listBackups(vaultRepo.Backups())
+	case "restore":
+		return restoreBackup(vaultRepo, args[1:])
+	default:
+		return &usageError{msg: "unknown backup command: " + args[0]}
+	}
+}
+
+func listBackups(backups *storage.BackupManager) error {
+	list, err := backups.List()
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No backups yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tSIZE")
+	for _, backup := range list {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", backup.ID, backup.CreatedAt.Local().Format("2006-01-02 15:04:05"), backup.Size)
+	}
+	return w.Flush()
+}
+
+func restoreBackup(vaultRepo *storage.FileVaultRepository, args []string) error {
+	fs := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("passvault backup restore [-yes] <id>")
+	}
+
+	backup, err := vaultRepo.Backups().Find(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		prompt := fmt.Sprintf("Replace the vault with the backup from %s? [y/N]: ", backup.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		ok, err := confirm(prompt)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	if _, err := vaultRepo.Restore(backup.ID); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	fmt.Printf("Restored backup %s. The replaced vault was kept as a new backup.\n", backup.ID)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+	"github.com/ritarock/passvault/storage"
+)
+
+// runBreachCheck looks every login password up in a local copy of the
+// Pwned Passwords hashes. Like audit, it fails when passwords are found.
+func runBreachCheck(auditVaultUc *service.AuditVaultUsecase, defaultDB string, args []string) error {
+	fs := flag.NewFlagSet("breach-check", flag.ContinueOnError)
+	dbPath := fs.String("db", defaultDB, "sorted SHA-1 hash file or directory of range files")
+	asJSON := fs.Bool("json", false, "print the breached entries as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *dbPath == "" {
+		return newUsageError("passvault breach-check [-json] -db <path>")
+	}
+
+	breachDB, err := storage.OpenBreachDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer breachDB.Close()
+
+	report, err := auditVaultUc.Execute(domain.AuditOptions{Breaches: breachDB})
+	if err != nil {
+		return fmt.Errorf("failed to check passwords: %w", err)
+	}
+
+	findings := []domain.AuditFinding{}
+	for _, finding := range report.Findings {
+		if finding.Issue == domain.AuditBreached {
+			findings = append(findings, finding)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else if len(findings) == 0 {
+		fmt.Printf("Checked %d entries, no breached passwords found.\n", report.Entries)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTITLE\tDETAIL")
+		for _, finding := range findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", finding.EntryID, finding.Title, finding.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d breached passwords found", len(findings))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/ritarock/passvault/service"
+)
+
+// runCopy copies a field of an entry to the clipboard, so that it does not
+// show up on screen or in the shell history.
+func runCopy(findEntryUc *service.FindEntryUsecase, getEntryUc *service.GetEntryUsecase, args []string) error {
+	fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the copied entry and field as JSON")
+	field := fs.String("field", "password", "field to copy: username, password, url, notes, totp, a card, identity or SSH key field, or a custom field name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("passvault copy [-json] [-field name] <id|title>")
+	}
+
+	found, err := findEntryUc.Execute(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	en, err := getEntryUc.Execute(found.ID)
+	if err != nil {
+		return err
+	}
+
+	value, err := en.Field(*field, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := clipboard.WriteAll(value); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", *field, err)
+	}
+
+	if *asJSON {
+		return writeJSON(map[string]string{"id": en.ID, "field": *field})
+	}
+	fmt.Fprintf(os.Stderr, "Copied the %s of %s to the clipboard.\n", *field, en.Title)
+	return nil
+}
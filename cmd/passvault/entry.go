@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// stringList is a flag that may be given several times. Each value may also
+// hold a comma-separated list.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// folderPaths returns the full path of every folder by ID.
+func folderPaths(listFoldersUc *service.ListFoldersUsecase) (map[string]string, error) {
+	folders, err := listFoldersUc.Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+
+	// Folders are listed parents first.
+	paths := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		if parent, ok := paths[folder.ParentID]; ok {
+			paths[folder.ID] = parent + domain.FolderSeparator + folder.Name
+		} else {
+			paths[folder.ID] = folder.Name
+		}
+	}
+	return paths, nil
+}
+
+// resolveFolder returns the ID of the folder at path. An empty path is the
+// top level.
+func resolveFolder(listFoldersUc *service.ListFoldersUsecase, path string) (string, error) {
+	path = strings.Trim(path, domain.FolderSeparator)
+	if path == "" {
+		return "", nil
+	}
+
+	paths, err := folderPaths(listFoldersUc)
+	if err != nil {
+		return "", err
+	}
+	for id, folderPath := range paths {
+		if folderPath == path {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrFolderNotFound, path)
+}
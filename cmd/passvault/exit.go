@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/storage"
+)
+
+// Exit codes are part of the command line interface; scripts may rely on
+// them, so existing values must not change.
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitUsage     = 2
+	exitNotFound  = 3
+	exitAmbiguous = 4
+	exitDecrypt   = 5
+	exitLocked    = 6
+)
+
+// usageError reports invalid arguments or flags.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func newUsageError(usage string) error {
+	return &usageError{msg: "usage: " + usage}
+}
+
+// exitCode maps an error returned by run to the process exit code.
+func exitCode(err error) int {
+	var usageErr *usageError
+	var lockedErr *storage.LockedError
+	var statusErr *exitStatusError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &statusErr):
+		return statusErr.code
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, domain.ErrEntryNotFound),
+		errors.Is(err, domain.ErrFieldNotFound),
+		errors.Is(err, domain.ErrFolderNotFound),
+		errors.Is(err, domain.ErrTagNotFound),
+		errors.Is(err, storage.ErrBackupNotFound),
+		errors.Is(err, storage.ErrSlotNotFound):
+		return exitNotFound
+	case errors.Is(err, domain.ErrAmbiguousEntry):
+		return exitAmbiguous
+	case errors.Is(err, domain.ErrInvalidPassword),
+		errors.Is(err, storage.ErrInvalidSlotSecret),
+		errors.Is(err, storage.ErrDecryptionFailed),
+		errors.Is(err, storage.ErrBackupUndecryptable):
+		return exitDecrypt
+	case errors.As(err, &lockedErr):
+		return exitLocked
+	default:
+		return exitError
+	}
+}
+
+// parseFlags parses args like fs.Parse but also accepts flags after the
+// positional arguments, as in "passvault show github -json". Parsing stops
+// at "--". The positional arguments are left in fs.Args.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return &usageError{msg: err.Error()}
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	return fs.Parse(append([]string{"--"}, positional...))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ritarock/passvault/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnlock_PasswordFromStdin replaces the prompt globals, so it must not
+// run in parallel.
+func TestUnlock_PasswordFromStdin(t *testing.T) {
+	keyManager := storage.NewKeyManager(t.TempDir())
+	cryptoSvc := storage.NewAESEncryptor(keyManager)
+	require.NoError(t, cryptoSvc.InitializeKey([]byte("secret")))
+
+	pipe, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer pipe.Close()
+	defer w.Close()
+
+	oldInput, oldReader := promptInput, stdinReader
+	defer func() { promptInput, stdinReader = oldInput, oldReader }()
+
+	tests := []struct {
+		name     string
+		input    string
+		wantCode int
+		wantRest string
+	}{
+		{
+			name:     "succeed: correct password",
+			input:    "secret\nentry secret\n",
+			wantCode: exitOK,
+			wantRest: "entry secret\n",
+		},
+		{
+			name:     "failed: wrong password is not retried",
+			input:    "wrong\nsecret\n",
+			wantCode: exitDecrypt,
+			wantRest: "secret\n",
+		},
+		{
+			name:     "failed: wrong password as the only line",
+			input:    "wrong\n",
+			wantCode: exitDecrypt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			promptInput = pipe
+			stdinReader = bufio.NewReader(strings.NewReader(test.input))
+
+			err := unlock(cryptoSvc)
+			if err != nil {
+				err = fmt.Errorf("failed to unlock vault: %w", err)
+			}
+			assert.Equal(t, test.wantCode, exitCode(err))
+
+			rest, _ := stdinReader.ReadString(0)
+			assert.Equal(t, test.wantRest, rest)
+		})
+	}
+}
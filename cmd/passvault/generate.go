@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+// runGenerate prints a random password or passphrase. The flags mirror the
+// password options dialog of the TUI.
+func runGenerate(passwordGen *domain.PasswordGenerator, args []string) error {
+	defaults := domain.DefaultPasswordOptions()
+
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the password and its entropy as JSON")
+	passphrase := fs.Bool("passphrase", false, "generate a passphrase from a wordlist")
+
+	opts := defaults
+	fs.IntVar(&opts.Length, "length", defaults.Length, "password length (8-64)")
+	fs.BoolVar(&opts.IncludeLowercase, "lower", defaults.IncludeLowercase, "include lowercase letters")
+	fs.BoolVar(&opts.IncludeUppercase, "upper", defaults.IncludeUppercase, "include uppercase letters")
+	fs.BoolVar(&opts.IncludeDigits, "digits", defaults.IncludeDigits, "include digits")
+	fs.BoolVar(&opts.IncludeSymbols, "symbols", defaults.IncludeSymbols, "include symbols")
+	fs.IntVar(&opts.MinLowercase, "min-lower", 0, "minimum number of lowercase letters")
+	fs.IntVar(&opts.MinUppercase, "min-upper", 0, "minimum number of uppercase letters")
+	fs.IntVar(&opts.MinDigits, "min-digits", 0, "minimum number of digits")
+	fs.IntVar(&opts.MinSymbols, "min-symbols", 0, "minimum number of symbols")
+	fs.StringVar(&opts.Symbols, "symbol-set", "", "symbols to use instead of the default set")
+	fs.BoolVar(&opts.ExcludeAmbiguous, "exclude-ambiguous", false, "leave out 0, O, 1, l and I")
+	fs.StringVar(&opts.ExcludeChars, "exclude", "", "characters to leave out")
+	fs.IntVar(&opts.Passphrase.Words, "words", defaults.Passphrase.Words, "number of passphrase words (3-20)")
+	fs.StringVar(&opts.Passphrase.Separator, "separator", defaults.Passphrase.Separator, "passphrase word separator")
+	fs.BoolVar(&opts.Passphrase.Capitalize, "capitalize", false, "capitalize the passphrase words")
+	fs.BoolVar(&opts.Passphrase.IncludeDigit, "add-digit", false, "add a digit to a passphrase word")
+	fs.BoolVar(&opts.Passphrase.IncludeSymbol, "add-symbol", false, "add a symbol to a passphrase word")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newUsageError("passvault generate [-json] [-passphrase] [options]")
+	}
+
+	if *passphrase {
+		opts.Mode = domain.PasswordModePassphrase
+	}
+	if err := opts.Validate(); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+
+	password, err := passwordGen.GenerateWithOptions(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	if *asJSON {
+		return writeJSON(struct {
+			Password string  `json:"password"`
+			Entropy  float64 `json:"entropy_bits"`
+		}{password, passwordGen.Entropy(opts)})
+	}
+	fmt.Println(password)
+	return nil
+}
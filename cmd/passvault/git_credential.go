@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// runGitCredential implements the git credential helper protocol, see
+// gitcredentials(7). git writes the request to stdin as key=value lines and
+// reads the answer of get from stdout.
+func runGitCredential(findCredentialsUc *service.FindCredentialsUsecase, storeCredentialUc *service.StoreCredentialUsecase, deleteEntryUc *service.DeleteEntryUsecase, args []string) error {
+	fs := flag.NewFlagSet("git-credential", flag.ContinueOnError)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("passvault git-credential <get|store|erase>")
+	}
+
+	attrs, err := readCredentialAttributes(os.Stdin)
+	if err != nil {
+		return err
+	}
+	credential := domain.Credential{
+		Protocol: attrs["protocol"],
+		Host:     attrs["host"],
+		Path:     attrs["path"],
+		Username: attrs["username"],
+	}
+	if u, err := url.Parse(attrs["url"]); attrs["url"] != "" && credential.Host == "" && err == nil {
+		credential.Protocol, credential.Host, credential.Path = u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")
+		if credential.Username == "" {
+			credential.Username = u.User.Username()
+		}
+	}
+	if credential.Host == "" {
+		// Nothing to match on, let git try its other helpers.
+		return nil
+	}
+
+	switch fs.Arg(0) {
+	case "get":
+		return getGitCredential(findCredentialsUc, credential)
+	case "store":
+		return storeGitCredential(storeCredentialUc, credential, attrs["password"])
+	case "erase":
+		return eraseGitCredential(findCredentialsUc, deleteEntryUc, credential, attrs["password"])
+	default:
+		// Helpers must ignore operations they do not know.
+		return nil
+	}
+}
+
+// readCredentialAttributes reads key=value lines up to a blank line or the
+// end of the input.
+func readCredentialAttributes(r io.Reader) (map[string]string, error) {
+	attrs := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid credential line: %q", line)
+		}
+		attrs[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credential: %w", err)
+	}
+	return attrs, nil
+}
+
+func getGitCredential(findCredentialsUc *service.FindCredentialsUsecase, credential domain.Credential) error {
+	entries, err := findCredentialsUc.Execute(credential)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	en := entries[0]
+	if strings.ContainsAny(en.Username+en.Password, "\n\x00") {
+		return errors.New("the username or password contains a line break, which git cannot accept")
+	}
+	fmt.Printf("username=%s\npassword=%s\n", en.Username, en.Password)
+	return nil
+}
+
+// storeGitCredential updates the password of the best matching login of the
+// user or creates a login for the host.
+func storeGitCredential(storeCredentialUc *service.StoreCredentialUsecase, credential domain.Credential, password string) error {
+	if credential.Username == "" || password == "" {
+		return nil
+	}
+
+	return storeCredentialUc.Execute(credential, password)
+}
+
+// eraseGitCredential moves the logins git rejected to the trash. Only logins
+// with the rejected password are affected, and they can be restored from the
+// trash.
+func eraseGitCredential(findCredentialsUc *service.FindCredentialsUsecase, deleteEntryUc *service.DeleteEntryUsecase, credential domain.Credential, password string) error {
+	if credential.Username == "" || password == "" {
+		return nil
+	}
+
+	entries, err := findCredentialsUc.Execute(credential)
+	if err != nil {
+		return err
+	}
+	for _, en := range entries {
+		if en.Password != password {
+			continue
+		}
+		if err := deleteEntryUc.Execute(en.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ritarock/passvault/service"
+	"github.com/ritarock/passvault/storage"
+)
+
+// runInject renders a template with values from the vault. The output file
+// is replaced atomically and only readable by its owner.
+func runInject(renderTemplateUc *service.RenderTemplateUsecase, args []string) error {
+	fs := flag.NewFlagSet("inject", flag.ContinueOnError)
+	input := fs.String("i", "-", "template file, - for stdin")
+	output := fs.String("o", "-", "output file, - for stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newUsageError("passvault inject [-i template] [-o output]")
+	}
+
+	var text []byte
+	var err error
+	name := filepath.Base(*input)
+	if *input == "-" {
+		name = "stdin"
+		text, err = io.ReadAll(stdinReader)
+	} else {
+		text, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	rendered, err := renderTemplateUc.Execute(name, string(text))
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err := os.Stdout.Write(rendered)
+		return err
+	}
+	if err := storage.WriteSecretFile(*output, rendered); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
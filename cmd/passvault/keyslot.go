@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ritarock/passvault/storage"
+)
+
+func runKeyslot(keyManager *storage.KeyManager, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("passvault keyslot <list|add|revoke>")
+	}
+
+	switch args[0] {
+	case "list":
+		return listKeyslots(keyManager)
+	case "add":
+		return addKeyslot(keyManager, args[1:])
+	case "revoke":
+		if len(args) != 2 {
+			return newUsageError("passvault keyslot revoke <id>")
+		}
+		return revokeKeyslot(keyManager, args[1])
+	default:
+		return &usageError{msg: "unknown keyslot command: " + args[0]}
+	}
+}
+
+func listKeyslots(keyManager *storage.KeyManager) error {
+	slots, err := keyManager.Slots()
+	if err != nil {
+		return fmt.Errorf("failed to list key slots: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tKDF\tCREATED")
+	for _, slot := range slots {
+		kdf := fmt.Sprintf("%s t=%d m=%dKiB p=%d", slot.KDF.Algorithm, slot.KDF.Time, slot.KDF.Memory, slot.KDF.Threads)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", slot.ID, slot.Type, kdf, slot.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func addKeyslot(keyManager *storage.KeyManager, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("passvault keyslot add <password|keyfile|recovery>")
+	}
+
+	slotType, err := storage.ParseSlotType(args[0])
+	if err != nil {
+		return err
+	}
+
+	var secret []byte
+	var recoveryCode string
+	switch slotType {
+	case storage.SlotPassword:
+		secret, err = readNewPassword("New master password: ")
+		if err != nil {
+			return err
+		}
+	case storage.SlotKeyfile:
+		if len(args) != 2 {
+			return newUsageError("passvault keyslot add keyfile <path>")
+		}
+		path := args[1]
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := storage.GenerateKeyfile(path); err != nil {
+				return fmt.Errorf("failed to create keyfile: %w", err)
+			}
+			fmt.Printf("Created keyfile %s\n", path)
+		}
+		secret, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read keyfile: %w", err)
+		}
+	case storage.SlotRecovery:
+		recoveryCode, err = storage.GenerateRecoveryCode()
+		if err != nil {
+			return fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		secret = []byte(recoveryCode)
+	}
+
+	slot, err := keyManager.AddSlot(slotType, secret)
+	if err != nil {
+		return fmt.Errorf("failed to add key slot: %w", err)
+	}
+
+	fmt.Printf("Added %s key slot %s\n", slot.Type, slot.ID)
+	if recoveryCode != "" {
+		fmt.Printf("Recovery code: %s\n", recoveryCode)
+		fmt.Println("Store it somewhere safe. It will not be shown again.")
+	}
+	return nil
+}
+
+func revokeKeyslot(keyManager *storage.KeyManager, id string) error {
+	slot, err := keyManager.RevokeSlot(id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke key slot: %w", err)
+	}
+
+	fmt.Printf("Revoked %s key slot %s\n", slot.Type, slot.ID)
+	return nil
+}
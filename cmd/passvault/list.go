@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// entrySummary is an entry as listed by ls. It leaves out every secret.
+type entrySummary struct {
+	ID        string           `json:"id"`
+	Type      domain.EntryType `json:"type"`
+	Title     string           `json:"title"`
+	Username  string           `json:"username,omitempty"`
+	URL       string           `json:"url,omitempty"`
+	Folder    string           `json:"folder,omitempty"`
+	Tags      []string         `json:"tags,omitempty"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// runList lists the entries sorted by title, so that the output is stable
+// for scripts.
+func runList(listEntriesUc *service.ListEntriesUsecase, listFoldersUc *service.ListFoldersUsecase, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the entries as JSON")
+	folder := fs.String("folder", "", "list the entries in this folder path and its subfolders")
+	entryType := fs.String("type", "", "list the entries of this type")
+	var tags stringList
+	fs.Var(&tags, "tag", "list the entries carrying this tag, may be repeated")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newUsageError("passvault ls [-json] [-folder path] [-tag tag] [-type type]")
+	}
+
+	filter := domain.EntryFilter{Tags: tags, TagMatch: domain.TagMatchAll}
+	if *entryType != "" {
+		t, err := domain.ParseEntryType(*entryType)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		filter.Type = t
+	}
+	folderID, err := resolveFolder(listFoldersUc, *folder)
+	if err != nil {
+		return err
+	}
+	filter.FolderID = folderID
+
+	entries, err := listEntriesUc.ExecuteWithFilter(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	paths, err := folderPaths(listFoldersUc)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		ti, tj := strings.ToLower(entries[i].Title), strings.ToLower(entries[j].Title)
+		if ti != tj {
+			return ti < tj
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	summaries := make([]entrySummary, 0, len(entries))
+	for _, en := range entries {
+		summaries = append(summaries, entrySummary{
+			ID:        en.ID,
+			Type:      en.Type,
+			Title:     en.Title,
+			Username:  en.Username,
+			URL:       en.URL,
+			Folder:    paths[en.FolderID],
+			Tags:      en.Tags,
+			UpdatedAt: en.UpdatedAt,
+		})
+	}
+
+	if *asJSON {
+		return writeJSON(summaries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tUSERNAME\tFOLDER")
+	for _, summary := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", summary.ID, summary.Title, summary.Username, summary.Folder)
+	}
+	return w.Flush()
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -10,19 +12,36 @@ import (
 	"github.com/ritarock/passvault/service"
 	"github.com/ritarock/passvault/storage"
 	"github.com/ritarock/passvault/tui"
+	"golang.org/x/term"
 )
 
 const (
-	AppDir = ".passvault"
+	AppDir         = ".passvault"
+	UnlockAttempts = 3
 )
 
 func main() {
 	if err := run(); err != nil {
-		log.Fatalf("Error: %v\n", err)
+		// The child of passvault run has reported its own errors.
+		var statusErr *exitStatusError
+		if !errors.Is(err, flag.ErrHelp) && !errors.As(err, &statusErr) {
+			log.Printf("Error: %v\n", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
 func run() error {
+	keyfile := flag.String("keyfile", "", "unlock the vault with a keyfile")
+	recovery := flag.Bool("recovery", false, "unlock the vault with a recovery code")
+	flag.Usage = usage
+	flag.Parse()
+
+	// Estimating a password does not need the vault.
+	if flag.Arg(0) == "strength" {
+		return runStrength(flag.Args()[1:])
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
@@ -30,21 +49,62 @@ func run() error {
 
 	baseDir := filepath.Join(homeDir, AppDir)
 
+	config, err := storage.LoadConfig(baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	passwordGen, err := config.Generator.PasswordGenerator()
+	if err != nil {
+		return err
+	}
+
+	// Neither does generating one.
+	if flag.Arg(0) == "generate" {
+		return runGenerate(passwordGen, flag.Args()[1:])
+	}
+
+	// git owns the stdin of a credential helper.
+	if flag.Arg(0) == "git-credential" && *keyfile == "" {
+		closeTerminal, err := promptFromTerminal()
+		if err != nil {
+			return err
+		}
+		defer closeTerminal()
+	}
+
 	keyManager := storage.NewKeyManager(baseDir)
 	cryptoSvc := storage.NewAESEncryptor(keyManager)
 	vaultRepo := storage.NewFileVaultRepository(baseDir, cryptoSvc)
+	vaultRepo.Backups().SetRetention(config.Backup)
 
-	if !cryptoSvc.KeyExists() {
+	switch {
+	case keyManager.LegacyKeyExists():
+		if err := migrateLegacyKey(keyManager, vaultRepo); err != nil {
+			return fmt.Errorf("failed to migrate key: %w", err)
+		}
+	case !cryptoSvc.KeyExists():
 		if err := initialize(cryptoSvc, vaultRepo); err != nil {
 			return fmt.Errorf("failed to initialize: %w", err)
 		}
+	case *keyfile != "":
+		if err := unlockWithKeyfile(keyManager, *keyfile); err != nil {
+			return fmt.Errorf("failed to unlock vault: %w", err)
+		}
+	case *recovery:
+		if err := unlockWithRecoveryCode(keyManager); err != nil {
+			return fmt.Errorf("failed to unlock vault: %w", err)
+		}
+	default:
+		if err := unlock(cryptoSvc); err != nil {
+			return fmt.Errorf("failed to unlock vault: %w", err)
+		}
 	}
 
-	if !vaultRepo.Exists() {
-		vault := domain.NewVault()
-		if err := vaultRepo.Save(vault); err != nil {
-			return fmt.Errorf("failed to create vault: %w", err)
-		}
+	if err := withVaultLock(vaultRepo, func() error {
+		return prepareVault(keyManager, vaultRepo)
+	}); err != nil {
+		return err
 	}
 
 	listEntriesUc := service.NewListEntriesUsecase(vaultRepo)
@@ -52,6 +112,81 @@ func run() error {
 	createEntryUc := service.NewCreateEntryUsecase(vaultRepo)
 	updateEntryUc := service.NewUpdateEntryUsecase(vaultRepo)
 	deleteEntryUc := service.NewDeleteEntryUsecase(vaultRepo)
+	restorePasswordUc := service.NewRestorePasswordUsecase(vaultRepo)
+	listTagsUc := service.NewListTagsUsecase(vaultRepo)
+	renameTagUc := service.NewRenameTagUsecase(vaultRepo)
+	listFoldersUc := service.NewListFoldersUsecase(vaultRepo)
+	createFolderUc := service.NewCreateFolderUsecase(vaultRepo)
+	renameFolderUc := service.NewRenameFolderUsecase(vaultRepo)
+	moveFolderUc := service.NewMoveFolderUsecase(vaultRepo)
+	deleteFolderUc := service.NewDeleteFolderUsecase(vaultRepo)
+	moveEntryUc := service.NewMoveEntryUsecase(vaultRepo)
+	listTrashUc := service.NewListTrashUsecase(vaultRepo)
+	restoreEntryUc := service.NewRestoreEntryUsecase(vaultRepo)
+	purgeEntryUc := service.NewPurgeEntryUsecase(vaultRepo)
+	purgeTrashUc := service.NewPurgeTrashUsecase(vaultRepo)
+	auditVaultUc := service.NewAuditVaultUsecase(vaultRepo, domain.NewStrengthEstimator())
+	findEntryUc := service.NewFindEntryUsecase(vaultRepo)
+	resolveReferencesUc := service.NewResolveReferencesUsecase(vaultRepo)
+	renderTemplateUc := service.NewRenderTemplateUsecase(vaultRepo)
+	findCredentialsUc := service.NewFindCredentialsUsecase(vaultRepo)
+	storeCredentialUc := service.NewStoreCredentialUsecase(vaultRepo)
+
+	args := flag.Args()
+	if len(args) > 0 {
+		switch args[0] {
+		case "keyslot":
+			return withVaultLock(vaultRepo, func() error {
+				return runKeyslot(keyManager, args[1:])
+			})
+		case "rotate-key":
+			return withVaultLock(vaultRepo, func() error {
+				return runRotateKey(keyManager, vaultRepo, *keyfile, args[1:])
+			})
+		case "backup":
+			return withVaultLock(vaultRepo, func() error {
+				return runBackup(vaultRepo, args[1:])
+			})
+		case "audit":
+			auditOpts, closeBreachDB := auditOptions(config.Audit)
+			defer closeBreachDB()
+			return runAudit(auditVaultUc, auditOpts, args[1:])
+		case "breach-check":
+			return runBreachCheck(auditVaultUc, config.Audit.BreachDB, args[1:])
+		case "ls":
+			return runList(listEntriesUc, listFoldersUc, args[1:])
+		case "show":
+			return runShow(findEntryUc, getEntryUc, listFoldersUc, args[1:])
+		case "add":
+			return runAdd(createEntryUc, listFoldersUc, passwordGen, args[1:])
+		case "edit":
+			return runEdit(findEntryUc, updateEntryUc, listFoldersUc, passwordGen, args[1:])
+		case "rm":
+			return runRemove(findEntryUc, deleteEntryUc, args[1:])
+		case "copy":
+			return runCopy(findEntryUc, getEntryUc, args[1:])
+		case "run":
+			return runRun(resolveReferencesUc, args[1:])
+		case "inject":
+			return runInject(renderTemplateUc, args[1:])
+		case "git-credential":
+			return runGitCredential(findCredentialsUc, storeCredentialUc, deleteEntryUc, args[1:])
+		case "ssh-agent":
+			return runSSHAgent(listEntriesUc, baseDir, args[1:])
+		default:
+			usage()
+			return &usageError{msg: "unknown command: " + args[0]}
+		}
+	}
+
+	if config.Trash.PurgeAfterDays > 0 {
+		if _, err := purgeTrashUc.Execute(config.Trash.PurgeAfter()); err != nil {
+			return fmt.Errorf("failed to purge trash: %w", err)
+		}
+	}
+
+	auditOpts, closeBreachDB := auditOptions(config.Audit)
+	defer closeBreachDB()
 
 	app := tui.NewApp(
 		listEntriesUc,
@@ -59,6 +194,22 @@ func run() error {
 		createEntryUc,
 		updateEntryUc,
 		deleteEntryUc,
+		restorePasswordUc,
+		listTagsUc,
+		renameTagUc,
+		listFoldersUc,
+		createFolderUc,
+		renameFolderUc,
+		moveFolderUc,
+		deleteFolderUc,
+		moveEntryUc,
+		listTrashUc,
+		restoreEntryUc,
+		purgeEntryUc,
+		purgeTrashUc,
+		auditVaultUc,
+		auditOpts,
+		passwordGen,
 	)
 
 	app.ShowList()
@@ -66,11 +217,63 @@ func run() error {
 	return app.Run()
 }
 
+// auditOptions opens the configured breach database for the commands that
+// use it. A database that cannot be opened only disables the breach check.
+func auditOptions(config storage.AuditConfig) (domain.AuditOptions, func()) {
+	opts := config.Options()
+	if config.BreachDB == "" {
+		return opts, func() {}
+	}
+
+	breachDB, err := storage.OpenBreachDB(config.BreachDB)
+	if err != nil {
+		log.Printf("Warning: breach check disabled: %v\n", err)
+		return opts, func() {}
+	}
+	opts.Breaches = breachDB
+	return opts, func() { breachDB.Close() }
+}
+
+// withVaultLock runs fn while holding the vault lock, so that commands
+// touching the vault or the key header do not race another passvault
+// process.
+func withVaultLock(vaultRepo *storage.FileVaultRepository, fn func() error) error {
+	unlock, err := vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// prepareVault finishes an interrupted key rotation and creates the vault
+// file if it is missing.
+func prepareVault(keyManager *storage.KeyManager, vaultRepo *storage.FileVaultRepository) error {
+	if err := storage.NewKeyRotator(keyManager, vaultRepo).Recover(); err != nil {
+		return fmt.Errorf("failed to recover key rotation: %w", err)
+	}
+
+	if !vaultRepo.Exists() {
+		vault := domain.NewVault()
+		if err := vaultRepo.Save(vault); err != nil {
+			return fmt.Errorf("failed to create vault: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func initialize(cryptoSvc *storage.AESEncryptor, vaultRepo *storage.FileVaultRepository) error {
 	fmt.Println("First time setup...")
-	fmt.Println("Generating encryption key...")
 
-	if err := cryptoSvc.InitializeKey(); err != nil {
+	password, err := readNewPassword("New master password: ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Deriving encryption key...")
+	if err := cryptoSvc.InitializeKey(password); err != nil {
 		return fmt.Errorf("failed to initialize key: %w", err)
 	}
 
@@ -84,3 +287,145 @@ func initialize(cryptoSvc *storage.AESEncryptor, vaultRepo *storage.FileVaultRep
 
 	return nil
 }
+
+func unlock(cryptoSvc *storage.AESEncryptor) error {
+	for attempt := 1; ; attempt++ {
+		password, err := readPassword("Master password: ")
+		if err != nil {
+			return err
+		}
+
+		err = cryptoSvc.Unlock(password)
+		if err == nil {
+			return nil
+		}
+		// A password read from a pipe is not retried, as the next line
+		// belongs to the command.
+		if !errors.Is(err, domain.ErrInvalidPassword) || attempt == UnlockAttempts ||
+			!term.IsTerminal(int(promptInput.Fd())) {
+			return err
+		}
+
+		fmt.Println("Invalid master password, try again.")
+	}
+}
+
+func unlockWithKeyfile(keyManager *storage.KeyManager, path string) error {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read keyfile: %w", err)
+	}
+	return keyManager.UnlockWith(storage.SlotKeyfile, secret)
+}
+
+func unlockWithRecoveryCode(keyManager *storage.KeyManager) error {
+	code, err := readPassword("Recovery code: ")
+	if err != nil {
+		return err
+	}
+	return keyManager.UnlockWith(storage.SlotRecovery, code)
+}
+
+// migrateLegacyKey protects a vault created with a raw key.bin by a master
+// password. The vault is re-encrypted with the derived key before key.bin is
+// removed.
+func migrateLegacyKey(keyManager *storage.KeyManager, vaultRepo *storage.FileVaultRepository) error {
+	fmt.Println("An unprotected encryption key was found.")
+	fmt.Println("Set a master password to protect your vault.")
+
+	if err := keyManager.UnlockLegacy(); err != nil {
+		return fmt.Errorf("failed to load legacy key: %w", err)
+	}
+	legacyCrypto, err := keyManager.LegacyEncryptor()
+	if err != nil {
+		return fmt.Errorf("failed to load legacy key: %w", err)
+	}
+
+	vault, err := vaultRepo.Load()
+	if err != nil && !errors.Is(err, storage.ErrVaultNotFound) {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+	if vault == nil {
+		vault = domain.NewVault()
+	}
+
+	password, err := readNewPassword("New master password: ")
+	if err != nil {
+		return err
+	}
+
+	if err := keyManager.InitializeKey(password); err != nil {
+		return fmt.Errorf("failed to initialize key: %w", err)
+	}
+
+	if err := vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	// key.bin is only removed once nothing is left that needs it.
+	if err := vaultRepo.ReencryptBackups(legacyCrypto); err != nil {
+		return fmt.Errorf("failed to re-encrypt backups: %w", err)
+	}
+
+	if err := keyManager.RemoveLegacyKey(); err != nil {
+		return fmt.Errorf("failed to remove legacy key: %w", err)
+	}
+
+	fmt.Println("Migration complete!")
+
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: passvault [flags] [command]
+
+Without a command the interactive TUI is started.
+
+Commands:
+  keyslot list                   List key slots
+  keyslot add password           Add a master password slot
+  keyslot add keyfile <path>     Add a keyfile slot, creating the keyfile if needed
+  keyslot add recovery           Add a recovery code slot and print the code
+  keyslot revoke <id>            Revoke a key slot
+  rotate-key [-yes]              Generate a new vault key and re-encrypt the vault,
+                                 asking for a new master password
+  backup list                    List vault backups
+  backup restore [-yes] <id>     Replace the vault with a backup
+  audit [-json] [-max-age days] [-min-score n]
+                                 Report reused, weak and old passwords and duplicate entries
+  breach-check [-json] [-db path]
+                                 Look the passwords up in a local Pwned Passwords copy
+  strength [-json]               Estimate the strength of a password read from stdin
+  generate [-json] [-passphrase] [options]
+                                 Print a random password or passphrase, see generate -h
+  ls [-json] [-folder path] [-tag tag] [-type type]
+                                 List entries without their secrets
+  show [-json | -field name] <id|title>
+                                 Print an entry or one of its fields
+  add [-json] -title title [flags]
+                                 Add a login, reading the password from stdin
+  edit [-json] [flags] <id|title>
+                                 Change the fields given by flags
+  rm [-json] <id|title>          Move an entry to the trash
+  copy [-json] [-field name] <id|title>
+                                 Copy the password or another field to the clipboard
+  run [-env-file path] [-no-mask] -- command [args...]
+                                 Run a command with pv://<entry>/<field> variables resolved
+  inject [-i template] [-o output]
+                                 Render a text/template with values from the vault
+  git-credential <get|store|erase>
+                                 Act as a git credential helper
+  ssh-agent [-socket path] [-confirm] [-lifetime duration]
+                                 Serve the SSH keys in the vault to ssh
+
+Entries are found by ID or by title, ignoring case. When stdin is not a
+terminal, the master password and secrets are read from it one per line.
+
+Exit codes: 0 success, 1 error, 2 usage, 3 not found, 4 ambiguous title,
+5 wrong master password or decryption failure, 6 vault locked by another
+process. run exits with the code of the command.
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+const concealed = "<concealed by passvault>"
+
+// maskingWriter replaces secrets in the stream written to it. A secret may
+// be split across writes, so output that could be the start of a secret is
+// held back until the next write or Flush.
+type maskingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+func newMaskingWriter(w io.Writer, secrets []string) *maskingWriter {
+	mw := &maskingWriter{w: w}
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if secret != "" && !seen[secret] {
+			seen[secret] = true
+			mw.secrets = append(mw.secrets, []byte(secret))
+		}
+	}
+	// A secret containing another one has to be matched first.
+	sort.Slice(mw.secrets, func(i, j int) bool {
+		return len(mw.secrets[i]) > len(mw.secrets[j])
+	})
+	return mw
+}
+
+func (mw *maskingWriter) Write(p []byte) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	mw.pending = append(mw.pending, p...)
+	out, held := mw.mask(mw.pending, false)
+	mw.pending = append(mw.pending[:0], held...)
+	if _, err := mw.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the output held back, once the stream has ended.
+func (mw *maskingWriter) Flush() error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	out, _ := mw.mask(mw.pending, true)
+	mw.pending = nil
+	_, err := mw.w.Write(out)
+	return err
+}
+
+// mask returns buf with the secrets replaced and, unless final is set, the
+// tail of buf that might continue into a secret.
+func (mw *maskingWriter) mask(buf []byte, final bool) ([]byte, []byte) {
+	out := make([]byte, 0, len(buf))
+	for i := 0; i < len(buf); {
+		if secret := mw.match(buf[i:]); secret != nil {
+			out = append(out, concealed...)
+			i += len(secret)
+			continue
+		}
+		if !final && mw.partial(buf[i:]) {
+			return out, buf[i:]
+		}
+		out = append(out, buf[i])
+		i++
+	}
+	return out, nil
+}
+
+func (mw *maskingWriter) match(b []byte) []byte {
+	for _, secret := range mw.secrets {
+		if bytes.HasPrefix(b, secret) {
+			return secret
+		}
+	}
+	return nil
+}
+
+func (mw *maskingWriter) partial(b []byte) bool {
+	for _, secret := range mw.secrets {
+		if len(b) < len(secret) && bytes.HasPrefix(secret, b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var (
+	// promptInput is where passwords are read from, stdin unless
+	// promptFromTerminal was called.
+	promptInput = os.Stdin
+	stdinReader = bufio.NewReader(os.Stdin)
+)
+
+// promptFromTerminal reads passwords and answers from the terminal instead
+// of stdin, for commands whose stdin belongs to another program.
+func promptFromTerminal() (func(), error) {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONIN$"
+	}
+
+	tty, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open terminal: %w", err)
+	}
+	promptInput = tty
+	stdinReader = bufio.NewReader(tty)
+	return func() { tty.Close() }, nil
+}
+
+// readPassword prompts on stderr and reads a password without echo. When
+// stdin is not a terminal the password is read as a single line instead.
+func readPassword(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(promptInput.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return password, err
+	}
+
+	line, err := stdinReader.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// readNewPassword asks for a new master password twice and makes sure both
+// inputs match.
+func readNewPassword(prompt string) ([]byte, error) {
+	password, err := readPassword(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	confirm, err := readPassword("Confirm master password: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(password, confirm) {
+		return nil, errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+// confirm asks a yes/no question and defaults to no.
+func confirm(prompt string) (bool, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
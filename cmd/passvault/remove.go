@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ritarock/passvault/service"
+)
+
+// runRemove moves an entry to the trash, from where it can be restored in
+// the TUI until it is purged.
+func runRemove(findEntryUc *service.FindEntryUsecase, deleteEntryUc *service.DeleteEntryUsecase, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the removed entry ID as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("passvault rm [-json] <id|title>")
+	}
+
+	en, err := findEntryUc.Execute(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := deleteEntryUc.Execute(en.ID); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(map[string]string{"id": en.ID})
+	}
+	fmt.Printf("Moved %s to the trash.\n", en.Title)
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ritarock/passvault/storage"
+)
+
+// runRotateKey replaces the vault key. The slot used to unlock is derived
+// anew: a password slot asks for a new master password, which may be the
+// old one, and a keyfile slot reads the keyfile again.
+func runRotateKey(keyManager *storage.KeyManager, vaultRepo *storage.FileVaultRepository, keyfile string, args []string) error {
+	fs := flag.NewFlagSet("rotate-key", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	unlocked, err := keyManager.UnlockedSlot()
+	if err != nil {
+		return fmt.Errorf("failed to find unlocked key slot: %w", err)
+	}
+	if unlocked.Type == storage.SlotRecovery {
+		return storage.ErrRecoveryRotation
+	}
+
+	slots, err := keyManager.Slots()
+	if err != nil {
+		return fmt.Errorf("failed to list key slots: %w", err)
+	}
+
+	var dropped []storage.KeySlot
+	for _, slot := range slots {
+		if slot.ID != unlocked.ID {
+			dropped = append(dropped, slot)
+		}
+	}
+
+	fmt.Printf("A new vault key will be generated and wrapped by the %s slot used to unlock.\n", unlocked.Type)
+	if len(dropped) > 0 {
+		fmt.Println("These key slots cannot be carried over and will be revoked:")
+		for _, slot := range dropped {
+			fmt.Printf("  %s  %s\n", slot.ID, slot.Type)
+		}
+		fmt.Println("Add them again with 'passvault keyslot add' afterwards.")
+	}
+
+	if !*yes {
+		ok, err := confirm("Rotate the vault key? [y/N]: ")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	var secret []byte
+	if unlocked.Type == storage.SlotKeyfile {
+		secret, err = os.ReadFile(keyfile)
+		if err != nil {
+			return fmt.Errorf("failed to read keyfile: %w", err)
+		}
+	} else {
+		secret, err = readNewPassword("New master password: ")
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := storage.NewKeyRotator(keyManager, vaultRepo).Rotate(secret); err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+
+	fmt.Println("Vault key rotated.")
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+	"golang.org/x/term"
+)
+
+// exitStatusError carries the exit code of a child process, which passvault
+// exits with.
+type exitStatusError struct {
+	code int
+}
+
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runRun starts a command with the secret references in its environment
+// resolved. The secrets only ever live in the environment of the child and
+// are masked in its output.
+func runRun(resolveReferencesUc *service.ResolveReferencesUsecase, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	envFile := fs.String("env-file", "", "read more variables from a KEY=VALUE file")
+	noMask := fs.Bool("no-mask", false, "do not mask secrets in the output of the command")
+	// The flags of the command must not be taken for ours, so flags are
+	// only accepted before it.
+	if err := fs.Parse(args); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if fs.NArg() == 0 {
+		return newUsageError("passvault run [-env-file path] [-no-mask] -- command [args...]")
+	}
+
+	env := os.Environ()
+	if *envFile != "" {
+		fileEnv, err := readEnvFile(*envFile)
+		if err != nil {
+			return err
+		}
+		env = append(env, fileEnv...)
+	}
+
+	env, secrets, err := resolveEnv(resolveReferencesUc, env)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// The master password may have been read from stdin already.
+		cmd.Stdin = stdinReader
+	}
+
+	var stdout, stderr *maskingWriter
+	if *noMask {
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	} else {
+		stdout, stderr = newMaskingWriter(os.Stdout, secrets), newMaskingWriter(os.Stderr, secrets)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", fs.Arg(0), err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	err = cmd.Wait()
+	if stdout != nil {
+		_ = stdout.Flush()
+		_ = stderr.Flush()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			// Killed by a signal.
+			code = exitError
+		}
+		return &exitStatusError{code: code}
+	}
+	return err
+}
+
+// resolveEnv replaces the values of variables that are secret references.
+// Later variables override earlier ones of the same name. It returns the
+// resolved secrets too.
+func resolveEnv(resolveReferencesUc *service.ResolveReferencesUsecase, env []string) ([]string, []string, error) {
+	var refs []domain.SecretReference
+	var indexes []int
+	for i, variable := range env {
+		_, value, _ := strings.Cut(variable, "=")
+		if !domain.IsSecretReference(value) {
+			continue
+		}
+		ref, err := domain.ParseSecretReference(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		refs = append(refs, ref)
+		indexes = append(indexes, i)
+	}
+
+	values, err := resolveReferencesUc.Execute(refs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved := make([]string, len(env))
+	copy(resolved, env)
+	for i, value := range values {
+		name, _, _ := strings.Cut(env[indexes[i]], "=")
+		resolved[indexes[i]] = name + "=" + value
+	}
+	return resolved, values, nil
+}
+
+// readEnvFile reads KEY=VALUE lines. Blank lines, lines starting with # and
+// an "export " prefix are ignored, and quotes around a value are removed.
+func readEnvFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	var env []string
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, name+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return env, nil
+}
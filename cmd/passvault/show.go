@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+)
+
+// runShow prints an entry, including its secrets, or a single field of it.
+func runShow(findEntryUc *service.FindEntryUsecase, getEntryUc *service.GetEntryUsecase, listFoldersUc *service.ListFoldersUsecase, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the entry as JSON")
+	field := fs.String("field", "", "print only this field: username, password, url, notes, totp, a card, identity or SSH key field, or a custom field name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*asJSON && *field != "") {
+		return newUsageError("passvault show [-json | -field name] <id|title>")
+	}
+
+	found, err := findEntryUc.Execute(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	en, err := getEntryUc.Execute(found.ID)
+	if err != nil {
+		return err
+	}
+
+	if *field != "" {
+		value, err := en.Field(*field, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	if *asJSON {
+		return writeJSON(en)
+	}
+
+	paths, err := folderPaths(listFoldersUc)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", label, value)
+		}
+	}
+	line("ID", en.ID)
+	line("Type", en.Type.Label())
+	line("Title", en.Title)
+	line("Username", en.Username)
+	line("Password", en.Password)
+	line("URL", en.URL)
+	if en.TOTP != nil {
+		code, err := domain.NewTOTPGenerator().Generate(en.TOTP, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to generate TOTP code: %w", err)
+		}
+		line("TOTP", code)
+	}
+	if card := en.Card; card != nil {
+		line("Card number", card.Number)
+		line("Expiry", card.Expiry)
+		line("CVV", card.CVV)
+	}
+	if identity := en.Identity; identity != nil {
+		line("Name", identity.Name)
+		line("Address", identity.Address)
+		line("Phone", identity.Phone)
+	}
+	if key := en.SSHKey; key != nil {
+		line("Public key", strings.TrimSpace(key.PublicKey))
+		line("Passphrase", key.Passphrase)
+	}
+	for _, customField := range en.CustomFields {
+		line(customField.Name, customField.Value)
+	}
+	line("Folder", paths[en.FolderID])
+	line("Tags", strings.Join(en.Tags, ", "))
+	line("Updated", en.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Multi-line values would break the alignment.
+	if key := en.SSHKey; key != nil {
+		fmt.Printf("\nPrivate key:\n%s\n", strings.TrimSpace(key.PrivateKey))
+	}
+	if en.Notes != "" {
+		fmt.Printf("\nNotes:\n%s\n", en.Notes)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ritarock/passvault/service"
+	"github.com/ritarock/passvault/sshagent"
+)
+
+// runSSHAgent serves the SSH keys in the vault on a Unix socket until it is
+// interrupted.
+func runSSHAgent(listEntriesUc *service.ListEntriesUsecase, baseDir string, args []string) error {
+	fs := flag.NewFlagSet("ssh-agent", flag.ContinueOnError)
+	socket := fs.String("socket", filepath.Join(baseDir, "agent.sock"), "path of the agent socket")
+	confirmUse := fs.Bool("confirm", false, "ask before every use of a vault key")
+	lifetime := fs.Duration("lifetime", 0, "stop serving the vault keys after this long, e.g. 1h")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *lifetime < 0 {
+		return newUsageError("passvault ssh-agent [-socket path] [-confirm] [-lifetime duration]")
+	}
+
+	opts := sshagent.Options{Lifetime: *lifetime, ConfirmEachUse: *confirmUse}
+	if askpass := os.Getenv("SSH_ASKPASS"); askpass != "" {
+		opts.Confirm = confirmWithAskpass(askpass)
+	} else {
+		// Keys added with ssh-add -c ask too, so the terminal is used
+		// whenever there is one.
+		closeTerminal, err := promptFromTerminal()
+		if err == nil {
+			defer closeTerminal()
+			opts.Confirm = confirmOnTerminal
+		} else if *confirmUse {
+			return fmt.Errorf("-confirm needs a terminal or SSH_ASKPASS: %w", err)
+		}
+	}
+
+	l, err := listenAgent(*socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(*socket)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		l.Close()
+	}()
+
+	fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", *socket)
+	fmt.Fprintln(os.Stderr, "Serving SSH keys from the vault, press Ctrl-C to stop.")
+
+	return sshagent.NewAgent(listEntriesUc, opts).Serve(l)
+}
+
+// listenAgent listens on the socket, replacing a socket left behind by an
+// agent that did not stop cleanly. Only the user may connect.
+func listenAgent(socket string) (net.Listener, error) {
+	if info, err := os.Lstat(socket); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", socket)
+		}
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("an agent is already listening on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := listenPrivate(socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	return l, nil
+}
+
+func confirmOnTerminal(comment string) (bool, error) {
+	return confirm(fmt.Sprintf("Allow use of SSH key %q? [y/N] ", comment))
+}
+
+// confirmWithAskpass asks with the program in SSH_ASKPASS the way
+// ssh-agent does. The program exits with 0 when the use is allowed.
+func confirmWithAskpass(askpass string) sshagent.ConfirmFunc {
+	return func(comment string) (bool, error) {
+		cmd := exec.Command(askpass, fmt.Sprintf("Allow use of SSH key %q?", comment))
+		cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+		out, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to run %s: %w", askpass, err)
+		}
+		answer := strings.ToLower(strings.TrimSpace(string(out)))
+		return answer == "" || answer == "y" || answer == "yes", nil
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "net"
+
+// listenPrivate relies on the directory of the socket to keep other users
+// out, as there is no umask.
+func listenPrivate(socket string) (net.Listener, error) {
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,16 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate creates the socket with mode 0600. Changing the mode after
+// Listen would let other users connect in between.
+func listenPrivate(socket string) (net.Listener, error) {
+	oldMask := syscall.Umask(0o177)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+// runStrength estimates the strength of a password read from the terminal
+// or stdin. The password is never taken from the arguments, where other
+// users could see it in the process list.
+func runStrength(args []string) error {
+	fs := flag.NewFlagSet("strength", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the estimate as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newUsageError("passvault strength [-json]")
+	}
+
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return err
+	}
+
+	strength := domain.NewStrengthEstimator().Estimate(string(password))
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(strength)
+	}
+
+	fmt.Printf("Score:      %d/4 (%s)\n", strength.Score, strength.Label())
+	fmt.Printf("Guesses:    %.3g (%.1f bits)\n", strength.Guesses, strength.Entropy)
+	fmt.Printf("Crack time: %s\n", strength.CrackTime())
+	if strength.Warning != "" {
+		fmt.Printf("Warning:    %s\n", strength.Warning)
+	}
+	for _, suggestion := range strength.Suggestions {
+		fmt.Printf("Suggestion: %s\n", suggestion)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AuditIssue string
+
+const (
+	AuditBreached  AuditIssue = "breached"
+	AuditReused    AuditIssue = "reused"
+	AuditWeak      AuditIssue = "weak"
+	AuditOld       AuditIssue = "old"
+	AuditDuplicate AuditIssue = "duplicate"
+)
+
+// AuditIssues lists the issues in the order they are reported.
+var AuditIssues = []AuditIssue{AuditBreached, AuditReused, AuditWeak, AuditOld, AuditDuplicate}
+
+func (i AuditIssue) Label() string {
+	switch i {
+	case AuditBreached:
+		return "Breached password"
+	case AuditReused:
+		return "Reused password"
+	case AuditWeak:
+		return "Weak password"
+	case AuditOld:
+		return "Old password"
+	default:
+		return "Duplicate entry"
+	}
+}
+
+type AuditOptions struct {
+	// MaxPasswordAge reports passwords that have not been changed for
+	// longer. Zero disables the check.
+	MaxPasswordAge time.Duration
+	// MinScore reports passwords whose strength score is lower.
+	MinScore int
+	// Breaches reports passwords found in data breaches. Nil disables the
+	// check.
+	Breaches BreachChecker
+}
+
+func DefaultAuditOptions() AuditOptions {
+	return AuditOptions{
+		MaxPasswordAge: 365 * 24 * time.Hour,
+		MinScore:       3,
+	}
+}
+
+// AuditFinding is an issue found with one entry.
+type AuditFinding struct {
+	Issue   AuditIssue `json:"issue"`
+	EntryID string     `json:"entry_id"`
+	Title   string     `json:"title"`
+	Detail  string     `json:"detail"`
+	// RelatedIDs are the other entries that share the password, or the
+	// title and URL.
+	RelatedIDs []string `json:"related_ids,omitempty"`
+}
+
+type AuditReport struct {
+	CheckedAt time.Time      `json:"checked_at"`
+	Entries   int            `json:"entries"`
+	Findings  []AuditFinding `json:"findings"`
+}
+
+// Count returns the number of findings of the issue.
+func (r *AuditReport) Count(issue AuditIssue) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Issue == issue {
+			count++
+		}
+	}
+	return count
+}
+
+// PasswordChangedAt returns when the current password was set: when the
+// previous one was replaced, or when the entry was created.
+func (e *Entry) PasswordChangedAt() time.Time {
+	if len(e.PasswordHistory) > 0 {
+		return e.PasswordHistory[0].ReplacedAt
+	}
+	return e.CreatedAt
+}
+
+// Audit checks the logins for breached, reused, weak and old passwords and
+// every entry for duplicates with the same title and URL. Trashed entries
+// are not checked.
+func (v *Vault) Audit(estimator *StrengthEstimator, opts AuditOptions, now time.Time) (*AuditReport, error) {
+	report := &AuditReport{
+		CheckedAt: now,
+		Entries:   len(v.Entries),
+		Findings:  []AuditFinding{},
+	}
+
+	byPassword := make(map[string][]*Entry)
+	byTitleURL := make(map[string][]*Entry)
+	for _, entry := range v.Entries {
+		key := strings.ToLower(strings.TrimSpace(entry.Title)) + "\x00" + normalizeAuditURL(entry.URL)
+		byTitleURL[key] = append(byTitleURL[key], entry)
+
+		if entry.Type != EntryLogin || entry.Password == "" {
+			continue
+		}
+		byPassword[entry.Password] = append(byPassword[entry.Password], entry)
+
+		if opts.Breaches != nil {
+			count, err := opts.Breaches.Breached(entry.Password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %q for breaches: %w", entry.Title, err)
+			}
+			if count > 0 {
+				report.add(AuditBreached, entry, fmt.Sprintf("Seen %d times in data breaches", count), nil)
+			}
+		}
+
+		strength := estimator.Estimate(entry.Password, entry.Title, entry.Username, entry.URL)
+		if strength.Score < opts.MinScore {
+			report.add(AuditWeak, entry, fmt.Sprintf("%s, cracked in %s", strength.Label(), strength.CrackTime()), nil)
+		}
+
+		if age := now.Sub(entry.PasswordChangedAt()); opts.MaxPasswordAge > 0 && age > opts.MaxPasswordAge {
+			report.add(AuditOld, entry, fmt.Sprintf("Unchanged for %d days", int(age.Hours()/24)), nil)
+		}
+	}
+
+	for _, entries := range byPassword {
+		for _, entry := range entries {
+			if len(entries) > 1 {
+				report.add(AuditReused, entry, fmt.Sprintf("Shared with %s", pluralEntries(len(entries)-1)), entries)
+			}
+		}
+	}
+	for _, entries := range byTitleURL {
+		for _, entry := range entries {
+			if len(entries) > 1 {
+				report.add(AuditDuplicate, entry, fmt.Sprintf("Same title and URL as %s", pluralEntries(len(entries)-1)), entries)
+			}
+		}
+	}
+
+	order := make(map[AuditIssue]int, len(AuditIssues))
+	for i, issue := range AuditIssues {
+		order[issue] = i
+	}
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Issue != b.Issue {
+			return order[a.Issue] < order[b.Issue]
+		}
+		if !strings.EqualFold(a.Title, b.Title) {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+		return a.EntryID < b.EntryID
+	})
+	return report, nil
+}
+
+// add records a finding. related may include the entry itself, which is
+// left out of RelatedIDs.
+func (r *AuditReport) add(issue AuditIssue, entry *Entry, detail string, related []*Entry) {
+	var ids []string
+	for _, other := range related {
+		if other.ID != entry.ID {
+			ids = append(ids, other.ID)
+		}
+	}
+	sort.Strings(ids)
+	r.Findings = append(r.Findings, AuditFinding{
+		Issue:      issue,
+		EntryID:    entry.ID,
+		Title:      entry.Title,
+		Detail:     detail,
+		RelatedIDs: ids,
+	})
+}
+
+// normalizeAuditURL ignores the scheme, a leading www. and a trailing slash,
+// so that the same site written differently is still a duplicate.
+func normalizeAuditURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	url = strings.TrimPrefix(url, "www.")
+	return strings.TrimRight(url, "/")
+}
+
+func pluralEntries(n int) string {
+	if n == 1 {
+		return "1 other entry"
+	}
+	return fmt.Sprintf("%d other entries", n)
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVault_Audit(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	strong := "vK7#qT2mXp9!Lw4z"
+
+	tests := []struct {
+		name    string
+		entries []*Entry
+		opts    AuditOptions
+		want    map[AuditIssue][]string
+	}{
+		{
+			name: "succeed: no findings",
+			entries: []*Entry{
+				{ID: "a", Type: EntryLogin, Title: "A", Password: strong, CreatedAt: now},
+				{ID: "b", Type: EntryLogin, Title: "B", Password: strong + "x", CreatedAt: now},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{},
+		},
+		{
+			name: "succeed: reused password",
+			entries: []*Entry{
+				{ID: "a", Type: EntryLogin, Title: "A", Password: strong, CreatedAt: now},
+				{ID: "b", Type: EntryLogin, Title: "B", Password: strong, CreatedAt: now},
+				{ID: "c", Type: EntryLogin, Title: "C", Password: strong + "x", CreatedAt: now},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{AuditReused: {"a", "b"}},
+		},
+		{
+			name: "succeed: weak password",
+			entries: []*Entry{
+				{ID: "a", Type: EntryLogin, Title: "A", Password: "password123", CreatedAt: now},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{AuditWeak: {"a"}},
+		},
+		{
+			name: "succeed: old password uses the last change",
+			entries: []*Entry{
+				{ID: "old", Type: EntryLogin, Title: "Old", Password: strong, CreatedAt: now.AddDate(-2, 0, 0)},
+				{
+					ID: "changed", Type: EntryLogin, Title: "Changed", Password: strong + "x", CreatedAt: now.AddDate(-2, 0, 0),
+					PasswordHistory: []PasswordRecord{{Password: "before", ReplacedAt: now.AddDate(0, -1, 0)}},
+				},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{AuditOld: {"old"}},
+		},
+		{
+			name: "succeed: age check disabled",
+			entries: []*Entry{
+				{ID: "old", Type: EntryLogin, Title: "Old", Password: strong, CreatedAt: now.AddDate(-2, 0, 0)},
+			},
+			opts: AuditOptions{MinScore: 3},
+			want: map[AuditIssue][]string{},
+		},
+		{
+			name: "succeed: breached password",
+			entries: []*Entry{
+				{ID: "a", Type: EntryLogin, Title: "A", Password: strong, CreatedAt: now},
+				{ID: "b", Type: EntryLogin, Title: "B", Password: strong + "x", CreatedAt: now},
+			},
+			opts: AuditOptions{MinScore: 3, Breaches: fakeBreachChecker{strong: 42}},
+			want: map[AuditIssue][]string{AuditBreached: {"a"}},
+		},
+		{
+			name: "succeed: duplicate title and URL",
+			entries: []*Entry{
+				{ID: "a", Type: EntryLogin, Title: "Mail", URL: "https://mail.example.com/", Password: strong, CreatedAt: now},
+				{ID: "b", Type: EntryLogin, Title: "mail", URL: "mail.example.com", Password: strong + "x", CreatedAt: now},
+				{ID: "c", Type: EntryLogin, Title: "Mail", URL: "https://other.example.com", Password: strong + "y", CreatedAt: now},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{AuditDuplicate: {"a", "b"}},
+		},
+		{
+			name: "succeed: non-login entries skip password checks",
+			entries: []*Entry{
+				{ID: "note", Type: EntryNote, Title: "Note", CreatedAt: now.AddDate(-2, 0, 0)},
+				{ID: "card", Type: EntryCard, Title: "Card", Password: "leftover", CreatedAt: now},
+			},
+			opts: DefaultAuditOptions(),
+			want: map[AuditIssue][]string{},
+		},
+	}
+
+	se := newTestStrengthEstimator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := NewVault()
+			for _, entry := range test.entries {
+				vault.Entries[entry.ID] = entry
+			}
+
+			report, err := vault.Audit(se, test.opts, now)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.entries), report.Entries)
+
+			got := map[AuditIssue][]string{}
+			for _, finding := range report.Findings {
+				got[finding.Issue] = append(got[finding.Issue], finding.EntryID)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+type fakeBreachChecker map[string]int
+
+func (f fakeBreachChecker) Breached(password string) (int, error) {
+	return f[password], nil
+}
+
+type failingBreachChecker struct{}
+
+func (failingBreachChecker) Breached(string) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestVault_AuditBreachCheckError(t *testing.T) {
+	t.Parallel()
+	vault := NewVault()
+	vault.Entries["a"] = &Entry{ID: "a", Type: EntryLogin, Title: "A", Password: "secret"}
+
+	_, err := vault.Audit(newTestStrengthEstimator(), AuditOptions{Breaches: failingBreachChecker{}}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestVault_AuditRelatedIDs(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	vault := NewVault()
+	for _, id := range []string{"a", "b", "c"} {
+		vault.Entries[id] = &Entry{ID: id, Type: EntryLogin, Title: id, Password: "vK7#qT2mXp9!Lw4z", CreatedAt: now}
+	}
+
+	report, err := vault.Audit(newTestStrengthEstimator(), DefaultAuditOptions(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Count(AuditReused))
+	assert.Equal(t, "a", report.Findings[0].EntryID)
+	assert.Equal(t, []string{"b", "c"}, report.Findings[0].RelatedIDs)
+	assert.Equal(t, "Shared with 2 other entries", report.Findings[0].Detail)
+}
@@ -0,0 +1,9 @@
+package domain
+
+// BreachChecker looks passwords up in a list of passwords exposed in data
+// breaches.
+type BreachChecker interface {
+	// Breached returns how often password was seen in breaches, zero if it
+	// was never seen.
+	Breached(password string) (int, error)
+}
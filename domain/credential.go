@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Credential describes the login a client such as git asks for.
+type Credential struct {
+	// Protocol is the URL scheme, e.g. https.
+	Protocol string
+	// Host includes the port, if any.
+	Host string
+	// Path is only sent by git when credential.useHttpPath is set.
+	Path     string
+	Username string
+}
+
+// URL returns the credential as a URL, suitable for a new entry.
+func (c Credential) URL() string {
+	u := url.URL{Scheme: c.Protocol, Host: c.Host, Path: c.Path}
+	if u.Path != "" && !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+// FindCredentials returns the logins whose URL matches the credential.
+// Hosts are compared ignoring case, and the scheme of the entry URL must
+// match the protocol. An entry URL with a path only matches a request for the same
+// path or below it, and is preferred over entries for the whole host. When
+// the credential names a user, only logins of that user match.
+func (v *Vault) FindCredentials(c Credential) []*Entry {
+	type match struct {
+		entry *Entry
+		path  string
+	}
+
+	var matches []match
+	for _, entry := range v.Entries {
+		if entry.Type != EntryLogin || entry.URL == "" {
+			continue
+		}
+		if c.Username != "" && entry.Username != c.Username {
+			continue
+		}
+
+		u, ok := parseCredentialURL(entry.URL)
+		if !ok || !strings.EqualFold(u.Host, c.Host) {
+			continue
+		}
+		if c.Protocol != "" && !strings.EqualFold(u.Scheme, c.Protocol) {
+			continue
+		}
+
+		entryPath := normalizeCredentialPath(u.Path)
+		if requestPath := normalizeCredentialPath(c.Path); requestPath != "" && entryPath != "" &&
+			requestPath != entryPath && !strings.HasPrefix(requestPath, entryPath+"/") {
+			continue
+		}
+		matches = append(matches, match{entry: entry, path: entryPath})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if len(matches[i].path) != len(matches[j].path) {
+			return len(matches[i].path) > len(matches[j].path)
+		}
+		if matches[i].entry.Title != matches[j].entry.Title {
+			return matches[i].entry.Title < matches[j].entry.Title
+		}
+		return matches[i].entry.ID < matches[j].entry.ID
+	})
+
+	entries := make([]*Entry, len(matches))
+	for i, m := range matches {
+		entries[i] = m.entry
+	}
+	return entries
+}
+
+// parseCredentialURL parses an entry URL. URLs without a scheme, like
+// github.com/org, are taken as https, so that credentials are never sent
+// over plain http unless the entry asks for it.
+func parseCredentialURL(raw string) (*url.URL, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// normalizeCredentialPath makes org/repo, /org/repo/ and org/repo.git
+// compare equal.
+func normalizeCredentialPath(path string) string {
+	path = strings.Trim(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
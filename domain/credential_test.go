@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVault_FindCredentials(t *testing.T) {
+	t.Parallel()
+	vault := NewVault()
+	for _, entry := range []*Entry{
+		{ID: "host", Type: EntryLogin, Title: "GitHub", Username: "alice", URL: "https://github.com"},
+		{ID: "bare", Type: EntryLogin, Title: "GitHub bob", Username: "bob", URL: "github.com"},
+		{ID: "repo", Type: EntryLogin, Title: "GitHub org", Username: "alice", URL: "https://github.com/org/repo.git"},
+		{ID: "http", Type: EntryLogin, Title: "Plain", Username: "alice", URL: "http://git.example.com:8080/"},
+		{ID: "note", Type: EntryNote, Title: "Note", URL: "https://github.com"},
+	} {
+		vault.Entries[entry.ID] = entry
+	}
+
+	tests := []struct {
+		name       string
+		credential Credential
+		want       []string
+	}{
+		{
+			name:       "succeed: host without path matches every entry of the host",
+			credential: Credential{Protocol: "https", Host: "github.com"},
+			want:       []string{"repo", "host", "bare"},
+		},
+		{
+			name:       "succeed: username narrows the match",
+			credential: Credential{Protocol: "https", Host: "GitHub.com", Username: "bob"},
+			want:       []string{"bare"},
+		},
+		{
+			name:       "succeed: path prefers the repository entry",
+			credential: Credential{Protocol: "https", Host: "github.com", Path: "org/repo.git", Username: "alice"},
+			want:       []string{"repo", "host"},
+		},
+		{
+			name:       "succeed: other repository skips the repository entry",
+			credential: Credential{Protocol: "https", Host: "github.com", Path: "org/other.git", Username: "alice"},
+			want:       []string{"host"},
+		},
+		{
+			name:       "succeed: port and scheme must match",
+			credential: Credential{Protocol: "http", Host: "git.example.com:8080"},
+			want:       []string{"http"},
+		},
+		{
+			name:       "succeed: wrong scheme",
+			credential: Credential{Protocol: "https", Host: "git.example.com:8080"},
+			want:       []string{},
+		},
+		{
+			name:       "succeed: entry without scheme is https only",
+			credential: Credential{Protocol: "http", Host: "github.com", Username: "bob"},
+			want:       []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got := []string{}
+			for _, entry := range vault.FindCredentials(test.credential) {
+				got = append(got, entry.ID)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCredential_URL(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "https://github.com", Credential{Protocol: "https", Host: "github.com"}.URL())
+	assert.Equal(t, "https://github.com/org/repo.git", Credential{Protocol: "https", Host: "github.com", Path: "org/repo.git"}.URL())
+}
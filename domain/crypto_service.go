@@ -1,8 +1,16 @@
 package domain
 
+import "errors"
+
+var (
+	ErrInvalidPassword = errors.New("invalid master password")
+	ErrEmptyPassword   = errors.New("master password must not be empty")
+)
+
 type CryptoService interface {
 	Encrypt(data []byte) ([]byte, error)
 	Decrypt(data []byte) ([]byte, error)
-	InitializeKey() error
+	InitializeKey(password []byte) error
+	Unlock(password []byte) error
 	KeyExists() bool
 }
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const CustomFieldDateLayout = "2006-01-02"
+
+var ErrInvalidCustomField = errors.New("invalid custom field")
+
+type FieldType string
+
+const (
+	FieldText   FieldType = "text"
+	FieldHidden FieldType = "hidden"
+	FieldURL    FieldType = "url"
+	FieldEmail  FieldType = "email"
+	FieldDate   FieldType = "date"
+)
+
+// FieldTypes lists the custom field types in the order they are offered to
+// the user.
+var FieldTypes = []FieldType{FieldText, FieldHidden, FieldURL, FieldEmail, FieldDate}
+
+func ParseFieldType(s string) (FieldType, error) {
+	for _, t := range FieldTypes {
+		if strings.EqualFold(s, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown type %q", ErrInvalidCustomField, s)
+}
+
+// CustomField is a user-defined, typed value on an entry. Hidden fields are
+// secrets and are masked like the password.
+type CustomField struct {
+	Name  string    `json:"name"`
+	Type  FieldType `json:"type"`
+	Value string    `json:"value"`
+}
+
+func (f CustomField) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCustomField)
+	}
+
+	if !slices.Contains(FieldTypes, f.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidCustomField, f.Type)
+	}
+	if f.Value == "" {
+		return nil
+	}
+
+	switch f.Type {
+	case FieldURL:
+		u, err := url.Parse(f.Value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w: %s is not a valid URL", ErrInvalidCustomField, f.Name)
+		}
+	case FieldEmail:
+		if _, err := mail.ParseAddress(f.Value); err != nil {
+			return fmt.Errorf("%w: %s is not a valid email address", ErrInvalidCustomField, f.Name)
+		}
+	case FieldDate:
+		if _, err := time.Parse(CustomFieldDateLayout, f.Value); err != nil {
+			return fmt.Errorf("%w: %s must be a date like 2006-01-02", ErrInvalidCustomField, f.Name)
+		}
+	}
+
+	return nil
+}
+
+func (f CustomField) IsHidden() bool {
+	return f.Type == FieldHidden
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomField_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		field  CustomField
+		hasErr bool
+	}{
+		{
+			name:  "succeed: text",
+			field: CustomField{Name: "Security question", Type: FieldText, Value: "first pet"},
+		},
+		{
+			name:  "succeed: hidden",
+			field: CustomField{Name: "PIN", Type: FieldHidden, Value: "1234"},
+		},
+		{
+			name:  "succeed: url",
+			field: CustomField{Name: "Admin", Type: FieldURL, Value: "https://example.com/admin"},
+		},
+		{
+			name:  "succeed: email",
+			field: CustomField{Name: "Recovery", Type: FieldEmail, Value: "alice@example.com"},
+		},
+		{
+			name:  "succeed: date",
+			field: CustomField{Name: "Expires", Type: FieldDate, Value: "2027-01-31"},
+		},
+		{
+			name:  "succeed: empty value",
+			field: CustomField{Name: "Expires", Type: FieldDate},
+		},
+		{
+			name:   "failed: missing name",
+			field:  CustomField{Type: FieldText, Value: "value"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: unknown type",
+			field:  CustomField{Name: "Name", Type: "number", Value: "1"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: invalid url",
+			field:  CustomField{Name: "Admin", Type: FieldURL, Value: "example"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: invalid email",
+			field:  CustomField{Name: "Recovery", Type: FieldEmail, Value: "alice"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: invalid date",
+			field:  CustomField{Name: "Expires", Type: FieldDate, Value: "31/01/2027"},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := test.field.Validate()
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidCustomField)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseFieldType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   FieldType
+		hasErr bool
+	}{
+		{name: "succeed: lower case", input: "hidden", want: FieldHidden},
+		{name: "succeed: mixed case", input: "Email", want: FieldEmail},
+		{name: "failed: unknown", input: "number", hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseFieldType(test.input)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
@@ -1,27 +1,56 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MaxPasswordHistory is the number of previous passwords kept per entry.
+const MaxPasswordHistory = 10
+
+var (
+	ErrPasswordHistoryNotFound = errors.New("password history entry not found")
+	ErrFieldNotFound           = errors.New("field not found")
+)
+
 type Entry struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Username     string    `json:"username"`
-	Password     string    `json:"password"`
-	URL          string    `json:"url"`
-	Notes        string    `json:"notes"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	LastViewedAt time.Time `json:"last_viewed_at"`
+	ID              string           `json:"id"`
+	Type            EntryType        `json:"type"`
+	Title           string           `json:"title"`
+	Username        string           `json:"username"`
+	Password        string           `json:"password"`
+	URL             string           `json:"url"`
+	Notes           string           `json:"notes"`
+	PasswordHistory []PasswordRecord `json:"password_history,omitempty"`
+	TOTP            *TOTP            `json:"totp,omitempty"`
+	CustomFields    []CustomField    `json:"custom_fields,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	FolderID        string           `json:"folder_id,omitempty"`
+	Card            *Card            `json:"card,omitempty"`
+	Identity        *Identity        `json:"identity,omitempty"`
+	SSHKey          *SSHKey          `json:"ssh_key,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	LastViewedAt    time.Time        `json:"last_viewed_at"`
+}
+
+// PasswordRecord is a previous password of an entry and the time it was
+// replaced.
+type PasswordRecord struct {
+	Password   string    `json:"password"`
+	ReplacedAt time.Time `json:"replaced_at"`
 }
 
 func NewEntry(title, username, password, url, notes string) *Entry {
 	now := time.Now()
 	return &Entry{
 		ID:           uuid.New().String(),
+		Type:         EntryLogin,
 		Title:        title,
 		Username:     username,
 		Password:     password,
@@ -34,14 +63,193 @@ func NewEntry(title, username, password, url, notes string) *Entry {
 }
 
 func (e *Entry) Update(title, username, password, url, notes string) {
+	now := time.Now()
 	e.Title = title
 	e.Username = username
-	e.Password = password
+	e.setPassword(password, now)
 	e.URL = url
 	e.Notes = notes
-	e.UpdatedAt = time.Now()
+	e.UpdatedAt = now
+}
+
+// RestorePassword makes the password at index in the history current again.
+// The replaced password is moved to the history.
+func (e *Entry) RestorePassword(index int) error {
+	if index < 0 || index >= len(e.PasswordHistory) {
+		return ErrPasswordHistoryNotFound
+	}
+
+	now := time.Now()
+	restored := e.PasswordHistory[index].Password
+	e.PasswordHistory = append(e.PasswordHistory[:index:index], e.PasswordHistory[index+1:]...)
+	e.setPassword(restored, now)
+	e.UpdatedAt = now
+	return nil
+}
+
+// setPassword replaces the password, keeping the old one at the front of
+// the bounded history.
+func (e *Entry) setPassword(password string, now time.Time) {
+	if password == e.Password {
+		return
+	}
+
+	if e.Password != "" {
+		record := PasswordRecord{Password: e.Password, ReplacedAt: now}
+		e.PasswordHistory = append([]PasswordRecord{record}, e.PasswordHistory...)
+		if len(e.PasswordHistory) > MaxPasswordHistory {
+			e.PasswordHistory = e.PasswordHistory[:MaxPasswordHistory]
+		}
+	}
+	e.Password = password
+}
+
+// SetTOTP sets the TOTP parameters from a base32 secret or an otpauth URI.
+// An empty string removes them.
+func (e *Entry) SetTOTP(secret string) error {
+	if strings.TrimSpace(secret) == "" {
+		e.TOTP = nil
+		return nil
+	}
+
+	totp, err := ParseTOTP(secret)
+	if err != nil {
+		return err
+	}
+	e.TOTP = totp
+	return nil
+}
+
+// SetCustomFields replaces the custom fields after validating each of them.
+// The order of fields is kept.
+func (e *Entry) SetCustomFields(fields []CustomField) error {
+	for _, field := range fields {
+		if err := field.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(fields) == 0 {
+		e.CustomFields = nil
+		return nil
+	}
+	e.CustomFields = append([]CustomField(nil), fields...)
+	return nil
+}
+
+// SetTags replaces the tags of the entry. Tags are normalized, deduplicated
+// and kept sorted.
+func (e *Entry) SetTags(tags []string) error {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t, err := NormalizeTag(tag)
+		if err != nil {
+			return err
+		}
+		normalized = append(normalized, t)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+
+	e.Tags = normalized
+	return nil
+}
+
+func (e *Entry) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(e.Tags, tag)
+	return found
+}
+
+// matchesTags reports whether the entry carries the tags according to
+// match. An empty tag list matches every entry.
+func (e *Entry) matchesTags(tags []string, match TagMatch) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		has := e.HasTag(tag)
+		if match == TagMatchAny && has {
+			return true
+		}
+		if match == TagMatchAll && !has {
+			return false
+		}
+	}
+	return match == TagMatchAll
 }
 
 func (e *Entry) MarkAsViewed() {
 	e.LastViewedAt = time.Now()
 }
+
+// Field returns the value of a field by name, ignoring case: a standard
+// field, a field of the card, identity or SSH key payload, "totp" for the
+// code valid at the given time, or the name of a custom field.
+func (e *Entry) Field(name string, at time.Time) (string, error) {
+	switch strings.ToLower(name) {
+	case "id":
+		return e.ID, nil
+	case "title":
+		return e.Title, nil
+	case "username":
+		return e.Username, nil
+	case "password":
+		return e.Password, nil
+	case "url":
+		return e.URL, nil
+	case "notes":
+		return e.Notes, nil
+	case "totp":
+		if e.TOTP != nil {
+			return NewTOTPGenerator().Generate(e.TOTP, at)
+		}
+	case "number":
+		if e.Card != nil {
+			return e.Card.Number, nil
+		}
+	case "expiry":
+		if e.Card != nil {
+			return e.Card.Expiry, nil
+		}
+	case "cvv":
+		if e.Card != nil {
+			return e.Card.CVV, nil
+		}
+	case "name":
+		if e.Identity != nil {
+			return e.Identity.Name, nil
+		}
+	case "address":
+		if e.Identity != nil {
+			return e.Identity.Address, nil
+		}
+	case "phone":
+		if e.Identity != nil {
+			return e.Identity.Phone, nil
+		}
+	case "private_key":
+		if e.SSHKey != nil {
+			return e.SSHKey.PrivateKey, nil
+		}
+	case "public_key":
+		if e.SSHKey != nil {
+			return e.SSHKey.PublicKey, nil
+		}
+	case "passphrase":
+		if e.SSHKey != nil {
+			return e.SSHKey.Passphrase, nil
+		}
+	}
+
+	for _, field := range e.CustomFields {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s has no field %q", ErrFieldNotFound, e.Title, name)
+}
@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -59,3 +61,290 @@ func TestEntry_MarkAsViewed(t *testing.T) {
 		})
 	}
 }
+
+func TestEntry_UpdateKeepsPasswordHistory(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		passwords   []string
+		wantCurrent string
+		wantHistory []string
+	}{
+		{
+			name:        "succeed: changed password is kept",
+			passwords:   []string{"second"},
+			wantCurrent: "second",
+			wantHistory: []string{"first"},
+		},
+		{
+			name:        "succeed: unchanged password is not recorded",
+			passwords:   []string{"first", "first"},
+			wantCurrent: "first",
+			wantHistory: nil,
+		},
+		{
+			name:        "succeed: newest first",
+			passwords:   []string{"second", "third"},
+			wantCurrent: "third",
+			wantHistory: []string{"second", "first"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "username", "first", "url", "notes")
+			for _, password := range test.passwords {
+				entry.Update("title", "username", password, "url", "notes")
+			}
+
+			assert.Equal(t, test.wantCurrent, entry.Password)
+			var history []string
+			for _, record := range entry.PasswordHistory {
+				history = append(history, record.Password)
+				assert.False(t, record.ReplacedAt.IsZero())
+			}
+			assert.Equal(t, test.wantHistory, history)
+		})
+	}
+}
+
+func TestEntry_PasswordHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+	entry := NewEntry("title", "username", "password-0", "url", "notes")
+	for i := 1; i <= MaxPasswordHistory+5; i++ {
+		entry.Update("title", "username", fmt.Sprintf("password-%d", i), "url", "notes")
+	}
+
+	assert.Len(t, entry.PasswordHistory, MaxPasswordHistory)
+	assert.Equal(t, fmt.Sprintf("password-%d", MaxPasswordHistory+4), entry.PasswordHistory[0].Password)
+}
+
+func TestEntry_RestorePassword(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		index       int
+		wantCurrent string
+		wantHistory []string
+		wantErr     error
+	}{
+		{
+			name:        "succeed: restore previous password",
+			index:       0,
+			wantCurrent: "second",
+			wantHistory: []string{"third", "first"},
+		},
+		{
+			name:        "succeed: restore oldest password",
+			index:       1,
+			wantCurrent: "first",
+			wantHistory: []string{"third", "second"},
+		},
+		{
+			name:        "failed: index out of range",
+			index:       2,
+			wantCurrent: "third",
+			wantHistory: []string{"second", "first"},
+			wantErr:     ErrPasswordHistoryNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "username", "first", "url", "notes")
+			entry.Update("title", "username", "second", "url", "notes")
+			entry.Update("title", "username", "third", "url", "notes")
+
+			err := entry.RestorePassword(test.index)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.wantCurrent, entry.Password)
+			var history []string
+			for _, record := range entry.PasswordHistory {
+				history = append(history, record.Password)
+			}
+			assert.Equal(t, test.wantHistory, history)
+		})
+	}
+}
+
+func TestEntry_SetTOTP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		secret   string
+		wantTOTP bool
+		hasErr   bool
+	}{
+		{
+			name:     "succeed: set secret",
+			secret:   "JBSWY3DPEHPK3PXP",
+			wantTOTP: true,
+		},
+		{
+			name:     "succeed: clear secret",
+			secret:   "  ",
+			wantTOTP: false,
+		},
+		{
+			name:     "failed: invalid secret",
+			secret:   "not base32!",
+			wantTOTP: true,
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "username", "password", "url", "notes")
+			entry.TOTP = &TOTP{Secret: "GEZDGNBV", Digits: 6, Period: 30, Algorithm: TOTPAlgorithmSHA1}
+
+			err := entry.SetTOTP(test.secret)
+			if test.hasErr {
+				assert.Error(t, err)
+				assert.Equal(t, "GEZDGNBV", entry.TOTP.Secret, "invalid input must not change the entry")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantTOTP, entry.TOTP != nil)
+		})
+	}
+}
+
+func TestEntry_SetCustomFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		fields []CustomField
+		want   []CustomField
+		hasErr bool
+	}{
+		{
+			name: "succeed: keeps order",
+			fields: []CustomField{
+				{Name: "PIN", Type: FieldHidden, Value: "1234"},
+				{Name: "Expires", Type: FieldDate, Value: "2027-01-31"},
+			},
+			want: []CustomField{
+				{Name: "PIN", Type: FieldHidden, Value: "1234"},
+				{Name: "Expires", Type: FieldDate, Value: "2027-01-31"},
+			},
+		},
+		{
+			name:   "succeed: clear fields",
+			fields: nil,
+			want:   nil,
+		},
+		{
+			name:   "failed: invalid field",
+			fields: []CustomField{{Name: "Expires", Type: FieldDate, Value: "soon"}},
+			want:   []CustomField{{Name: "Old", Type: FieldText, Value: "old"}},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "username", "password", "url", "notes")
+			entry.CustomFields = []CustomField{{Name: "Old", Type: FieldText, Value: "old"}}
+
+			err := entry.SetCustomFields(test.fields)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, entry.CustomFields)
+		})
+	}
+}
+
+func TestEntry_SetTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		tags   []string
+		want   []string
+		hasErr bool
+	}{
+		{
+			name: "succeed: normalized, sorted and unique",
+			tags: []string{"Work", "finance", "work", "Home Office"},
+			want: []string{"finance", "home-office", "work"},
+		},
+		{
+			name: "succeed: clear tags",
+			tags: nil,
+			want: nil,
+		},
+		{
+			name:   "failed: invalid tag",
+			tags:   []string{"ok", " "},
+			want:   []string{"old"},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "username", "password", "url", "notes")
+			entry.Tags = []string{"old"}
+
+			err := entry.SetTags(test.tags)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, entry.Tags)
+		})
+	}
+}
+
+func TestEntry_Field(t *testing.T) {
+	t.Parallel()
+	at := time.Unix(59, 0)
+	entry := &Entry{
+		Title:        "GitHub",
+		Username:     "alice",
+		Password:     "secret",
+		TOTP:         &TOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Digits: 8, Period: 30, Algorithm: TOTPAlgorithmSHA1},
+		SSHKey:       &SSHKey{PublicKey: "ssh-ed25519 AAAA"},
+		CustomFields: []CustomField{{Name: "API Token", Type: FieldHidden, Value: "token"}},
+	}
+
+	tests := []struct {
+		name   string
+		field  string
+		want   string
+		hasErr bool
+	}{
+		{name: "succeed: standard field ignoring case", field: "Password", want: "secret"},
+		{name: "succeed: current TOTP code", field: "totp", want: "94287082"},
+		{name: "succeed: payload field", field: "public_key", want: "ssh-ed25519 AAAA"},
+		{name: "succeed: custom field", field: "api token", want: "token"},
+		{name: "failed: payload of another type", field: "cvv", hasErr: true},
+		{name: "failed: unknown field", field: "pin", hasErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := entry.Field(test.field, at)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrFieldNotFound)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
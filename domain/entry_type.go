@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var ErrInvalidEntry = errors.New("invalid entry")
+
+type EntryType string
+
+const (
+	EntryLogin    EntryType = "login"
+	EntryNote     EntryType = "note"
+	EntryCard     EntryType = "card"
+	EntryIdentity EntryType = "identity"
+	EntrySSHKey   EntryType = "ssh_key"
+)
+
+// EntryTypes lists the entry types in the order they are offered to the
+// user.
+var EntryTypes = []EntryType{EntryLogin, EntryNote, EntryCard, EntryIdentity, EntrySSHKey}
+
+// ParseEntryType accepts a type name, case-insensitively. An empty string
+// is a login, the type of every entry before types were introduced.
+func ParseEntryType(s string) (EntryType, error) {
+	if s == "" {
+		return EntryLogin, nil
+	}
+	for _, t := range EntryTypes {
+		if strings.EqualFold(s, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown type %q", ErrInvalidEntry, s)
+}
+
+func (t EntryType) Label() string {
+	switch t {
+	case EntryNote:
+		return "Secure Note"
+	case EntryCard:
+		return "Credit Card"
+	case EntryIdentity:
+		return "Identity"
+	case EntrySSHKey:
+		return "SSH Key"
+	default:
+		return "Login"
+	}
+}
+
+// EntryDetails carries the payload of the non-login entry types. Only the
+// payload matching the entry type is kept; secure notes use Entry.Notes.
+type EntryDetails struct {
+	Card     *Card
+	Identity *Identity
+	SSHKey   *SSHKey
+}
+
+type Card struct {
+	Number string `json:"number"`
+	// Expiry is formatted as MM/YY.
+	Expiry string `json:"expiry"`
+	CVV    string `json:"cvv,omitempty"`
+}
+
+// NormalizeCardNumber removes the spaces and dashes printed on cards.
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+func (c *Card) Validate() error {
+	if c.Number == "" {
+		return fmt.Errorf("%w: card number is required", ErrInvalidEntry)
+	}
+	if len(c.Number) < 12 || len(c.Number) > 19 || !isDigits(c.Number) || !luhnValid(c.Number) {
+		return fmt.Errorf("%w: card number is not valid", ErrInvalidEntry)
+	}
+	if _, err := time.Parse("01/06", c.Expiry); err != nil {
+		return fmt.Errorf("%w: expiry must be formatted as MM/YY", ErrInvalidEntry)
+	}
+	if c.CVV != "" && (len(c.CVV) < 3 || len(c.CVV) > 4 || !isDigits(c.CVV)) {
+		return fmt.Errorf("%w: CVV must be 3 or 4 digits", ErrInvalidEntry)
+	}
+	return nil
+}
+
+// MaskedNumber hides all but the last four digits.
+func (c *Card) MaskedNumber() string {
+	if len(c.Number) <= 4 {
+		return c.Number
+	}
+	return "•••• " + c.Number[len(c.Number)-4:]
+}
+
+type Identity struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+}
+
+func (i *Identity) Validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidEntry)
+	}
+	for _, r := range i.Phone {
+		if !unicode.IsDigit(r) && !strings.ContainsRune("+-() ", r) {
+			return fmt.Errorf("%w: phone number may only contain digits, spaces and +-()", ErrInvalidEntry)
+		}
+	}
+	return nil
+}
+
+type SSHKey struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+func (k *SSHKey) Validate() error {
+	block, _ := pem.Decode([]byte(k.PrivateKey))
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return fmt.Errorf("%w: private key must be PEM encoded", ErrInvalidEntry)
+	}
+	if k.PublicKey != "" {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.PublicKey)); err != nil {
+			return fmt.Errorf("%w: public key must be in authorized_keys format", ErrInvalidEntry)
+		}
+	}
+	return nil
+}
+
+// SetType changes the type of the entry and validates the payload for it.
+// Payloads of other types are dropped.
+func (e *Entry) SetType(t EntryType, details EntryDetails) error {
+	t, err := ParseEntryType(string(t))
+	if err != nil {
+		return err
+	}
+
+	var card *Card
+	var identity *Identity
+	var sshKey *SSHKey
+
+	switch t {
+	case EntryCard:
+		if details.Card == nil {
+			return fmt.Errorf("%w: card details are required", ErrInvalidEntry)
+		}
+		c := *details.Card
+		c.Number = NormalizeCardNumber(c.Number)
+		c.Expiry = strings.TrimSpace(c.Expiry)
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		card = &c
+	case EntryIdentity:
+		if details.Identity == nil {
+			return fmt.Errorf("%w: identity details are required", ErrInvalidEntry)
+		}
+		i := *details.Identity
+		if err := i.Validate(); err != nil {
+			return err
+		}
+		identity = &i
+	case EntrySSHKey:
+		if details.SSHKey == nil {
+			return fmt.Errorf("%w: SSH key is required", ErrInvalidEntry)
+		}
+		k := *details.SSHKey
+		k.PublicKey = strings.TrimSpace(k.PublicKey)
+		if err := k.Validate(); err != nil {
+			return err
+		}
+		sshKey = &k
+	}
+
+	e.Type = t
+	e.Card = card
+	e.Identity = identity
+	e.SSHKey = sshKey
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// luhnValid reports whether number passes the Luhn checksum used by card
+// numbers. number must only contain digits.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d, _ := strconv.Atoi(number[i : i+1])
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
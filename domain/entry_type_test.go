@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSSHKey(t *testing.T) *SSHKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SSHKey{
+		PrivateKey: string(pem.EncodeToMemory(block)),
+		PublicKey:  string(ssh.MarshalAuthorizedKey(sshPub)),
+	}
+}
+
+func TestParseEntryType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   EntryType
+		hasErr bool
+	}{
+		{
+			name:  "succeed: empty is login",
+			input: "",
+			want:  EntryLogin,
+		},
+		{
+			name:  "succeed: case-insensitive",
+			input: "SSH_KEY",
+			want:  EntrySSHKey,
+		},
+		{
+			name:   "failed: unknown type",
+			input:  "wifi",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseEntryType(test.input)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidEntry)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestEntry_SetType(t *testing.T) {
+	t.Parallel()
+	sshKey := newTestSSHKey(t)
+
+	tests := []struct {
+		name      string
+		entryType EntryType
+		details   EntryDetails
+		check     func(*testing.T, *Entry)
+		hasErr    bool
+	}{
+		{
+			name:      "succeed: secure note drops payloads",
+			entryType: EntryNote,
+			details:   EntryDetails{Card: &Card{Number: "4111111111111111", Expiry: "12/30"}},
+			check: func(t *testing.T, e *Entry) {
+				assert.Equal(t, EntryNote, e.Type)
+				assert.Nil(t, e.Card)
+			},
+		},
+		{
+			name:      "succeed: card number is normalized",
+			entryType: EntryCard,
+			details:   EntryDetails{Card: &Card{Number: "4111 1111-1111 1111", Expiry: "12/30", CVV: "123"}},
+			check: func(t *testing.T, e *Entry) {
+				assert.Equal(t, "4111111111111111", e.Card.Number)
+				assert.Equal(t, "•••• 1111", e.Card.MaskedNumber())
+			},
+		},
+		{
+			name:      "succeed: identity",
+			entryType: EntryIdentity,
+			details:   EntryDetails{Identity: &Identity{Name: "Alice", Phone: "+1 (555) 010-0000"}},
+			check: func(t *testing.T, e *Entry) {
+				assert.Equal(t, "Alice", e.Identity.Name)
+			},
+		},
+		{
+			name:      "succeed: SSH key",
+			entryType: EntrySSHKey,
+			details:   EntryDetails{SSHKey: sshKey},
+			check: func(t *testing.T, e *Entry) {
+				assert.Equal(t, sshKey.PrivateKey, e.SSHKey.PrivateKey)
+			},
+		},
+		{
+			name:      "failed: unknown type",
+			entryType: "wifi",
+			hasErr:    true,
+		},
+		{
+			name:      "failed: card without details",
+			entryType: EntryCard,
+			hasErr:    true,
+		},
+		{
+			name:      "failed: card number fails checksum",
+			entryType: EntryCard,
+			details:   EntryDetails{Card: &Card{Number: "4111111111111112", Expiry: "12/30"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: card expiry",
+			entryType: EntryCard,
+			details:   EntryDetails{Card: &Card{Number: "4111111111111111", Expiry: "13/30"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: card CVV",
+			entryType: EntryCard,
+			details:   EntryDetails{Card: &Card{Number: "4111111111111111", Expiry: "12/30", CVV: "12a"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: identity without name",
+			entryType: EntryIdentity,
+			details:   EntryDetails{Identity: &Identity{Phone: "555"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: identity phone",
+			entryType: EntryIdentity,
+			details:   EntryDetails{Identity: &Identity{Name: "Alice", Phone: "call me"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: SSH private key not PEM",
+			entryType: EntrySSHKey,
+			details:   EntryDetails{SSHKey: &SSHKey{PrivateKey: "not a key"}},
+			hasErr:    true,
+		},
+		{
+			name:      "failed: SSH public key",
+			entryType: EntrySSHKey,
+			details:   EntryDetails{SSHKey: &SSHKey{PrivateKey: sshKey.PrivateKey, PublicKey: "ssh-ed25519 garbage"}},
+			hasErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			entry := NewEntry("title", "", "", "", "")
+			err := entry.SetType(test.entryType, test.details)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidEntry)
+				assert.Equal(t, EntryLogin, entry.Type)
+			} else {
+				assert.NoError(t, err)
+				test.check(t, entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FolderSeparator joins folder names into a path.
+const FolderSeparator = "/"
+
+var (
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderExists   = errors.New("folder already exists")
+	ErrFolderNotEmpty = errors.New("folder is not empty")
+	ErrInvalidFolder  = errors.New("invalid folder")
+)
+
+// Folder groups entries. Folders form a tree through ParentID; an empty
+// ParentID places the folder at the top level.
+type Folder struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewFolder(name, parentID string) *Folder {
+	now := time.Now()
+	return &Folder{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func validateFolderName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidFolder)
+	}
+	if strings.Contains(name, FolderSeparator) {
+		return fmt.Errorf("%w: name must not contain %q", ErrInvalidFolder, FolderSeparator)
+	}
+	return nil
+}
+
+func (v *Vault) CreateFolder(folder Folder) error {
+	if v.Folders == nil {
+		v.Folders = make(map[string]*Folder)
+	}
+	if _, exists := v.Folders[folder.ID]; exists {
+		return ErrFolderExists
+	}
+	if err := validateFolderName(folder.Name); err != nil {
+		return err
+	}
+	if err := v.checkParent(folder.ParentID); err != nil {
+		return err
+	}
+	if err := v.checkSiblingName(folder.ParentID, folder.Name, ""); err != nil {
+		return err
+	}
+
+	v.Folders[folder.ID] = &folder
+	v.UpdatedAt = time.Now()
+	return nil
+}
+
+func (v *Vault) GetFolder(id string) (*Folder, error) {
+	folder, exists := v.Folders[id]
+	if !exists {
+		return nil, ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+func (v *Vault) RenameFolder(id, name string) error {
+	folder, err := v.GetFolder(id)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if err := validateFolderName(name); err != nil {
+		return err
+	}
+	if err := v.checkSiblingName(folder.ParentID, name, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	folder.Name = name
+	folder.UpdatedAt = now
+	v.UpdatedAt = now
+	return nil
+}
+
+// MoveFolder moves a folder, with its subfolders and entries, under
+// parentID. A folder cannot be moved into itself or one of its subfolders.
+func (v *Vault) MoveFolder(id, parentID string) error {
+	folder, err := v.GetFolder(id)
+	if err != nil {
+		return err
+	}
+	if err := v.checkParent(parentID); err != nil {
+		return err
+	}
+	if parentID != "" && v.subtree(id)[parentID] {
+		return fmt.Errorf("%w: cannot move a folder into itself", ErrInvalidFolder)
+	}
+	if err := v.checkSiblingName(parentID, folder.Name, id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	folder.ParentID = parentID
+	folder.UpdatedAt = now
+	v.UpdatedAt = now
+	return nil
+}
+
+// DeleteFolder removes an empty folder. With cascade, the subfolders are
+// removed as well and the entries they contain are moved to the trash.
+func (v *Vault) DeleteFolder(id string, cascade bool) error {
+	if _, err := v.GetFolder(id); err != nil {
+		return err
+	}
+
+	subtree := v.subtree(id)
+	if !cascade {
+		if len(subtree) > 1 {
+			return fmt.Errorf("%w: it contains folders", ErrFolderNotEmpty)
+		}
+		for _, entry := range v.Entries {
+			if entry.FolderID == id {
+				return fmt.Errorf("%w: it contains entries", ErrFolderNotEmpty)
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, entry := range v.Entries {
+		if subtree[entry.FolderID] {
+			v.trash(entry, now)
+		}
+	}
+	for folderID := range subtree {
+		delete(v.Folders, folderID)
+	}
+
+	v.UpdatedAt = now
+	return nil
+}
+
+// MoveEntry puts an entry into a folder. An empty folderID moves it to the
+// top level.
+func (v *Vault) MoveEntry(entryID, folderID string) error {
+	entry, err := v.GetEntry(entryID)
+	if err != nil {
+		return err
+	}
+	if err := v.checkParent(folderID); err != nil {
+		return err
+	}
+
+	entry.FolderID = folderID
+	v.UpdatedAt = time.Now()
+	return nil
+}
+
+// ChildFolders returns the folders directly under parentID, sorted by name.
+func (v *Vault) ChildFolders(parentID string) []*Folder {
+	var folders []*Folder
+	for _, folder := range v.Folders {
+		if folder.ParentID == parentID {
+			folders = append(folders, folder)
+		}
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		return strings.ToLower(folders[i].Name) < strings.ToLower(folders[j].Name)
+	})
+	return folders
+}
+
+// ListFolders returns every folder in depth-first order, parents before
+// their children.
+func (v *Vault) ListFolders() []*Folder {
+	folders := make([]*Folder, 0, len(v.Folders))
+	var walk func(parentID string)
+	walk = func(parentID string) {
+		for _, folder := range v.ChildFolders(parentID) {
+			folders = append(folders, folder)
+			walk(folder.ID)
+		}
+	}
+	walk("")
+	return folders
+}
+
+// FolderPath returns the names from the top level down to the folder,
+// joined with FolderSeparator.
+func (v *Vault) FolderPath(id string) (string, error) {
+	var names []string
+	for id != "" {
+		folder, err := v.GetFolder(id)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, folder.Name)
+		id = folder.ParentID
+	}
+	slices.Reverse(names)
+	return strings.Join(names, FolderSeparator), nil
+}
+
+// checkParent reports an error unless parentID is empty or an existing
+// folder.
+func (v *Vault) checkParent(parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if _, err := v.GetFolder(parentID); err != nil {
+		return fmt.Errorf("%w: %s", err, parentID)
+	}
+	return nil
+}
+
+// checkSiblingName rejects a name already used by another folder with the
+// same parent. Names are compared case-insensitively.
+func (v *Vault) checkSiblingName(parentID, name, exceptID string) error {
+	for _, folder := range v.ChildFolders(parentID) {
+		if folder.ID != exceptID && strings.EqualFold(folder.Name, strings.TrimSpace(name)) {
+			return fmt.Errorf("%w: %s", ErrFolderExists, name)
+		}
+	}
+	return nil
+}
+
+// subtree returns the IDs of the folder and all of its subfolders.
+func (v *Vault) subtree(id string) map[string]bool {
+	ids := map[string]bool{id: true}
+	for changed := true; changed; {
+		changed = false
+		for _, folder := range v.Folders {
+			if ids[folder.ParentID] && !ids[folder.ID] {
+				ids[folder.ID] = true
+				changed = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,407 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFolderVault returns a vault with the tree
+//
+//	work
+//	  clients
+//	    acme   (entry: acme-db)
+//	  (entry: jira)
+//	personal   (entry: bank)
+//	(entry: misc)
+func newFolderVault() *Vault {
+	vault := NewVault()
+	for _, folder := range []Folder{
+		{ID: "work", Name: "Work"},
+		{ID: "clients", Name: "Clients", ParentID: "work"},
+		{ID: "acme", Name: "Acme", ParentID: "clients"},
+		{ID: "personal", Name: "Personal"},
+	} {
+		vault.Folders[folder.ID] = &folder
+	}
+	for id, folderID := range map[string]string{
+		"acme-db": "acme",
+		"jira":    "work",
+		"bank":    "personal",
+		"misc":    "",
+	} {
+		vault.Entries[id] = &Entry{ID: id, Title: id, FolderID: folderID}
+	}
+	return vault
+}
+
+func entryIDs(entries []*Entry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func TestVault_CreateFolder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		folder Folder
+		hasErr bool
+	}{
+		{
+			name:   "succeed: top level folder",
+			folder: Folder{ID: "new", Name: "Shared"},
+			hasErr: false,
+		},
+		{
+			name:   "succeed: subfolder",
+			folder: Folder{ID: "new", Name: "Globex", ParentID: "clients"},
+			hasErr: false,
+		},
+		{
+			name:   "succeed: same name under another parent",
+			folder: Folder{ID: "new", Name: "Clients", ParentID: "personal"},
+			hasErr: false,
+		},
+		{
+			name:   "failed: duplicate ID",
+			folder: Folder{ID: "work", Name: "Other"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: empty name",
+			folder: Folder{ID: "new", Name: " "},
+			hasErr: true,
+		},
+		{
+			name:   "failed: name with separator",
+			folder: Folder{ID: "new", Name: "a/b"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: unknown parent",
+			folder: Folder{ID: "new", Name: "Orphan", ParentID: "missing"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: duplicate sibling name",
+			folder: Folder{ID: "new", Name: "work"},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newFolderVault()
+			err := vault.CreateFolder(test.folder)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Contains(t, vault.Folders, test.folder.ID)
+			}
+		})
+	}
+}
+
+func TestVault_RenameFolder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		id      string
+		newName string
+		hasErr  bool
+	}{
+		{
+			name:    "succeed: rename",
+			id:      "clients",
+			newName: "Customers",
+			hasErr:  false,
+		},
+		{
+			name:    "succeed: change case",
+			id:      "work",
+			newName: "WORK",
+			hasErr:  false,
+		},
+		{
+			name:    "failed: folder not found",
+			id:      "missing",
+			newName: "Name",
+			hasErr:  true,
+		},
+		{
+			name:    "failed: sibling has the name",
+			id:      "work",
+			newName: "Personal",
+			hasErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newFolderVault()
+			err := vault.RenameFolder(test.id, test.newName)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.newName, vault.Folders[test.id].Name)
+			}
+		})
+	}
+}
+
+func TestVault_MoveFolder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		id       string
+		parentID string
+		hasErr   bool
+	}{
+		{
+			name:     "succeed: move under another folder",
+			id:       "clients",
+			parentID: "personal",
+			hasErr:   false,
+		},
+		{
+			name:     "succeed: move to top level",
+			id:       "acme",
+			parentID: "",
+			hasErr:   false,
+		},
+		{
+			name:     "failed: into itself",
+			id:       "work",
+			parentID: "work",
+			hasErr:   true,
+		},
+		{
+			name:     "failed: into a subfolder",
+			id:       "work",
+			parentID: "acme",
+			hasErr:   true,
+		},
+		{
+			name:     "failed: unknown parent",
+			id:       "work",
+			parentID: "missing",
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newFolderVault()
+			err := vault.MoveFolder(test.id, test.parentID)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.parentID, vault.Folders[test.id].ParentID)
+			}
+		})
+	}
+}
+
+func TestVault_DeleteFolder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		setup       func(*Vault)
+		id          string
+		cascade     bool
+		wantFolders []string
+		wantEntries []string
+		wantTrash   int
+		err         error
+	}{
+		{
+			name:        "succeed: empty folder",
+			setup:       func(v *Vault) { v.Folders["empty"] = &Folder{ID: "empty", Name: "Empty"} },
+			id:          "empty",
+			wantFolders: []string{"work", "clients", "acme", "personal"},
+			wantEntries: []string{"acme-db", "jira", "bank", "misc"},
+		},
+		{
+			name:        "succeed: cascade removes subfolders and trashes entries",
+			setup:       func(v *Vault) {},
+			id:          "work",
+			cascade:     true,
+			wantFolders: []string{"personal"},
+			wantEntries: []string{"bank", "misc"},
+			wantTrash:   2,
+		},
+		{
+			name:  "failed: folder has entries",
+			setup: func(v *Vault) {},
+			id:    "personal",
+			err:   ErrFolderNotEmpty,
+		},
+		{
+			name:  "failed: folder has subfolders",
+			setup: func(v *Vault) { delete(v.Entries, "jira") },
+			id:    "work",
+			err:   ErrFolderNotEmpty,
+		},
+		{
+			name:  "failed: folder not found",
+			setup: func(v *Vault) {},
+			id:    "missing",
+			err:   ErrFolderNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newFolderVault()
+			test.setup(vault)
+			err := vault.DeleteFolder(test.id, test.cascade)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, vault.Folders, len(test.wantFolders))
+			for _, id := range test.wantFolders {
+				assert.Contains(t, vault.Folders, id)
+			}
+			assert.Len(t, vault.Entries, len(test.wantEntries))
+			for _, id := range test.wantEntries {
+				assert.Contains(t, vault.Entries, id)
+			}
+			assert.Len(t, vault.Trash, test.wantTrash)
+		})
+	}
+}
+
+func TestVault_MoveEntry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		entryID  string
+		folderID string
+		hasErr   bool
+	}{
+		{
+			name:     "succeed: into folder",
+			entryID:  "misc",
+			folderID: "acme",
+			hasErr:   false,
+		},
+		{
+			name:     "succeed: to top level",
+			entryID:  "bank",
+			folderID: "",
+			hasErr:   false,
+		},
+		{
+			name:     "failed: entry not found",
+			entryID:  "missing",
+			folderID: "work",
+			hasErr:   true,
+		},
+		{
+			name:     "failed: folder not found",
+			entryID:  "misc",
+			folderID: "missing",
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newFolderVault()
+			err := vault.MoveEntry(test.entryID, test.folderID)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.folderID, vault.Entries[test.entryID].FolderID)
+			}
+		})
+	}
+}
+
+func TestVault_ListFolders(t *testing.T) {
+	t.Parallel()
+	vault := newFolderVault()
+
+	var ids []string
+	for _, folder := range vault.ListFolders() {
+		ids = append(ids, folder.ID)
+	}
+	assert.Equal(t, []string{"personal", "work", "clients", "acme"}, ids)
+}
+
+func TestVault_FolderPath(t *testing.T) {
+	t.Parallel()
+	vault := newFolderVault()
+
+	path, err := vault.FolderPath("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, "Work/Clients/Acme", path)
+
+	path, err = vault.FolderPath("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", path)
+
+	_, err = vault.FolderPath("missing")
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+}
+
+func TestVault_FilterEntries(t *testing.T) {
+	t.Parallel()
+	vault := newFolderVault()
+	vault.Entries["jira"].SetTags([]string{"dev"})
+	vault.Entries["acme-db"].SetTags([]string{"dev"})
+	vault.Entries["bank"].Type = EntryCard
+
+	tests := []struct {
+		name   string
+		filter EntryFilter
+		want   []string
+	}{
+		{
+			name:   "succeed: zero filter lists everything",
+			filter: EntryFilter{},
+			want:   []string{"acme-db", "bank", "jira", "misc"},
+		},
+		{
+			name:   "succeed: folder includes subfolders",
+			filter: EntryFilter{FolderID: "work"},
+			want:   []string{"acme-db", "jira"},
+		},
+		{
+			name:   "succeed: folder and tags",
+			filter: EntryFilter{FolderID: "clients", Tags: []string{"dev"}},
+			want:   []string{"acme-db"},
+		},
+		{
+			name:   "succeed: type",
+			filter: EntryFilter{Type: EntryCard},
+			want:   []string{"bank"},
+		},
+		{
+			name:   "succeed: unknown folder matches nothing",
+			filter: EntryFilter{FolderID: "missing"},
+			want:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.ElementsMatch(t, test.want, entryIDs(vault.FilterEntries(test.filter)))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+const (
+	defaultPassphraseWords = 6
+	minPassphraseWords     = 3
+	maxPassphraseWords     = 20
+	// MinWordlistSize keeps user-supplied wordlists from producing weak
+	// passphrases. The EFF short list has 1296 words.
+	MinWordlistSize = 1000
+)
+
+var ErrInvalidWordlist = errors.New("invalid wordlist")
+
+// PasswordMode selects between random characters and a diceware
+// passphrase.
+type PasswordMode string
+
+const (
+	PasswordModeCharacters PasswordMode = "characters"
+	PasswordModePassphrase PasswordMode = "passphrase"
+)
+
+type PassphraseOptions struct {
+	Words      int
+	Separator  string
+	Capitalize bool
+	// IncludeDigit and IncludeSymbol append a random digit or symbol to a
+	// random word.
+	IncludeDigit  bool
+	IncludeSymbol bool
+}
+
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{
+		Words:     defaultPassphraseWords,
+		Separator: "-",
+	}
+}
+
+func (opts PassphraseOptions) Validate() error {
+	if opts.Words < minPassphraseWords {
+		return fmt.Errorf("passphrase must have at least %d words", minPassphraseWords)
+	}
+	if opts.Words > maxPassphraseWords {
+		return fmt.Errorf("passphrase must have at most %d words", maxPassphraseWords)
+	}
+	return nil
+}
+
+var defaultWordlist = sync.OnceValue(func() []string {
+	words, err := ParseWordlist(effLargeWordlist)
+	if err != nil {
+		panic(err)
+	}
+	return words
+})
+
+// ParseWordlist reads one word per line. Lines in the diceware format,
+// where the word follows the dice roll, are accepted too. Duplicate words
+// are dropped, as they would make the passphrase weaker than its entropy
+// suggests.
+func ParseWordlist(data string) ([]string, error) {
+	seen := make(map[string]bool)
+	var words []string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWordlist, err)
+	}
+	if len(words) < MinWordlistSize {
+		return nil, fmt.Errorf("%w: %d words, at least %d are required", ErrInvalidWordlist, len(words), MinWordlistSize)
+	}
+	return words, nil
+}
+
+// NewPassphraseGenerator returns a password generator whose passphrases are
+// drawn from words instead of the embedded EFF large wordlist.
+func NewPassphraseGenerator(words []string) *PasswordGenerator {
+	return &PasswordGenerator{words: words}
+}
+
+func (pg *PasswordGenerator) wordlist() []string {
+	if pg.words != nil {
+		return pg.words
+	}
+	return defaultWordlist()
+}
+
+func (pg *PasswordGenerator) generatePassphrase(opts PassphraseOptions) (string, error) {
+	wordlist := pg.wordlist()
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = wordlist[n]
+		if opts.Capitalize {
+			r, size := utf8.DecodeRuneInString(words[i])
+			words[i] = string(unicode.ToUpper(r)) + words[i][size:]
+		}
+	}
+
+	for _, extra := range []struct {
+		include bool
+		charset string
+	}{{opts.IncludeDigit, digits}, {opts.IncludeSymbol, symbols}} {
+		if !extra.include {
+			continue
+		}
+		word, err := randomInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		char, err := randomInt(len(extra.charset))
+		if err != nil {
+			return "", err
+		}
+		words[word] += extra.charset[char : char+1]
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
+
+// Entropy returns the bits of entropy of passwords generated with opts,
+// assuming the attacker knows the options and the wordlist. Minimum counts
+// per character class lower it slightly and are not accounted for.
+func (pg *PasswordGenerator) Entropy(opts PasswordOptions) float64 {
+	if opts.Mode != PasswordModePassphrase {
+		return float64(opts.Length) * math.Log2(float64(len(pg.buildCharset(opts))))
+	}
+
+	passphrase := opts.Passphrase
+	bits := float64(passphrase.Words) * math.Log2(float64(len(pg.wordlist())))
+	if passphrase.IncludeDigit {
+		bits += math.Log2(float64(len(digits) * passphrase.Words))
+	}
+	if passphrase.IncludeSymbol {
+		bits += math.Log2(float64(len(symbols) * passphrase.Words))
+	}
+	return bits
+}
+
+func randomInt(n int) (int, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(num.Int64()), nil
+}
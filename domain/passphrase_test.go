@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordGenerator_GeneratePassphrase(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		opts   PassphraseOptions
+		check  func(*testing.T, []string)
+		hasErr bool
+	}{
+		{
+			name: "succeed: default options",
+			opts: DefaultPassphraseOptions(),
+			check: func(t *testing.T, words []string) {
+				assert.Len(t, words, 6)
+				for _, word := range words {
+					assert.Contains(t, defaultWordlist(), word)
+				}
+			},
+		},
+		{
+			name: "succeed: capitalized",
+			opts: PassphraseOptions{Words: 4, Separator: " ", Capitalize: true},
+			check: func(t *testing.T, words []string) {
+				assert.Len(t, words, 4)
+				for _, word := range words {
+					assert.True(t, unicode.IsUpper([]rune(word)[0]), word)
+				}
+			},
+		},
+		{
+			name: "succeed: digit and symbol",
+			opts: PassphraseOptions{Words: 5, Separator: " ", IncludeDigit: true, IncludeSymbol: true},
+			check: func(t *testing.T, words []string) {
+				joined := strings.Join(words, "")
+				assert.True(t, strings.ContainsAny(joined, digits), joined)
+				assert.True(t, strings.ContainsAny(joined, symbols), joined)
+			},
+		},
+		{
+			name:   "failed: too few words",
+			opts:   PassphraseOptions{Words: 2, Separator: "-"},
+			hasErr: true,
+		},
+		{
+			name:   "failed: too many words",
+			opts:   PassphraseOptions{Words: 21, Separator: "-"},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			pg := NewPasswordGenerator()
+			got, err := pg.GenerateWithOptions(PasswordOptions{Mode: PasswordModePassphrase, Passphrase: test.opts})
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				test.check(t, strings.Split(got, test.opts.Separator))
+			}
+		})
+	}
+}
+
+func TestPasswordGenerator_Entropy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts PasswordOptions
+		want float64
+	}{
+		{
+			name: "succeed: characters",
+			opts: PasswordOptions{Length: 16, IncludeLowercase: true, IncludeUppercase: true, IncludeDigits: true},
+			want: 16 * math.Log2(62),
+		},
+		{
+			name: "succeed: passphrase",
+			opts: PasswordOptions{Mode: PasswordModePassphrase, Passphrase: PassphraseOptions{Words: 6}},
+			want: 6 * math.Log2(7776),
+		},
+		{
+			name: "succeed: capitalization adds nothing",
+			opts: PasswordOptions{Mode: PasswordModePassphrase, Passphrase: PassphraseOptions{Words: 6, Capitalize: true}},
+			want: 6 * math.Log2(7776),
+		},
+		{
+			name: "succeed: digit and symbol",
+			opts: PasswordOptions{Mode: PasswordModePassphrase, Passphrase: PassphraseOptions{Words: 4, IncludeDigit: true, IncludeSymbol: true}},
+			want: 4*math.Log2(7776) + math.Log2(10*4) + math.Log2(float64(len(symbols)*4)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, test.want, NewPasswordGenerator().Entropy(test.opts), 1e-9)
+		})
+	}
+}
+
+func TestParseWordlist(t *testing.T) {
+	t.Parallel()
+
+	plain := make([]string, MinWordlistSize)
+	diceware := make([]string, MinWordlistSize)
+	for i := range plain {
+		plain[i] = fmt.Sprintf("word%d", i)
+		diceware[i] = fmt.Sprintf("%05d\tword%d", i, i)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		want   int
+		hasErr bool
+	}{
+		{
+			name: "succeed: one word per line",
+			data: strings.Join(plain, "\n"),
+			want: MinWordlistSize,
+		},
+		{
+			name: "succeed: diceware format",
+			data: strings.Join(diceware, "\n"),
+			want: MinWordlistSize,
+		},
+		{
+			name: "succeed: duplicates are dropped",
+			data: strings.Join(plain, "\n") + "\n" + plain[0],
+			want: MinWordlistSize,
+		},
+		{
+			name:   "failed: too small",
+			data:   strings.Join(plain[:10], "\n"),
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseWordlist(test.data)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidWordlist)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewPassphraseGenerator(t *testing.T) {
+	t.Parallel()
+	words := make([]string, MinWordlistSize)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+
+	pg := NewPassphraseGenerator(words)
+	got, err := pg.GenerateWithOptions(PasswordOptions{Mode: PasswordModePassphrase, Passphrase: PassphraseOptions{Words: 3, Separator: "_"}})
+	assert.NoError(t, err)
+	for _, word := range strings.Split(got, "_") {
+		assert.Contains(t, words, word)
+	}
+	assert.InDelta(t, 3*math.Log2(MinWordlistSize), pg.Entropy(PasswordOptions{Mode: PasswordModePassphrase, Passphrase: PassphraseOptions{Words: 3}}), 1e-9)
+}
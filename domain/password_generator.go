@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"strings"
 )
 
 const (
@@ -14,29 +15,90 @@ const (
 	uppercase     = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	digits        = "0123456789"
 	symbols       = "!@#$%^&*()-_=+[]{}|;:,.<>?"
+	// ambiguousChars are easily confused with each other in many fonts.
+	ambiguousChars = "0O1lI"
 )
 
-type PasswordGenerator struct{}
+type PasswordGenerator struct {
+	// words is the passphrase wordlist, nil for the embedded EFF list.
+	words []string
+}
 
 type PasswordOptions struct {
+	// Mode defaults to random characters.
+	Mode             PasswordMode
+	Passphrase       PassphraseOptions
 	Length           int
 	IncludeLowercase bool
 	IncludeUppercase bool
 	IncludeDigits    bool
 	IncludeSymbols   bool
+	// MinLowercase, MinUppercase, MinDigits and MinSymbols are the least
+	// number of characters of each class in the password.
+	MinLowercase int
+	MinUppercase int
+	MinDigits    int
+	MinSymbols   int
+	// ExcludeAmbiguous leaves out characters like 0/O and l/1/I.
+	ExcludeAmbiguous bool
+	// ExcludeChars are never used.
+	ExcludeChars string
+	// Symbols replaces the default symbol set when not empty.
+	Symbols string
+}
+
+// charClass is one class of characters a password is drawn from.
+type charClass struct {
+	name    string
+	chars   string
+	include bool
+	min     int
+}
+
+// classes returns the character classes with the excluded characters
+// removed.
+func (opts PasswordOptions) classes() []charClass {
+	symbolSet := symbols
+	if opts.Symbols != "" {
+		symbolSet = opts.Symbols
+	}
+
+	classes := []charClass{
+		{"lowercase", lowercase, opts.IncludeLowercase, opts.MinLowercase},
+		{"uppercase", uppercase, opts.IncludeUppercase, opts.MinUppercase},
+		{"digits", digits, opts.IncludeDigits, opts.MinDigits},
+		{"symbols", symbolSet, opts.IncludeSymbols, opts.MinSymbols},
+	}
+	for i := range classes {
+		// Repeated characters would be drawn more often than others.
+		var chars strings.Builder
+		for _, r := range classes[i].chars {
+			excluded := strings.ContainsRune(opts.ExcludeChars, r) || (opts.ExcludeAmbiguous && strings.ContainsRune(ambiguousChars, r))
+			if !excluded && !strings.ContainsRune(chars.String(), r) {
+				chars.WriteRune(r)
+			}
+		}
+		classes[i].chars = chars.String()
+	}
+	return classes
 }
 
 func DefaultPasswordOptions() PasswordOptions {
 	return PasswordOptions{
+		Mode:             PasswordModeCharacters,
 		Length:           defaultLength,
 		IncludeLowercase: true,
 		IncludeUppercase: true,
 		IncludeDigits:    true,
 		IncludeSymbols:   false,
+		Passphrase:       DefaultPassphraseOptions(),
 	}
 }
 
 func (opts PasswordOptions) Validate() error {
+	if opts.Mode == PasswordModePassphrase {
+		return opts.Passphrase.Validate()
+	}
 	if opts.Length < minLength {
 		return fmt.Errorf("password length must be at least %d", minLength)
 	}
@@ -46,6 +108,28 @@ func (opts PasswordOptions) Validate() error {
 	if !opts.IncludeLowercase && !opts.IncludeUppercase && !opts.IncludeDigits && !opts.IncludeSymbols {
 		return fmt.Errorf("at least one character type must be selected")
 	}
+	for _, r := range opts.Symbols {
+		if r <= ' ' || r > '~' || strings.ContainsRune(lowercase+uppercase+digits, r) {
+			return fmt.Errorf("symbol set may only contain printable ASCII punctuation")
+		}
+	}
+
+	required := 0
+	for _, class := range opts.classes() {
+		if class.min < 0 {
+			return fmt.Errorf("minimum %s must not be negative", class.name)
+		}
+		if class.min > 0 && !class.include {
+			return fmt.Errorf("minimum %s requires %s to be included", class.name, class.name)
+		}
+		if class.include && class.chars == "" {
+			return fmt.Errorf("all %s are excluded", class.name)
+		}
+		required += class.min
+	}
+	if required > opts.Length {
+		return fmt.Errorf("minimum counts add up to %d, more than the length %d", required, opts.Length)
+	}
 	return nil
 }
 
@@ -75,23 +159,50 @@ func (pg *PasswordGenerator) Generate(length int) (string, error) {
 	return string(password), nil
 }
 
+// GenerateWithOptions draws the minimum number of characters of each class
+// first, fills the rest of the password from all included classes and
+// shuffles the result, so every password meeting Validate can be generated.
 func (pg *PasswordGenerator) GenerateWithOptions(opts PasswordOptions) (string, error) {
 	if err := opts.Validate(); err != nil {
 		return "", err
 	}
 
+	if opts.Mode == PasswordModePassphrase {
+		return pg.generatePassphrase(opts.Passphrase)
+	}
+
 	charset := pg.buildCharset(opts)
 	if len(charset) == 0 {
 		return "", fmt.Errorf("no characters available for password generation")
 	}
 
-	password := make([]byte, opts.Length)
-	for i := 0; i < opts.Length; i++ {
-		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	sampler := newByteSampler()
+	password := make([]byte, 0, opts.Length)
+	for _, class := range opts.classes() {
+		for i := 0; i < class.min; i++ {
+			n, err := sampler.intn(len(class.chars))
+			if err != nil {
+				return "", err
+			}
+			password = append(password, class.chars[n])
+		}
+	}
+	for len(password) < opts.Length {
+		n, err := sampler.intn(len(charset))
 		if err != nil {
 			return "", err
 		}
-		password[i] = charset[num.Int64()]
+		password = append(password, charset[n])
+	}
+
+	// Fisher-Yates shuffle, so the required characters are not always in
+	// front.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := sampler.intn(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
 	}
 
 	return string(password), nil
@@ -99,19 +210,42 @@ func (pg *PasswordGenerator) GenerateWithOptions(opts PasswordOptions) (string,
 
 func (pg *PasswordGenerator) buildCharset(opts PasswordOptions) string {
 	var charset string
-
-	if opts.IncludeLowercase {
-		charset += lowercase
-	}
-	if opts.IncludeUppercase {
-		charset += uppercase
-	}
-	if opts.IncludeDigits {
-		charset += digits
-	}
-	if opts.IncludeSymbols {
-		charset += symbols
+	for _, class := range opts.classes() {
+		if class.include {
+			charset += class.chars
+		}
 	}
-
 	return charset
 }
+
+// byteSampler draws unbiased random indexes from buffered random bytes, so
+// that generating a password does not read crypto/rand once per character.
+type byteSampler struct {
+	buf []byte
+	pos int
+}
+
+func newByteSampler() *byteSampler {
+	buf := make([]byte, 256)
+	return &byteSampler{buf: buf, pos: len(buf)}
+}
+
+// intn returns a uniform random number in [0, n). n must be at most 256.
+// Bytes at or above the largest multiple of n are rejected to avoid modulo
+// bias.
+func (s *byteSampler) intn(n int) (int, error) {
+	limit := 256 - 256%n
+	for {
+		if s.pos == len(s.buf) {
+			if _, err := rand.Read(s.buf); err != nil {
+				return 0, err
+			}
+			s.pos = 0
+		}
+		b := int(s.buf[s.pos])
+		s.pos++
+		if b < limit {
+			return b % n, nil
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordOptions_ValidatePolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		opts   func(*PasswordOptions)
+		hasErr bool
+	}{
+		{
+			name: "succeed: minimums within length",
+			opts: func(o *PasswordOptions) { o.MinDigits, o.MinUppercase = 4, 4 },
+		},
+		{
+			name: "succeed: custom symbols",
+			opts: func(o *PasswordOptions) { o.IncludeSymbols, o.Symbols = true, "-_." },
+		},
+		{
+			name:   "failed: negative minimum",
+			opts:   func(o *PasswordOptions) { o.MinDigits = -1 },
+			hasErr: true,
+		},
+		{
+			name:   "failed: minimum for excluded class",
+			opts:   func(o *PasswordOptions) { o.MinSymbols = 1 },
+			hasErr: true,
+		},
+		{
+			name:   "failed: minimums exceed length",
+			opts:   func(o *PasswordOptions) { o.MinLowercase, o.MinDigits = 10, 10 },
+			hasErr: true,
+		},
+		{
+			name:   "failed: class fully excluded",
+			opts:   func(o *PasswordOptions) { o.ExcludeChars = digits },
+			hasErr: true,
+		},
+		{
+			name:   "failed: letters in symbol set",
+			opts:   func(o *PasswordOptions) { o.IncludeSymbols, o.Symbols = true, "!a" },
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			opts := DefaultPasswordOptions()
+			test.opts(&opts)
+			err := opts.Validate()
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordGenerator_GenerateWithPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		opts  func(*PasswordOptions)
+		check func(*testing.T, string)
+	}{
+		{
+			name: "succeed: minimum counts are met",
+			opts: func(o *PasswordOptions) {
+				o.Length, o.IncludeSymbols = 8, true
+				o.MinLowercase, o.MinUppercase, o.MinDigits, o.MinSymbols = 2, 2, 2, 2
+			},
+			check: func(t *testing.T, got string) {
+				count := func(set string) int {
+					n := 0
+					for _, r := range got {
+						if strings.ContainsRune(set, r) {
+							n++
+						}
+					}
+					return n
+				}
+				assert.Equal(t, 2, count(lowercase), got)
+				assert.Equal(t, 2, count(uppercase), got)
+				assert.Equal(t, 2, count(digits), got)
+				assert.Equal(t, 2, count(symbols), got)
+			},
+		},
+		{
+			name: "succeed: ambiguous characters excluded",
+			opts: func(o *PasswordOptions) { o.Length, o.ExcludeAmbiguous = 64, true },
+			check: func(t *testing.T, got string) {
+				assert.False(t, strings.ContainsAny(got, ambiguousChars), got)
+			},
+		},
+		{
+			name: "succeed: custom exclusions",
+			opts: func(o *PasswordOptions) { o.Length, o.ExcludeChars = 64, "abcXYZ789" },
+			check: func(t *testing.T, got string) {
+				assert.False(t, strings.ContainsAny(got, "abcXYZ789"), got)
+			},
+		},
+		{
+			name: "succeed: custom symbol set",
+			opts: func(o *PasswordOptions) {
+				o.Length, o.IncludeSymbols, o.Symbols, o.MinSymbols = 32, true, "-_", 4
+			},
+			check: func(t *testing.T, got string) {
+				assert.False(t, strings.ContainsAny(got, strings.NewReplacer("-", "", "_", "").Replace(symbols)), got)
+				assert.GreaterOrEqual(t, strings.Count(got, "-")+strings.Count(got, "_"), 4, got)
+			},
+		},
+	}
+
+	pg := NewPasswordGenerator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			opts := DefaultPasswordOptions()
+			test.opts(&opts)
+			for i := 0; i < 20; i++ {
+				got, err := pg.GenerateWithOptions(opts)
+				assert.NoError(t, err)
+				assert.Len(t, got, opts.Length)
+				test.check(t, got)
+			}
+		})
+	}
+}
+
+func TestPasswordGenerator_GenerateWithPolicyExactMinimums(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts func(*PasswordOptions)
+	}{
+		{
+			name: "succeed: one class fills the password",
+			opts: func(o *PasswordOptions) { o.Length, o.MinDigits = 8, 8 },
+		},
+		{
+			name: "succeed: one class fills the longest password",
+			opts: func(o *PasswordOptions) { o.Length, o.MinDigits = 64, 64 },
+		},
+		{
+			name: "succeed: minimums of all classes add up to the length",
+			opts: func(o *PasswordOptions) {
+				o.Length, o.IncludeSymbols = 10, true
+				o.MinLowercase, o.MinUppercase, o.MinDigits, o.MinSymbols = 1, 2, 3, 4
+			},
+		},
+	}
+
+	pg := NewPasswordGenerator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			opts := DefaultPasswordOptions()
+			test.opts(&opts)
+			// The minimums add up to the length, so they are exact counts.
+			mins := []int{opts.MinLowercase, opts.MinUppercase, opts.MinDigits, opts.MinSymbols}
+			for i := 0; i < 20; i++ {
+				got, err := pg.GenerateWithOptions(opts)
+				assert.NoError(t, err)
+				assert.Len(t, got, opts.Length)
+				for j, set := range []string{lowercase, uppercase, digits, symbols} {
+					n := 0
+					for _, r := range got {
+						if strings.ContainsRune(set, r) {
+							n++
+						}
+					}
+					assert.Equal(t, mins[j], n, got)
+				}
+			}
+		})
+	}
+}
+
+func TestByteSampler_Uniform(t *testing.T) {
+	t.Parallel()
+	sampler := newByteSampler()
+	counts := make([]int, 7)
+	const draws = 70000
+	for i := 0; i < draws; i++ {
+		n, err := sampler.intn(len(counts))
+		assert.NoError(t, err)
+		counts[n]++
+	}
+	for _, count := range counts {
+		assert.InDelta(t, draws/len(counts), count, 600)
+	}
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SecretReferenceScheme starts a reference to a field of an entry, as in
+// pv://GitHub/password.
+const SecretReferenceScheme = "pv://"
+
+var ErrInvalidReference = errors.New("invalid secret reference")
+
+// SecretReference points at a field of an entry. It lets configuration
+// name a secret without containing it.
+type SecretReference struct {
+	// Entry is the ID or the title of the entry, see Vault.FindEntry.
+	Entry string
+	// Field is a field name as accepted by Entry.Field.
+	Field string
+}
+
+func IsSecretReference(s string) bool {
+	return strings.HasPrefix(s, SecretReferenceScheme)
+}
+
+// ParseSecretReference parses pv://<entry>/<field>. The field is the part
+// after the last slash, so titles may contain slashes. Both parts may be
+// percent-encoded, for titles with spaces in places that do not allow them.
+func ParseSecretReference(s string) (SecretReference, error) {
+	if !IsSecretReference(s) {
+		return SecretReference{}, fmt.Errorf("%w: %q does not start with %s", ErrInvalidReference, s, SecretReferenceScheme)
+	}
+
+	path := strings.TrimPrefix(s, SecretReferenceScheme)
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return SecretReference{}, fmt.Errorf("%w: %q has no field, use %s<entry>/<field>", ErrInvalidReference, s, SecretReferenceScheme)
+	}
+
+	entry, err := url.PathUnescape(path[:i])
+	if err != nil {
+		return SecretReference{}, fmt.Errorf("%w: %q: %v", ErrInvalidReference, s, err)
+	}
+	field, err := url.PathUnescape(path[i+1:])
+	if err != nil {
+		return SecretReference{}, fmt.Errorf("%w: %q: %v", ErrInvalidReference, s, err)
+	}
+	if entry == "" || field == "" {
+		return SecretReference{}, fmt.Errorf("%w: %q needs both an entry and a field", ErrInvalidReference, s)
+	}
+
+	return SecretReference{Entry: entry, Field: field}, nil
+}
+
+func (r SecretReference) String() string {
+	return SecretReferenceScheme + url.PathEscape(r.Entry) + "/" + url.PathEscape(r.Field)
+}
+
+// Resolve returns the value of the referenced field. A TOTP field resolves
+// to the code valid at the given time.
+func (v *Vault) Resolve(ref SecretReference, at time.Time) (string, error) {
+	entry, err := v.FindEntry(ref.Entry)
+	if err != nil {
+		return "", err
+	}
+	return entry.Field(ref.Field, at)
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSecretReference(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   SecretReference
+		hasErr bool
+	}{
+		{
+			name:  "succeed: title and field",
+			input: "pv://GitHub/password",
+			want:  SecretReference{Entry: "GitHub", Field: "password"},
+		},
+		{
+			name:  "succeed: title with slash",
+			input: "pv://Work/GitHub/API Token",
+			want:  SecretReference{Entry: "Work/GitHub", Field: "API Token"},
+		},
+		{
+			name:  "succeed: percent-encoded",
+			input: "pv://My%20Bank/password",
+			want:  SecretReference{Entry: "My Bank", Field: "password"},
+		},
+		{
+			name:   "failed: other scheme",
+			input:  "https://example.com/password",
+			hasErr: true,
+		},
+		{
+			name:   "failed: no field",
+			input:  "pv://GitHub",
+			hasErr: true,
+		},
+		{
+			name:   "failed: empty field",
+			input:  "pv://GitHub/",
+			hasErr: true,
+		},
+		{
+			name:   "failed: bad escape",
+			input:  "pv://Git%zzHub/password",
+			hasErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseSecretReference(test.input)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidReference)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestSecretReference_String(t *testing.T) {
+	t.Parallel()
+	ref := SecretReference{Entry: "My Bank", Field: "password"}
+
+	parsed, err := ParseSecretReference(ref.String())
+	assert.NoError(t, err)
+	assert.Equal(t, ref, parsed)
+}
+
+func TestVault_Resolve(t *testing.T) {
+	t.Parallel()
+	vault := NewVault()
+	vault.Entries["id-1"] = &Entry{ID: "id-1", Title: "GitHub", Password: "secret"}
+
+	tests := []struct {
+		name    string
+		ref     SecretReference
+		want    string
+		wantErr error
+	}{
+		{
+			name: "succeed: by title",
+			ref:  SecretReference{Entry: "github", Field: "password"},
+			want: "secret",
+		},
+		{
+			name: "succeed: by id",
+			ref:  SecretReference{Entry: "id-1", Field: "title"},
+			want: "GitHub",
+		},
+		{
+			name:    "failed: unknown entry",
+			ref:     SecretReference{Entry: "gitlab", Field: "password"},
+			wantErr: ErrEntryNotFound,
+		},
+		{
+			name:    "failed: unknown field",
+			ref:     SecretReference{Entry: "github", Field: "pin"},
+			wantErr: ErrFieldNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := vault.Resolve(test.ref, time.Now())
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
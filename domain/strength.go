@@ -0,0 +1,892 @@
+package domain
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// The strength estimator follows the approach of zxcvbn: the password is
+// split into the cheapest sequence of guessable patterns, and its strength
+// is the number of guesses an attacker needs to enumerate that sequence.
+
+var (
+	//go:embed wordlists/passwords.txt
+	commonPasswordList string
+	//go:embed wordlists/words.txt
+	commonWordList string
+)
+
+// PatternKind names the kind of weakness a part of a password matched.
+type PatternKind string
+
+const (
+	PatternDictionary PatternKind = "dictionary"
+	PatternKeyboard   PatternKind = "keyboard"
+	PatternRepeat     PatternKind = "repeat"
+	PatternSequence   PatternKind = "sequence"
+	PatternDate       PatternKind = "date"
+	PatternBruteforce PatternKind = "bruteforce"
+)
+
+// Dictionaries a dictionary match can come from.
+const (
+	DictionaryPasswords  = "passwords"
+	DictionaryWords      = "words"
+	DictionaryUserInputs = "user_inputs"
+)
+
+// GuessesPerSecond is the assumed speed of an offline attack against a slow
+// password hash.
+const GuessesPerSecond = 1e4
+
+// minSubmatchGuesses keeps a pattern that only covers part of the password
+// from being counted as nearly free.
+const minSubmatchGuesses = 50
+
+// PasswordMatch is a part of a password that matched a pattern. Start and
+// End are rune offsets, End is exclusive.
+type PasswordMatch struct {
+	Pattern    PatternKind `json:"pattern"`
+	Token      string      `json:"token"`
+	Start      int         `json:"start"`
+	End        int         `json:"end"`
+	Guesses    float64     `json:"guesses"`
+	Dictionary string      `json:"dictionary,omitempty"`
+	Rank       int         `json:"rank,omitempty"`
+	Reversed   bool        `json:"reversed,omitempty"`
+	L33t       bool        `json:"l33t,omitempty"`
+	// Turns is the number of direction changes of a keyboard walk plus one.
+	Turns int `json:"turns,omitempty"`
+	// BaseToken is the repeated unit of a repeat match.
+	BaseToken string `json:"base_token,omitempty"`
+	Year      int    `json:"year,omitempty"`
+}
+
+// Strength is the result of estimating a password.
+type Strength struct {
+	// Score ranges from 0 (too guessable) to 4 (very unguessable).
+	Score   int     `json:"score"`
+	Guesses float64 `json:"guesses"`
+	// Entropy is log2 of Guesses.
+	Entropy          float64         `json:"entropy"`
+	CrackTimeSeconds float64         `json:"crack_time_seconds"`
+	Matches          []PasswordMatch `json:"matches"`
+	Warning          string          `json:"warning,omitempty"`
+	Suggestions      []string        `json:"suggestions,omitempty"`
+}
+
+func (s Strength) Label() string {
+	switch s.Score {
+	case 0:
+		return "Very weak"
+	case 1:
+		return "Weak"
+	case 2:
+		return "Fair"
+	case 3:
+		return "Strong"
+	default:
+		return "Very strong"
+	}
+}
+
+// CrackTime formats CrackTimeSeconds for display, e.g. "3 hours".
+func (s Strength) CrackTime() string {
+	seconds := s.CrackTimeSeconds
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", 365 * 24 * 3600},
+		{"month", 30 * 24 * 3600},
+		{"day", 24 * 3600},
+		{"hour", 3600},
+		{"minute", 60},
+		{"second", 1},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= 100*units[0].seconds:
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.seconds {
+			n := int(math.Round(seconds / unit.seconds))
+			if n == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+	return "less than a second"
+}
+
+type StrengthEstimator struct {
+	dictionaries map[string]map[string]int
+	now          func() time.Time
+}
+
+var (
+	rankedDictionariesOnce sync.Once
+	rankedDictionaries     map[string]map[string]int
+)
+
+// NewStrengthEstimator returns an estimator backed by the embedded lists of
+// common passwords and words.
+func NewStrengthEstimator() *StrengthEstimator {
+	rankedDictionariesOnce.Do(func() {
+		rankedDictionaries = map[string]map[string]int{
+			DictionaryPasswords: rankWords(commonPasswordList),
+			DictionaryWords:     rankWords(commonWordList),
+		}
+	})
+	return &StrengthEstimator{
+		dictionaries: rankedDictionaries,
+		now:          time.Now,
+	}
+}
+
+// rankWords maps each lowercased word of a list, one per line, to its
+// 1-based position. The first occurrence of a word wins.
+func rankWords(list string) map[string]int {
+	ranked := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if _, exists := ranked[word]; word != "" && !exists {
+			ranked[word] = len(ranked) + 1
+		}
+	}
+	return ranked
+}
+
+// Estimate scores password. userInputs, such as the entry title, username
+// or URL, are treated as an extra dictionary because passwords derived
+// from them are easy to guess for anyone who can see the entry.
+func (se *StrengthEstimator) Estimate(password string, userInputs ...string) Strength {
+	dictionaries := se.dictionaries
+	if inputs := userInputDictionary(userInputs); len(inputs) > 0 {
+		dictionaries = make(map[string]map[string]int, len(se.dictionaries)+1)
+		for name, ranked := range se.dictionaries {
+			dictionaries[name] = ranked
+		}
+		dictionaries[DictionaryUserInputs] = inputs
+	}
+
+	guesses, matches := se.mostGuessableMatches(password, dictionaries)
+	strength := Strength{
+		Score:            scoreGuesses(guesses),
+		Guesses:          guesses,
+		Entropy:          math.Log2(guesses),
+		CrackTimeSeconds: guesses / GuessesPerSecond,
+		Matches:          matches,
+	}
+	strength.Warning, strength.Suggestions = feedback(strength)
+	return strength
+}
+
+func userInputDictionary(inputs []string) map[string]int {
+	ranked := make(map[string]int)
+	add := func(word string) {
+		word = strings.ToLower(word)
+		if _, exists := ranked[word]; len([]rune(word)) >= 3 && !exists {
+			ranked[word] = len(ranked) + 1
+		}
+	}
+	for _, input := range inputs {
+		add(strings.TrimSpace(input))
+		for _, word := range strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			add(word)
+		}
+	}
+	return ranked
+}
+
+func scoreGuesses(guesses float64) int {
+	switch {
+	case guesses < 1e3+5:
+		return 0
+	case guesses < 1e6+5:
+		return 1
+	case guesses < 1e8+5:
+		return 2
+	case guesses < 1e10+5:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// mostGuessableMatches finds the sequence of non-overlapping matches, with
+// bruteforce filling the gaps, that needs the fewest guesses in total.
+func (se *StrengthEstimator) mostGuessableMatches(password string, dictionaries map[string]map[string]int) (float64, []PasswordMatch) {
+	runes := []rune(password)
+	n := len(runes)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matches := se.findMatches(runes, dictionaries)
+	byEnd := make([][]PasswordMatch, n+1)
+	for _, m := range matches {
+		if m.Start == 0 && m.End == n {
+			m.Guesses = math.Max(m.Guesses, 1)
+		} else {
+			m.Guesses = math.Max(m.Guesses, minSubmatchGuesses)
+		}
+		byEnd[m.End] = append(byEnd[m.End], m)
+	}
+
+	cardinality := float64(bruteforceCardinality(runes))
+	best := make([]float64, n+1)
+	last := make([]PasswordMatch, n+1)
+	best[0] = 1
+	for end := 1; end <= n; end++ {
+		// Runes not covered by a pattern are extended into a single
+		// bruteforce match.
+		start := end - 1
+		if last[end-1].Pattern == PatternBruteforce {
+			start = last[end-1].Start
+		}
+		bruteforce := PasswordMatch{
+			Pattern: PatternBruteforce,
+			Token:   string(runes[start:end]),
+			Start:   start,
+			End:     end,
+			Guesses: math.Pow(cardinality, float64(end-start)),
+		}
+		best[end] = best[start] * bruteforce.Guesses
+		last[end] = bruteforce
+
+		for _, m := range byEnd[end] {
+			if g := best[m.Start] * m.Guesses; g < best[end] {
+				best[end] = g
+				last[end] = m
+			}
+		}
+	}
+
+	var sequence []PasswordMatch
+	for end := n; end > 0; end = last[end].Start {
+		sequence = append(sequence, last[end])
+	}
+	slices.Reverse(sequence)
+	return best[n], sequence
+}
+
+func bruteforceCardinality(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	cardinality := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			cardinality += class.size
+		}
+	}
+	return max(cardinality, 10)
+}
+
+func (se *StrengthEstimator) findMatches(runes []rune, dictionaries map[string]map[string]int) []PasswordMatch {
+	var matches []PasswordMatch
+	matches = append(matches, dictionaryMatches(runes, dictionaries)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, se.repeatMatches(runes, dictionaries)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, se.dateMatches(runes)...)
+	return matches
+}
+
+// Dictionary matching.
+
+// maxWordLength bounds the substrings looked up in the dictionaries.
+const maxWordLength = 24
+
+// l33tTables undo common character substitutions. Characters that stand for
+// more than one letter get a table each.
+var l33tTables = []map[rune]rune{
+	{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '9': 'g', '1': 'i', '!': 'i', '|': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z', '%': 'x'},
+	{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '9': 'g', '1': 'l', '!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z', '%': 'x'},
+}
+
+func dictionaryMatches(runes []rune, dictionaries map[string]map[string]int) []PasswordMatch {
+	lower := []rune(strings.ToLower(string(runes)))
+	n := len(runes)
+
+	var matches []PasswordMatch
+	lookup := func(candidate []rune, start, end int, reversed, l33t bool) {
+		word := string(candidate)
+		for name, ranked := range dictionaries {
+			rank, ok := ranked[word]
+			if !ok {
+				continue
+			}
+			token := runes[start:end]
+			guesses := float64(rank) * uppercaseVariations(token)
+			if l33t {
+				guesses *= l33tVariations(token, candidate)
+			}
+			if reversed {
+				guesses *= 2
+			}
+			matches = append(matches, PasswordMatch{
+				Pattern:    PatternDictionary,
+				Token:      string(token),
+				Start:      start,
+				End:        end,
+				Guesses:    guesses,
+				Dictionary: name,
+				Rank:       rank,
+				Reversed:   reversed,
+				L33t:       l33t,
+			})
+		}
+	}
+
+	for start := 0; start < n; start++ {
+		for end := start + 3; end <= n && end-start <= maxWordLength; end++ {
+			token := lower[start:end]
+			lookup(token, start, end, false, false)
+
+			reversed := slices.Clone(token)
+			slices.Reverse(reversed)
+			if string(reversed) != string(token) {
+				lookup(reversed, start, end, true, false)
+			}
+
+			seen := map[string]bool{string(token): true}
+			for _, table := range l33tTables {
+				translated := make([]rune, len(token))
+				for i, r := range token {
+					if sub, ok := table[r]; ok {
+						r = sub
+					}
+					translated[i] = r
+				}
+				if !seen[string(translated)] {
+					seen[string(translated)] = true
+					lookup(translated, start, end, false, true)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// uppercaseVariations counts the ways the capitalization of token could
+// have been chosen. Capitalizing the first or last letter, or all of them,
+// only doubles the guesses.
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1]))) {
+		return 2
+	}
+	return choices(upper+lower, min(upper, lower))
+}
+
+// l33tVariations counts the ways the substituted characters could have
+// been chosen.
+func l33tVariations(token, translated []rune) float64 {
+	subs := 0
+	for i, r := range token {
+		if unicode.ToLower(r) != translated[i] {
+			subs++
+		}
+	}
+	return math.Max(2, choices(len(token), subs))
+}
+
+// choices returns the number of ways to pick up to k of n items.
+func choices(n, k int) float64 {
+	total := 0.0
+	for i := 1; i <= k; i++ {
+		c := 1.0
+		for j := 0; j < i; j++ {
+			c = c * float64(n-j) / float64(j+1)
+		}
+		total += c
+	}
+	return math.Max(total, 1)
+}
+
+// Keyboard matching.
+
+var keyboardRows = []struct{ plain, shifted string }{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{"asdfghjkl;'", "ASDFGHJKL:\""},
+	{"zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+type keyPosition struct {
+	row, col int
+	shifted  bool
+}
+
+var keyPositions = func() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for row, keys := range keyboardRows {
+		for col, r := range keys.plain {
+			positions[r] = keyPosition{row: row, col: col}
+		}
+		for col, r := range keys.shifted {
+			positions[r] = keyPosition{row: row, col: col, shifted: true}
+		}
+	}
+	return positions
+}()
+
+const (
+	keyboardStartingKeys  = 47
+	keyboardAverageDegree = 4.6
+	minKeyboardWalk       = 4
+)
+
+// keyDirection returns the direction from key a to an adjacent key b, or
+// -1 if they are not adjacent. Each row is shifted half a key to the right
+// of the row above it.
+func keyDirection(a, b keyPosition) int {
+	dr, dc := b.row-a.row, b.col-a.col
+	switch {
+	case dr == 0 && dc == -1:
+		return 0
+	case dr == 0 && dc == 1:
+		return 1
+	case dr == -1 && dc == 0:
+		return 2
+	case dr == -1 && dc == 1:
+		return 3
+	case dr == 1 && dc == -1:
+		return 4
+	case dr == 1 && dc == 0:
+		return 5
+	}
+	return -1
+}
+
+func keyboardMatches(runes []rune) []PasswordMatch {
+	var matches []PasswordMatch
+	n := len(runes)
+	for start := 0; start < n; {
+		end, turns, lastDirection := start+1, 0, -1
+		for end < n {
+			a, okA := keyPositions[runes[end-1]]
+			b, okB := keyPositions[runes[end]]
+			if !okA || !okB {
+				break
+			}
+			direction := keyDirection(a, b)
+			if direction < 0 {
+				break
+			}
+			if direction != lastDirection {
+				turns++
+				lastDirection = direction
+			}
+			end++
+		}
+
+		if end-start >= minKeyboardWalk {
+			token := runes[start:end]
+			matches = append(matches, PasswordMatch{
+				Pattern: PatternKeyboard,
+				Token:   string(token),
+				Start:   start,
+				End:     end,
+				Guesses: keyboardGuesses(token, turns),
+				Turns:   turns,
+			})
+			start = end
+			continue
+		}
+		start++
+	}
+	return matches
+}
+
+func keyboardGuesses(token []rune, turns int) float64 {
+	length := len(token)
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * keyboardStartingKeys * math.Pow(keyboardAverageDegree, float64(j))
+		}
+	}
+
+	shifted := 0
+	for _, r := range token {
+		if keyPositions[r].shifted {
+			shifted++
+		}
+	}
+	if shifted > 0 {
+		if shifted == length {
+			guesses *= 2
+		} else {
+			guesses *= choices(length, min(shifted, length-shifted))
+		}
+	}
+	return guesses
+}
+
+func binomial(n, k int) float64 {
+	c := 1.0
+	for j := 0; j < k; j++ {
+		c = c * float64(n-j) / float64(j+1)
+	}
+	return c
+}
+
+// Repeat matching.
+
+func (se *StrengthEstimator) repeatMatches(runes []rune, dictionaries map[string]map[string]int) []PasswordMatch {
+	var matches []PasswordMatch
+	n := len(runes)
+	for start := 0; start < n; {
+		bestEnd, bestUnit := start, 0
+		for unit := 1; start+2*unit <= n; unit++ {
+			count := 1
+			for end := start + (count+1)*unit; end <= n && slices.Equal(runes[start:start+unit], runes[end-unit:end]); end += unit {
+				count++
+			}
+			// A single repeated rune only counts from three on.
+			if count >= 2 && (unit > 1 || count >= 3) && start+count*unit > bestEnd {
+				bestEnd, bestUnit = start+count*unit, unit
+			}
+		}
+
+		if bestUnit == 0 {
+			start++
+			continue
+		}
+
+		base := runes[start : start+bestUnit]
+		baseGuesses, _ := se.mostGuessableMatches(string(base), dictionaries)
+		matches = append(matches, PasswordMatch{
+			Pattern:   PatternRepeat,
+			Token:     string(runes[start:bestEnd]),
+			Start:     start,
+			End:       bestEnd,
+			Guesses:   baseGuesses * float64((bestEnd-start)/bestUnit),
+			BaseToken: string(base),
+		})
+		start = bestEnd
+	}
+	return matches
+}
+
+// Sequence matching.
+
+const maxSequenceDelta = 5
+
+func sequenceMatches(runes []rune) []PasswordMatch {
+	var matches []PasswordMatch
+	n := len(runes)
+	for start := 0; start+2 < n; {
+		delta := runes[start+1] - runes[start]
+		end := start + 1
+		if delta != 0 && abs(delta) <= maxSequenceDelta && sameClass(runes[start], runes[start+1]) {
+			for end < n && runes[end]-runes[end-1] == delta && sameClass(runes[end-1], runes[end]) {
+				end++
+			}
+		}
+
+		if end-start >= 3 {
+			token := runes[start:end]
+			matches = append(matches, PasswordMatch{
+				Pattern: PatternSequence,
+				Token:   string(token),
+				Start:   start,
+				End:     end,
+				Guesses: sequenceGuesses(token, delta),
+			})
+			start = end - 1
+			continue
+		}
+		start++
+	}
+	return matches
+}
+
+func sameClass(a, b rune) bool {
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLower(r):
+			return 1
+		case unicode.IsUpper(r):
+			return 2
+		case unicode.IsDigit(r):
+			return 3
+		}
+		return 0
+	}
+	return class(a) != 0 && class(a) == class(b)
+}
+
+func sequenceGuesses(token []rune, delta rune) float64 {
+	first := token[0]
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return base * float64(len(token))
+}
+
+func abs(r rune) rune {
+	if r < 0 {
+		return -r
+	}
+	return r
+}
+
+// Date matching.
+
+const (
+	minYearSpace   = 20
+	dateSeparators = " /\\_.-"
+)
+
+func (se *StrengthEstimator) yearGuesses(year int) float64 {
+	return math.Max(math.Abs(float64(year-se.now().Year())), minYearSpace)
+}
+
+func (se *StrengthEstimator) dateMatches(runes []rune) []PasswordMatch {
+	var matches []PasswordMatch
+	n := len(runes)
+	for start := 0; start < n; start++ {
+		for end := start + 4; end <= n && end-start <= 10; end++ {
+			token := string(runes[start:end])
+			match := PasswordMatch{
+				Pattern: PatternDate,
+				Token:   token,
+				Start:   start,
+				End:     end,
+			}
+
+			if year, ok := parseYear(token); ok {
+				match.Year = year
+				match.Guesses = se.yearGuesses(year)
+			} else if year, separated, ok := parseDate(token); ok {
+				match.Year = year
+				match.Guesses = se.yearGuesses(year) * 365
+				if separated {
+					match.Guesses *= 4
+				}
+			} else {
+				continue
+			}
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// parseDate recognizes a day, month and year in any common order, with or
+// without separators. It returns the year and whether the parts were
+// separated.
+func parseDate(token string) (int, bool, bool) {
+	if isDigits(token) {
+		if len(token) > 8 {
+			return 0, false, false
+		}
+		// Try every split into three non-empty parts.
+		for i := 1; i < len(token)-1; i++ {
+			for j := i + 1; j < len(token); j++ {
+				if year, ok := dateFromParts(token[:i], token[i:j], token[j:]); ok {
+					return year, false, true
+				}
+			}
+		}
+		return 0, false, false
+	}
+
+	sep := strings.IndexAny(token, dateSeparators)
+	if sep <= 0 {
+		return 0, false, false
+	}
+	parts := strings.Split(token, token[sep:sep+1])
+	if len(parts) != 3 {
+		return 0, false, false
+	}
+	for _, part := range parts {
+		if part == "" || !isDigits(part) {
+			return 0, false, false
+		}
+	}
+	year, ok := dateFromParts(parts[0], parts[1], parts[2])
+	return year, true, ok
+}
+
+// dateFromParts accepts year-month-day, day-month-year and month-day-year.
+func dateFromParts(a, b, c string) (int, bool) {
+	orders := [][3]string{{a, b, c}, {c, b, a}, {c, a, b}} // year, month, day
+	for _, order := range orders {
+		year, ok := parseDateYear(order[0])
+		if !ok {
+			continue
+		}
+		month, day := atoiLen(order[1], 2), atoiLen(order[2], 2)
+		if month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			return year, true
+		}
+	}
+	return 0, false
+}
+
+// parseYear accepts a four digit year from 1900 to 2099.
+func parseYear(s string) (int, bool) {
+	if len(s) != 4 || !isDigits(s) {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(s)
+	return year, year >= 1900 && year <= 2099
+}
+
+// parseDateYear also accepts two digit years, which are placed in the
+// closest century.
+func parseDateYear(s string) (int, bool) {
+	switch len(s) {
+	case 2:
+		year, _ := strconv.Atoi(s)
+		if year > 50 {
+			return 1900 + year, true
+		}
+		return 2000 + year, true
+	case 4:
+		return parseYear(s)
+	}
+	return 0, false
+}
+
+// atoiLen parses s if it has at most maxLen digits and returns -1 otherwise.
+func atoiLen(s string, maxLen int) int {
+	if len(s) == 0 || len(s) > maxLen {
+		return -1
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// Feedback.
+
+func feedback(s Strength) (string, []string) {
+	if len(s.Matches) == 0 {
+		return "", []string{"Use a few words, avoid common phrases"}
+	}
+	if s.Score > 2 {
+		return "", nil
+	}
+
+	// The longest match explains the most about the score.
+	longest := s.Matches[0]
+	for _, m := range s.Matches[1:] {
+		if m.End-m.Start > longest.End-longest.Start {
+			longest = m
+		}
+	}
+
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	warning := ""
+	switch longest.Pattern {
+	case PatternDictionary:
+		wholePassword := len(s.Matches) == 1
+		switch {
+		case longest.Dictionary == DictionaryPasswords && longest.Rank <= 10 && !longest.Reversed && !longest.L33t:
+			warning = "This is a top-10 common password"
+		case longest.Dictionary == DictionaryPasswords && longest.Rank <= 100 && !longest.Reversed && !longest.L33t:
+			warning = "This is a top-100 common password"
+		case longest.Dictionary == DictionaryPasswords:
+			warning = "This is similar to a commonly used password"
+		case longest.Dictionary == DictionaryUserInputs:
+			warning = "Passwords based on the entry's title, username or URL are easy to guess"
+		case wholePassword:
+			warning = "A word by itself is easy to guess"
+		}
+		token := []rune(longest.Token)
+		if unicode.IsUpper(token[0]) {
+			suggestions = append(suggestions, "Capitalization doesn't help very much")
+		}
+		if longest.Reversed {
+			suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+		}
+		if longest.L33t {
+			suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+		}
+	case PatternKeyboard:
+		if longest.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		} else {
+			warning = "Short keyboard patterns are easy to guess"
+		}
+		suggestions = append(suggestions, "Use a longer keyboard pattern with more turns")
+	case PatternRepeat:
+		if len([]rune(longest.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess`
+		} else {
+			warning = `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+		}
+		suggestions = append(suggestions, "Avoid repeated words and characters")
+	case PatternSequence:
+		warning = "Sequences like abc or 6543 are easy to guess"
+		suggestions = append(suggestions, "Avoid sequences")
+	case PatternDate:
+		if len(longest.Token) == 4 && isDigits(longest.Token) {
+			warning = "Recent years are easy to guess"
+			suggestions = append(suggestions, "Avoid recent years and years that are associated with you")
+		} else {
+			warning = "Dates are often easy to guess"
+			suggestions = append(suggestions, "Avoid dates and years that are associated with you")
+		}
+	}
+	return warning, suggestions
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStrengthEstimator() *StrengthEstimator {
+	se := NewStrengthEstimator()
+	se.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return se
+}
+
+func TestStrengthEstimator_Estimate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		maxScore   int
+		minScore   int
+		pattern    PatternKind
+		warning    string
+	}{
+		{
+			name:     "succeed: top common password",
+			password: "password",
+			maxScore: 0,
+			pattern:  PatternDictionary,
+			warning:  "This is a top-10 common password",
+		},
+		{
+			name:     "succeed: common password with digits",
+			password: "password123",
+			maxScore: 1,
+			pattern:  PatternDictionary,
+		},
+		{
+			name:     "succeed: l33t substitution",
+			password: "p@ssw0rd",
+			maxScore: 1,
+			pattern:  PatternDictionary,
+		},
+		{
+			name:     "succeed: reversed word",
+			password: "nogardrats",
+			maxScore: 2,
+			pattern:  PatternDictionary,
+		},
+		{
+			name:     "succeed: keyboard walk",
+			password: "zxcvfdsa",
+			maxScore: 1,
+			pattern:  PatternKeyboard,
+		},
+		{
+			name:     "succeed: repeat",
+			password: "aaaaaaaaaa",
+			maxScore: 0,
+			pattern:  PatternRepeat,
+			warning:  `Repeats like "aaa" are easy to guess`,
+		},
+		{
+			name:     "succeed: sequence",
+			password: "lmnopqrs",
+			maxScore: 0,
+			pattern:  PatternSequence,
+			warning:  "Sequences like abc or 6543 are easy to guess",
+		},
+		{
+			name:     "succeed: date",
+			password: "13.04.1987",
+			maxScore: 1,
+			pattern:  PatternDate,
+			warning:  "Dates are often easy to guess",
+		},
+		{
+			name:     "succeed: recent year",
+			password: "2019",
+			maxScore: 0,
+			pattern:  PatternDate,
+			warning:  "Recent years are easy to guess",
+		},
+		{
+			name:       "succeed: based on user input",
+			password:   "Acmecorp!",
+			userInputs: []string{"acmecorp.example"},
+			maxScore:   1,
+			pattern:    PatternDictionary,
+			warning:    "Passwords based on the entry's title, username or URL are easy to guess",
+		},
+		{
+			name:     "succeed: random password",
+			password: "vK7#qT2mXp9!Lw4z",
+			minScore: 4,
+			maxScore: 4,
+			pattern:  PatternBruteforce,
+		},
+		{
+			name:     "succeed: several uncommon words",
+			password: "glacier velvet orbit tundra",
+			minScore: 4,
+			maxScore: 4,
+		},
+	}
+
+	se := newTestStrengthEstimator()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got := se.Estimate(test.password, test.userInputs...)
+			assert.GreaterOrEqual(t, got.Score, test.minScore)
+			assert.LessOrEqual(t, got.Score, test.maxScore)
+			if test.pattern != "" {
+				var patterns []PatternKind
+				for _, m := range got.Matches {
+					patterns = append(patterns, m.Pattern)
+				}
+				assert.Contains(t, patterns, test.pattern)
+			}
+			if test.warning != "" {
+				assert.Equal(t, test.warning, got.Warning)
+			}
+		})
+	}
+}
+
+func TestStrengthEstimator_EstimateMatchesCoverPassword(t *testing.T) {
+	t.Parallel()
+	se := newTestStrengthEstimator()
+
+	got := se.Estimate("Tr0ub4dor&3")
+	end := 0
+	for _, m := range got.Matches {
+		assert.Equal(t, end, m.Start)
+		end = m.End
+	}
+	assert.Equal(t, len([]rune("Tr0ub4dor&3")), end)
+	assert.InDelta(t, got.Guesses/GuessesPerSecond, got.CrackTimeSeconds, 1e-9)
+}
+
+func TestStrength_CrackTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		seconds float64
+		want    string
+	}{
+		{
+			name:    "succeed: instant",
+			seconds: 0.2,
+			want:    "less than a second",
+		},
+		{
+			name:    "succeed: singular",
+			seconds: 60,
+			want:    "1 minute",
+		},
+		{
+			name:    "succeed: hours",
+			seconds: 3 * 3600,
+			want:    "3 hours",
+		},
+		{
+			name:    "succeed: centuries",
+			seconds: 1e12,
+			want:    "centuries",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, Strength{CrackTimeSeconds: test.seconds}.CrackTime())
+		})
+	}
+}
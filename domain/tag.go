@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid tag")
+	ErrTagNotFound = errors.New("tag not found")
+)
+
+// TagMatch selects how a list of tags filters entries.
+type TagMatch int
+
+const (
+	// TagMatchAll keeps entries that carry every tag.
+	TagMatchAll TagMatch = iota
+	// TagMatchAny keeps entries that carry at least one of the tags.
+	TagMatchAny
+)
+
+func (m TagMatch) String() string {
+	if m == TagMatchAny {
+		return "OR"
+	}
+	return "AND"
+}
+
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// NormalizeTag lower-cases a tag and replaces inner whitespace with dashes,
+// so that "Work Stuff" and "work-stuff" are the same tag.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+	if tag == "" {
+		return "", fmt.Errorf("%w: tag is empty", ErrInvalidTag)
+	}
+	if strings.Contains(tag, ",") {
+		return "", fmt.Errorf("%w: %q contains a comma", ErrInvalidTag, tag)
+	}
+	return tag, nil
+}
+
+// ParseTags splits a comma separated list of tags.
+func ParseTags(s string) ([]string, error) {
+	var tags []string
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		tag, err := NormalizeTag(part)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		tag    string
+		want   string
+		hasErr bool
+	}{
+		{name: "succeed: lower case", tag: "Work", want: "work"},
+		{name: "succeed: inner whitespace", tag: "  Work   Stuff ", want: "work-stuff"},
+		{name: "failed: empty", tag: "   ", hasErr: true},
+		{name: "failed: comma", tag: "a,b", hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NormalizeTag(test.tag)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidTag)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "succeed: comma separated", input: "work, Home ,,finance", want: []string{"work", "home", "finance"}},
+		{name: "succeed: empty", input: " ", want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseTags(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rfc6238Secret(seed string, size int) string {
+	key := make([]byte, 0, size)
+	for len(key) < size {
+		key = append(key, seed...)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key[:size])
+}
+
+func TestParseTOTP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   *TOTP
+		hasErr bool
+	}{
+		{
+			name:  "succeed: raw base32 secret",
+			input: "jbsw y3dp-ehpk 3pxp",
+			want: &TOTP{
+				Secret:    "JBSWY3DPEHPK3PXP",
+				Digits:    6,
+				Period:    30,
+				Algorithm: TOTPAlgorithmSHA1,
+			},
+		},
+		{
+			name:  "succeed: otpauth uri",
+			input: "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA256&digits=8&period=60",
+			want: &TOTP{
+				Secret:    "JBSWY3DPEHPK3PXP",
+				Digits:    8,
+				Period:    60,
+				Algorithm: TOTPAlgorithmSHA256,
+				Issuer:    "Example",
+				Account:   "alice@example.com",
+			},
+		},
+		{
+			name:  "succeed: otpauth uri with defaults",
+			input: "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP",
+			want: &TOTP{
+				Secret:    "JBSWY3DPEHPK3PXP",
+				Digits:    6,
+				Period:    30,
+				Algorithm: TOTPAlgorithmSHA1,
+				Account:   "alice",
+			},
+		},
+		{
+			name:   "failed: not base32",
+			input:  "not a secret!",
+			hasErr: true,
+		},
+		{
+			name:   "failed: empty secret",
+			input:  "",
+			hasErr: true,
+		},
+		{
+			name:   "failed: hotp uri",
+			input:  "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=1",
+			hasErr: true,
+		},
+		{
+			name:   "failed: unsupported algorithm",
+			input:  "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+			hasErr: true,
+		},
+		{
+			name:   "failed: invalid digits",
+			input:  "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=4",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			totp, err := ParseTOTP(test.input)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrInvalidTOTP)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, totp)
+			}
+		})
+	}
+}
+
+func TestTOTP_String(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "succeed: raw secret round trip",
+			input: "JBSWY3DPEHPK3PXP",
+		},
+		{
+			name:  "succeed: uri round trip",
+			input: "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA512&digits=8&period=60",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			totp, err := ParseTOTP(test.input)
+			assert.NoError(t, err)
+
+			reparsed, err := ParseTOTP(totp.String())
+			assert.NoError(t, err)
+			assert.Equal(t, totp, reparsed)
+		})
+	}
+}
+
+func TestTOTPGenerator_Generate(t *testing.T) {
+	t.Parallel()
+	// Test vectors from RFC 6238 appendix B.
+	sha1Secret := rfc6238Secret("12345678901234567890", 20)
+	sha256Secret := rfc6238Secret("12345678901234567890", 32)
+	sha512Secret := rfc6238Secret("12345678901234567890", 64)
+
+	tests := []struct {
+		name      string
+		secret    string
+		algorithm string
+		unix      int64
+		want      string
+	}{
+		{name: "succeed: SHA1 at 59", secret: sha1Secret, algorithm: TOTPAlgorithmSHA1, unix: 59, want: "94287082"},
+		{name: "succeed: SHA256 at 59", secret: sha256Secret, algorithm: TOTPAlgorithmSHA256, unix: 59, want: "46119246"},
+		{name: "succeed: SHA512 at 59", secret: sha512Secret, algorithm: TOTPAlgorithmSHA512, unix: 59, want: "90693936"},
+		{name: "succeed: SHA1 at 1111111109", secret: sha1Secret, algorithm: TOTPAlgorithmSHA1, unix: 1111111109, want: "07081804"},
+		{name: "succeed: SHA256 at 1234567890", secret: sha256Secret, algorithm: TOTPAlgorithmSHA256, unix: 1234567890, want: "91819424"},
+		{name: "succeed: SHA512 at 20000000000", secret: sha512Secret, algorithm: TOTPAlgorithmSHA512, unix: 20000000000, want: "47863826"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			totp := &TOTP{Secret: test.secret, Digits: 8, Period: 30, Algorithm: test.algorithm}
+			code, err := NewTOTPGenerator().Generate(totp, time.Unix(test.unix, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, code)
+		})
+	}
+}
+
+func TestTOTPGenerator_Remaining(t *testing.T) {
+	t.Parallel()
+	totp := &TOTP{Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 30, Algorithm: TOTPAlgorithmSHA1}
+	g := NewTOTPGenerator()
+
+	assert.Equal(t, 30*time.Second, g.Remaining(totp, time.Unix(60, 0)))
+	assert.Equal(t, 1*time.Second, g.Remaining(totp, time.Unix(89, 0)))
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// TrashedEntry is an entry that was deleted but can still be restored until
+// it is purged.
+type TrashedEntry struct {
+	Entry     *Entry    `json:"entry"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashEntry moves an entry to the trash.
+func (v *Vault) TrashEntry(id string) error {
+	entry, err := v.GetEntry(id)
+	if err != nil {
+		return err
+	}
+
+	v.trash(entry, time.Now())
+	return nil
+}
+
+func (v *Vault) trash(entry *Entry, now time.Time) {
+	if v.Trash == nil {
+		v.Trash = make(map[string]*TrashedEntry)
+	}
+	delete(v.Entries, entry.ID)
+	v.Trash[entry.ID] = &TrashedEntry{Entry: entry, DeletedAt: now}
+	v.UpdatedAt = now
+}
+
+// ListTrash returns the trashed entries, most recently deleted first.
+func (v *Vault) ListTrash() []*TrashedEntry {
+	trashed := make([]*TrashedEntry, 0, len(v.Trash))
+	for _, t := range v.Trash {
+		trashed = append(trashed, t)
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(trashed[j].DeletedAt)
+	})
+	return trashed
+}
+
+// RestoreEntry moves an entry from the trash back to the vault. If its
+// folder was deleted in the meantime, the entry is restored to the top
+// level.
+func (v *Vault) RestoreEntry(id string) (*Entry, error) {
+	trashed, exists := v.Trash[id]
+	if !exists {
+		return nil, ErrEntryNotFound
+	}
+	if _, exists := v.Entries[id]; exists {
+		return nil, ErrEntryExists
+	}
+
+	entry := trashed.Entry
+	if _, err := v.GetFolder(entry.FolderID); entry.FolderID != "" && err != nil {
+		entry.FolderID = ""
+	}
+
+	delete(v.Trash, id)
+	v.Entries[id] = entry
+	v.UpdatedAt = time.Now()
+	return entry, nil
+}
+
+// PurgeEntry deletes a trashed entry for good.
+func (v *Vault) PurgeEntry(id string) error {
+	if _, exists := v.Trash[id]; !exists {
+		return ErrEntryNotFound
+	}
+	delete(v.Trash, id)
+	v.UpdatedAt = time.Now()
+	return nil
+}
+
+// PurgeTrash deletes the entries trashed at or before the given time and
+// returns how many were deleted.
+func (v *Vault) PurgeTrash(before time.Time) int {
+	purged := 0
+	for id, trashed := range v.Trash {
+		if !trashed.DeletedAt.After(before) {
+			delete(v.Trash, id)
+			purged++
+		}
+	}
+	if purged > 0 {
+		v.UpdatedAt = time.Now()
+	}
+	return purged
+}
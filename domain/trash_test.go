@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVault_TrashEntry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		id     string
+		hasErr bool
+	}{
+		{
+			name:   "succeed: move entry to trash",
+			id:     "test-id-1",
+			hasErr: false,
+		},
+		{
+			name:   "failed: non-existent entry",
+			id:     "missing",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := NewVault()
+			vault.Entries["test-id-1"] = &Entry{ID: "test-id-1"}
+			err := vault.TrashEntry(test.id)
+			if test.hasErr {
+				assert.Error(t, err)
+				assert.Empty(t, vault.Trash)
+			} else {
+				assert.NoError(t, err)
+				assert.NotContains(t, vault.Entries, test.id)
+				assert.Contains(t, vault.Trash, test.id)
+				assert.False(t, vault.Trash[test.id].DeletedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestVault_ListTrash(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	vault := NewVault()
+	vault.Trash["old"] = &TrashedEntry{Entry: &Entry{ID: "old"}, DeletedAt: now.Add(-2 * time.Hour)}
+	vault.Trash["new"] = &TrashedEntry{Entry: &Entry{ID: "new"}, DeletedAt: now}
+	vault.Trash["mid"] = &TrashedEntry{Entry: &Entry{ID: "mid"}, DeletedAt: now.Add(-time.Hour)}
+
+	var ids []string
+	for _, trashed := range vault.ListTrash() {
+		ids = append(ids, trashed.Entry.ID)
+	}
+	assert.Equal(t, []string{"new", "mid", "old"}, ids)
+}
+
+func TestVault_RestoreEntry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		setup        func(*Vault)
+		id           string
+		wantFolderID string
+		hasErr       bool
+	}{
+		{
+			name:         "succeed: restore into its folder",
+			setup:        func(v *Vault) {},
+			id:           "in-folder",
+			wantFolderID: "work",
+			hasErr:       false,
+		},
+		{
+			name:         "succeed: folder was deleted",
+			setup:        func(v *Vault) { delete(v.Folders, "work") },
+			id:           "in-folder",
+			wantFolderID: "",
+			hasErr:       false,
+		},
+		{
+			name:   "failed: not in trash",
+			setup:  func(v *Vault) {},
+			id:     "missing",
+			hasErr: true,
+		},
+		{
+			name:   "failed: entry with the same ID exists",
+			setup:  func(v *Vault) { v.Entries["in-folder"] = &Entry{ID: "in-folder"} },
+			id:     "in-folder",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := NewVault()
+			vault.Folders["work"] = &Folder{ID: "work", Name: "Work"}
+			vault.Trash["in-folder"] = &TrashedEntry{
+				Entry:     &Entry{ID: "in-folder", FolderID: "work"},
+				DeletedAt: time.Now(),
+			}
+			test.setup(vault)
+			entry, err := vault.RestoreEntry(test.id)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantFolderID, entry.FolderID)
+				assert.Contains(t, vault.Entries, test.id)
+				assert.NotContains(t, vault.Trash, test.id)
+			}
+		})
+	}
+}
+
+func TestVault_PurgeEntry(t *testing.T) {
+	t.Parallel()
+	vault := NewVault()
+	vault.Trash["test-id-1"] = &TrashedEntry{Entry: &Entry{ID: "test-id-1"}, DeletedAt: time.Now()}
+
+	assert.NoError(t, vault.PurgeEntry("test-id-1"))
+	assert.Empty(t, vault.Trash)
+	assert.ErrorIs(t, vault.PurgeEntry("test-id-1"), ErrEntryNotFound)
+}
+
+func TestVault_PurgeTrash(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	vault := NewVault()
+	vault.Trash["old"] = &TrashedEntry{Entry: &Entry{ID: "old"}, DeletedAt: now.Add(-48 * time.Hour)}
+	vault.Trash["edge"] = &TrashedEntry{Entry: &Entry{ID: "edge"}, DeletedAt: now.Add(-24 * time.Hour)}
+	vault.Trash["new"] = &TrashedEntry{Entry: &Entry{ID: "new"}, DeletedAt: now}
+
+	assert.Equal(t, 2, vault.PurgeTrash(now.Add(-24*time.Hour)))
+	assert.Len(t, vault.Trash, 1)
+	assert.Contains(t, vault.Trash, "new")
+	assert.Equal(t, 0, vault.PurgeTrash(now.Add(-24*time.Hour)))
+}
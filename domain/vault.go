@@ -2,26 +2,41 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
-const CurrentVaultVersion = "1.0"
+// CurrentVaultVersion is the schema version written by this build. Older
+// vaults are upgraded by the migrations registered in the storage package.
+const CurrentVaultVersion = "1.8"
 
 var (
-	ErrEntryNotFound = errors.New("entry not found")
-	ErrEntryExists   = errors.New("entry already exists")
+	ErrEntryNotFound  = errors.New("entry not found")
+	ErrEntryExists    = errors.New("entry already exists")
+	ErrAmbiguousEntry = errors.New("more than one entry matches")
+	ErrVaultConflict  = errors.New("vault was modified by another process")
 )
 
 type Vault struct {
-	Version   string            `json:"version"`
-	Entries   map[string]*Entry `json:"entries"`
-	UpdatedAt time.Time         `json:"updated_at"`
+	Version string `json:"version"`
+	// Revision is incremented on every save and lets the repository detect
+	// writes made by another process since the vault was loaded.
+	Revision  uint64                   `json:"revision"`
+	Entries   map[string]*Entry        `json:"entries"`
+	Folders   map[string]*Folder       `json:"folders"`
+	Trash     map[string]*TrashedEntry `json:"trash"`
+	UpdatedAt time.Time                `json:"updated_at"`
 }
 
 func NewVault() *Vault {
 	return &Vault{
 		Version:   CurrentVaultVersion,
 		Entries:   make(map[string]*Entry),
+		Folders:   make(map[string]*Folder),
+		Trash:     make(map[string]*TrashedEntry),
 		UpdatedAt: time.Now(),
 	}
 }
@@ -43,6 +58,30 @@ func (v *Vault) GetEntry(id string) (*Entry, error) {
 	return entry, nil
 }
 
+// FindEntry returns the entry with the ID ref or, failing that, the only
+// entry titled ref. Titles are compared case-insensitively.
+func (v *Vault) FindEntry(ref string) (*Entry, error) {
+	if entry, exists := v.Entries[ref]; exists {
+		return entry, nil
+	}
+
+	var matches []string
+	for _, entry := range v.Entries {
+		if strings.EqualFold(entry.Title, ref) {
+			matches = append(matches, entry.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, ref)
+	case 1:
+		return v.Entries[matches[0]], nil
+	default:
+		sort.Strings(matches)
+		return nil, fmt.Errorf("%w: %q matches %s", ErrAmbiguousEntry, ref, strings.Join(matches, ", "))
+	}
+}
+
 func (v *Vault) UpdateEntry(entry Entry) error {
 	if _, exists := v.Entries[entry.ID]; !exists {
 		return ErrEntryNotFound
@@ -82,3 +121,97 @@ func (v *Vault) ListEntries() []*Entry {
 
 	return entries
 }
+
+// Tags returns every tag in the vault with the number of entries carrying
+// it, sorted by name.
+func (v *Vault) Tags() []TagCount {
+	counts := make(map[string]int)
+	for _, entry := range v.Entries {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, TagCount{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags
+}
+
+// EntryFilter narrows the entries returned by FilterEntries. The zero value
+// matches every entry.
+type EntryFilter struct {
+	// FolderID keeps the entries in the folder and its subfolders.
+	FolderID string
+	Tags     []string
+	TagMatch TagMatch
+	// Type keeps the entries of one type. Empty keeps every type.
+	Type EntryType
+}
+
+// FilterEntries returns the entries matching the filter, in the order of
+// ListEntries.
+func (v *Vault) FilterEntries(filter EntryFilter) []*Entry {
+	var folders map[string]bool
+	if filter.FolderID != "" {
+		folders = v.subtree(filter.FolderID)
+	}
+
+	var entries []*Entry
+	for _, entry := range v.ListEntries() {
+		if folders != nil && !folders[entry.FolderID] {
+			continue
+		}
+		if filter.Type != "" && entry.Type != filter.Type {
+			continue
+		}
+		if entry.matchesTags(filter.Tags, filter.TagMatch) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ListEntriesByTags returns the entries matching the tags, in the order of
+// ListEntries.
+func (v *Vault) ListEntriesByTags(tags []string, match TagMatch) []*Entry {
+	return v.FilterEntries(EntryFilter{Tags: tags, TagMatch: match})
+}
+
+// RenameTag renames a tag on every entry. If the new name already exists the
+// two tags are merged. It returns the number of entries changed.
+func (v *Vault) RenameTag(from, to string) (int, error) {
+	from, err := NormalizeTag(from)
+	if err != nil {
+		return 0, err
+	}
+	to, err = NormalizeTag(to)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, entry := range v.Entries {
+		if !entry.HasTag(from) {
+			continue
+		}
+
+		tags := slices.DeleteFunc(slices.Clone(entry.Tags), func(tag string) bool {
+			return tag == from
+		})
+		if err := entry.SetTags(append(tags, to)); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+
+	if changed == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrTagNotFound, from)
+	}
+	v.UpdatedAt = time.Now()
+	return changed, nil
+}
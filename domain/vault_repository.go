@@ -4,4 +4,7 @@ type VaultRepository interface {
 	Load() (*Vault, error)
 	Save(vault *Vault) error
 	Exists() bool
+	// Lock serializes Load-modify-Save cycles across processes. The returned
+	// function releases the lock.
+	Lock() (unlock func(), err error)
 }
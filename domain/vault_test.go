@@ -95,6 +95,54 @@ func TestVault_GetEntry(t *testing.T) {
 	}
 }
 
+func TestVault_FindEntry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		ref     string
+		wantID  string
+		wantErr error
+	}{
+		{
+			name:   "succeed: find by id",
+			ref:    "id-1",
+			wantID: "id-1",
+		},
+		{
+			name:   "succeed: find by title ignoring case",
+			ref:    "github",
+			wantID: "id-1",
+		},
+		{
+			name:    "failed: no match",
+			ref:     "gitlab",
+			wantErr: ErrEntryNotFound,
+		},
+		{
+			name:    "failed: title matches several entries",
+			ref:     "mail",
+			wantErr: ErrAmbiguousEntry,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := NewVault()
+			vault.Entries["id-1"] = &Entry{ID: "id-1", Title: "GitHub"}
+			vault.Entries["id-2"] = &Entry{ID: "id-2", Title: "Mail"}
+			vault.Entries["id-3"] = &Entry{ID: "id-3", Title: "mail"}
+
+			entry, err := vault.FindEntry(test.ref)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantID, entry.ID)
+			}
+		})
+	}
+}
+
 func TestVault_UpdateEntry(t *testing.T) {
 	t.Parallel()
 	newTitle := "new title"
@@ -246,3 +294,143 @@ func TestVault_ListEntries(t *testing.T) {
 		})
 	}
 }
+
+func newTaggedVault() *Vault {
+	vault := NewVault()
+	for id, tags := range map[string][]string{
+		"bank":   {"finance", "personal"},
+		"github": {"work", "dev"},
+		"jira":   {"work"},
+		"email":  {"personal"},
+		"misc":   nil,
+	} {
+		entry := Entry{ID: id, Title: id}
+		entry.SetTags(tags)
+		vault.Entries[id] = &entry
+	}
+	return vault
+}
+
+func TestVault_Tags(t *testing.T) {
+	t.Parallel()
+	vault := newTaggedVault()
+
+	assert.Equal(t, []TagCount{
+		{Name: "dev", Count: 1},
+		{Name: "finance", Count: 1},
+		{Name: "personal", Count: 2},
+		{Name: "work", Count: 2},
+	}, vault.Tags())
+}
+
+func TestVault_ListEntriesByTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		tags  []string
+		match TagMatch
+		want  []string
+	}{
+		{
+			name:  "succeed: no tags lists everything",
+			tags:  nil,
+			match: TagMatchAll,
+			want:  []string{"bank", "email", "github", "jira", "misc"},
+		},
+		{
+			name:  "succeed: all tags",
+			tags:  []string{"work", "dev"},
+			match: TagMatchAll,
+			want:  []string{"github"},
+		},
+		{
+			name:  "succeed: any tag",
+			tags:  []string{"finance", "dev"},
+			match: TagMatchAny,
+			want:  []string{"bank", "github"},
+		},
+		{
+			name:  "succeed: no match",
+			tags:  []string{"finance", "work"},
+			match: TagMatchAll,
+			want:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newTaggedVault()
+
+			var got []string
+			for _, entry := range vault.ListEntriesByTags(test.tags, test.match) {
+				got = append(got, entry.ID)
+			}
+			assert.ElementsMatch(t, test.want, got)
+		})
+	}
+}
+
+func TestVault_RenameTag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		wantChanged int
+		wantTags    map[string][]string
+		wantErr     error
+	}{
+		{
+			name:        "succeed: rename tag",
+			from:        "work",
+			to:          "Job",
+			wantChanged: 2,
+			wantTags: map[string][]string{
+				"github": {"dev", "job"},
+				"jira":   {"job"},
+			},
+		},
+		{
+			name:        "succeed: merge into existing tag",
+			from:        "finance",
+			to:          "personal",
+			wantChanged: 1,
+			wantTags: map[string][]string{
+				"bank":  {"personal"},
+				"email": {"personal"},
+			},
+		},
+		{
+			name:    "failed: unknown tag",
+			from:    "travel",
+			to:      "trips",
+			wantErr: ErrTagNotFound,
+		},
+		{
+			name:    "failed: invalid new name",
+			from:    "work",
+			to:      " ",
+			wantErr: ErrInvalidTag,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := newTaggedVault()
+
+			changed, err := vault.RenameTag(test.from, test.to)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantChanged, changed)
+			for id, tags := range test.wantTags {
+				assert.Equal(t, tags, vault.Entries[id].Tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type AuditVaultUsecase struct {
+	vaultRepo domain.VaultRepository
+	estimator *domain.StrengthEstimator
+}
+
+func NewAuditVaultUsecase(vaultRepo domain.VaultRepository, estimator *domain.StrengthEstimator) *AuditVaultUsecase {
+	return &AuditVaultUsecase{
+		vaultRepo: vaultRepo,
+		estimator: estimator,
+	}
+}
+
+// Execute reports breached, reused, weak and old passwords and duplicate
+// entries.
+func (uc *AuditVaultUsecase) Execute(opts domain.AuditOptions) (*domain.AuditReport, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	report, err := vault.Audit(uc.estimator, opts, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit vault: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditVaultUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		setup     func() *mockVaultRepository
+		wantCount map[domain.AuditIssue]int
+		hasErr    bool
+	}{
+		{
+			name: "succeed: reports findings",
+			setup: func() *mockVaultRepository {
+				now := time.Now()
+				vault := domain.NewVault()
+				vault.Entries["a"] = &domain.Entry{ID: "a", Type: domain.EntryLogin, Title: "A", Password: "password", CreatedAt: now}
+				vault.Entries["b"] = &domain.Entry{ID: "b", Type: domain.EntryLogin, Title: "B", Password: "password", CreatedAt: now.AddDate(-2, 0, 0)}
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			wantCount: map[domain.AuditIssue]int{
+				domain.AuditReused:    2,
+				domain.AuditWeak:      2,
+				domain.AuditOld:       1,
+				domain.AuditDuplicate: 0,
+			},
+			hasErr: false,
+		},
+		{
+			name: "succeed: empty vault",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			wantCount: map[domain.AuditIssue]int{},
+			hasErr:    false,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewAuditVaultUsecase(repo, domain.NewStrengthEstimator())
+			report, err := usecase.Execute(domain.DefaultAuditOptions())
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				for issue, count := range test.wantCount {
+					assert.Equal(t, count, report.Count(issue), issue)
+				}
+			}
+		})
+	}
+}
@@ -16,21 +16,43 @@ func NewCreateEntryUsecase(vaultRepo domain.VaultRepository) *CreateEntryUsecase
 	}
 }
 
-func (uc *CreateEntryUsecase) Execute(title, username, password, url, notes string) error {
+// Execute creates the entry and returns its ID.
+func (uc *CreateEntryUsecase) Execute(input EntryInput) (string, error) {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return "", fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
 	vault, err := uc.vaultRepo.Load()
 	if err != nil {
-		return fmt.Errorf("failed to lead vault: %w", err)
+		return "", fmt.Errorf("failed to lead vault: %w", err)
 	}
 
-	en := domain.NewEntry(title, username, password, url, notes)
+	en := domain.NewEntry(input.Title, input.Username, input.Password, input.URL, input.Notes)
+	if err := en.SetType(input.Type, input.Details); err != nil {
+		return "", fmt.Errorf("failed to set type: %w", err)
+	}
+	if err := en.SetTOTP(input.TOTP); err != nil {
+		return "", fmt.Errorf("failed to set TOTP: %w", err)
+	}
+	if err := en.SetCustomFields(input.CustomFields); err != nil {
+		return "", fmt.Errorf("failed to set custom fields: %w", err)
+	}
+	if err := en.SetTags(input.Tags); err != nil {
+		return "", fmt.Errorf("failed to set tags: %w", err)
+	}
 
 	if err := vault.CreateEntry(*en); err != nil {
-		return fmt.Errorf("failed to create entry: %w", err)
+		return "", fmt.Errorf("failed to create entry: %w", err)
+	}
+	if err := vault.MoveEntry(en.ID, input.FolderID); err != nil {
+		return "", fmt.Errorf("failed to move entry: %w", err)
 	}
 
 	if err := uc.vaultRepo.Save(vault); err != nil {
-		return fmt.Errorf("failed to save vault: %w", err)
+		return "", fmt.Errorf("failed to save vault: %w", err)
 	}
 
-	return nil
+	return en.ID, nil
 }
@@ -11,14 +11,10 @@ import (
 func TestCreateEntryUsecase_Execute(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name     string
-		setup    func() *mockVaultRepository
-		title    string
-		username string
-		password string
-		url      string
-		notes    string
-		hasErr   bool
+		name   string
+		setup  func() *mockVaultRepository
+		input  EntryInput
+		hasErr bool
 	}{
 		{
 			name: "succeed: create new entry",
@@ -32,12 +28,150 @@ func TestCreateEntryUsecase_Execute(t *testing.T) {
 					},
 				}
 			},
-			title:    "test title",
-			username: "test username",
-			password: "test password",
-			url:      "test url",
-			notes:    "test notes",
-			hasErr:   false,
+			input: EntryInput{
+				Title:    "test title",
+				Username: "test username",
+				Password: "test password",
+				URL:      "test url",
+				Notes:    "test notes",
+			},
+			hasErr: false,
+		},
+		{
+			name: "succeed: create entry with TOTP",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					saveFunc: func(vault *domain.Vault) error {
+						for _, entry := range vault.Entries {
+							if entry.TOTP == nil {
+								return errors.New("TOTP not saved")
+							}
+						}
+						return nil
+					},
+				}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Password: "test password",
+				TOTP:     "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			},
+			hasErr: false,
+		},
+		{
+			name: "succeed: create entry in folder",
+			setup: func() *mockVaultRepository {
+				vault := domain.NewVault()
+				vault.CreateFolder(domain.Folder{ID: "folder-1", Name: "Work"})
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						for _, entry := range vault.Entries {
+							if entry.FolderID != "folder-1" {
+								return errors.New("folder not saved")
+							}
+						}
+						return nil
+					},
+				}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Password: "test password",
+				FolderID: "folder-1",
+			},
+			hasErr: false,
+		},
+		{
+			name: "failed: folder not found",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Password: "test password",
+				FolderID: "missing",
+			},
+			hasErr: true,
+		},
+		{
+			name: "succeed: create card entry",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					saveFunc: func(vault *domain.Vault) error {
+						for _, entry := range vault.Entries {
+							if entry.Type != domain.EntryCard || entry.Card == nil {
+								return errors.New("card not saved")
+							}
+						}
+						return nil
+					},
+				}
+			},
+			input: EntryInput{
+				Type:    domain.EntryCard,
+				Details: domain.EntryDetails{Card: &domain.Card{Number: "4111 1111 1111 1111", Expiry: "12/30"}},
+				Title:   "test card",
+			},
+			hasErr: false,
+		},
+		{
+			name: "failed: invalid card",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			input: EntryInput{
+				Type:    domain.EntryCard,
+				Details: domain.EntryDetails{Card: &domain.Card{Number: "1234", Expiry: "12/30"}},
+				Title:   "test card",
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: invalid TOTP",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Password: "test password",
+				TOTP:     "not base32!",
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: invalid custom field",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Password: "test password",
+				CustomFields: []domain.CustomField{
+					{Name: "Expires", Type: domain.FieldDate, Value: "soon"},
+				},
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			input: EntryInput{
+				Title:    "test title",
+				Username: "test username",
+				Password: "test password",
+				URL:      "test url",
+				Notes:    "test notes",
+			},
+			hasErr: true,
 		},
 		{
 			name: "failed: vault load error",
@@ -48,12 +182,14 @@ func TestCreateEntryUsecase_Execute(t *testing.T) {
 					},
 				}
 			},
-			title:    "test title",
-			username: "test username",
-			password: "test password",
-			url:      "test url",
-			notes:    "test notes",
-			hasErr:   true,
+			input: EntryInput{
+				Title:    "test title",
+				Username: "test username",
+				Password: "test password",
+				URL:      "test url",
+				Notes:    "test notes",
+			},
+			hasErr: true,
 		},
 		{
 			name: "failed: vault save error",
@@ -67,12 +203,14 @@ func TestCreateEntryUsecase_Execute(t *testing.T) {
 					},
 				}
 			},
-			title:    "test title",
-			username: "test username",
-			password: "test password",
-			url:      "test url",
-			notes:    "test notes",
-			hasErr:   true,
+			input: EntryInput{
+				Title:    "test title",
+				Username: "test username",
+				Password: "test password",
+				URL:      "test url",
+				Notes:    "test notes",
+			},
+			hasErr: true,
 		},
 	}
 
@@ -81,11 +219,12 @@ func TestCreateEntryUsecase_Execute(t *testing.T) {
 			t.Parallel()
 			repo := test.setup()
 			usecase := NewCreateEntryUsecase(repo)
-			err := usecase.Execute(test.title, test.username, test.password, test.url, test.notes)
+			id, err := usecase.Execute(test.input)
 			if test.hasErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.NotEmpty(t, id)
 			}
 		})
 	}
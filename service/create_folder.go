@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type CreateFolderUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewCreateFolderUsecase(vaultRepo domain.VaultRepository) *CreateFolderUsecase {
+	return &CreateFolderUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute creates a folder under parentID, or at the top level if parentID
+// is empty.
+func (uc *CreateFolderUsecase) Execute(name, parentID string) (*domain.Folder, error) {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	folder := domain.NewFolder(name, parentID)
+	if err := vault.CreateFolder(*folder); err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return nil, fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return folder, nil
+}
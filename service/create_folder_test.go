@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFolderUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+		vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1", FolderID: "work"})
+		return vault
+	}
+
+	tests := []struct {
+		name       string
+		setup      func() *mockVaultRepository
+		folderName string
+		parentID   string
+		hasErr     bool
+	}{
+		{
+			name: "succeed: subfolder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			folderName: "Acme",
+			parentID:   "clients",
+			hasErr:     false,
+		},
+		{
+			name: "succeed: top level folder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			folderName: "Personal",
+			parentID:   "",
+			hasErr:     false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			folderName: "Acme",
+			parentID:   "clients",
+			hasErr:     true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			folderName: "Acme",
+			parentID:   "clients",
+			hasErr:     true,
+		},
+		{
+			name: "failed: duplicate name",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			folderName: "work",
+			parentID:   "",
+			hasErr:     true,
+		},
+		{
+			name: "failed: parent not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			folderName: "Acme",
+			parentID:   "missing",
+			hasErr:     true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			folderName: "Acme",
+			parentID:   "clients",
+			hasErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewCreateFolderUsecase(repo)
+			folder, err := usecase.Execute(test.folderName, test.parentID)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.parentID, folder.ParentID)
+				assert.Equal(t, test.folderName, folder.Name)
+			}
+		})
+	}
+}
@@ -16,13 +16,21 @@ func NewDeleteEntryUsecase(vaultRepo domain.VaultRepository) *DeleteEntryUsecase
 	}
 }
 
+// Execute moves the entry to the trash. It can be restored until it is
+// purged.
 func (uc *DeleteEntryUsecase) Execute(id string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
 	vault, err := uc.vaultRepo.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load vault: %w", err)
 	}
 
-	if err := vault.DeleteEntry(id); err != nil {
+	if err := vault.TrashEntry(id); err != nil {
 		return fmt.Errorf("failed to delete entry: %w", err)
 	}
 
@@ -16,7 +16,7 @@ func TestDeleteEntryUsecase_Execute(t *testing.T) {
 		hasErr bool
 	}{
 		{
-			name: "succeed: delete existing entry",
+			name: "succeed: move existing entry to trash",
 			setup: func() (*mockVaultRepository, string) {
 				vault := domain.NewVault()
 				entry := domain.NewEntry("test title", "test username", "test password", "test url", "test notes")
@@ -26,12 +26,26 @@ func TestDeleteEntryUsecase_Execute(t *testing.T) {
 						return vault, nil
 					},
 					saveFunc: func(vault *domain.Vault) error {
+						if _, ok := vault.Trash[entry.ID]; !ok {
+							return errors.New("entry not in trash")
+						}
 						return nil
 					},
 				}, entry.ID
 			},
 			hasErr: false,
 		},
+		{
+			name: "failed: vault locked",
+			setup: func() (*mockVaultRepository, string) {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}, "test-id"
+			},
+			hasErr: true,
+		},
 		{
 			name: "failed: vault load error",
 			setup: func() (*mockVaultRepository, string) {
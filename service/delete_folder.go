@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type DeleteFolderUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewDeleteFolderUsecase(vaultRepo domain.VaultRepository) *DeleteFolderUsecase {
+	return &DeleteFolderUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute deletes a folder. Unless cascade is set, the folder must be empty;
+// otherwise the error wraps domain.ErrFolderNotEmpty. With cascade, its
+// subfolders are deleted too and its entries are moved to the trash.
+func (uc *DeleteFolderUsecase) Execute(id string, cascade bool) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := vault.DeleteFolder(id, cascade); err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
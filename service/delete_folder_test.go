@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteFolderUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+		vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1", FolderID: "work"})
+		return vault
+	}
+
+	tests := []struct {
+		name    string
+		setup   func() *mockVaultRepository
+		id      string
+		cascade bool
+		hasErr  bool
+	}{
+		{
+			name: "succeed: empty folder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:      "clients",
+			cascade: false,
+			hasErr:  false,
+		},
+		{
+			name: "succeed: cascade",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:      "work",
+			cascade: true,
+			hasErr:  false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			id:      "clients",
+			cascade: false,
+			hasErr:  true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			id:      "clients",
+			cascade: false,
+			hasErr:  true,
+		},
+		{
+			name: "failed: folder not empty",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:      "work",
+			cascade: false,
+			hasErr:  true,
+		},
+		{
+			name: "failed: folder not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:      "missing",
+			cascade: true,
+			hasErr:  true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			id:      "clients",
+			cascade: false,
+			hasErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewDeleteFolderUsecase(repo)
+			err := usecase.Execute(test.id, test.cascade)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import "github.com/ritarock/passvault/domain"
+
+// EntryInput carries the user-editable fields of an entry to the create
+// and update usecases.
+type EntryInput struct {
+	// Type defaults to a login. Details carries the payload of the other
+	// types.
+	Type     domain.EntryType
+	Details  domain.EntryDetails
+	Title    string
+	Username string
+	Password string
+	URL      string
+	Notes    string
+	// TOTP is a base32 secret or an otpauth URI. Empty means no TOTP.
+	TOTP string
+	// CustomFields replaces the entry's custom fields, in order.
+	CustomFields []domain.CustomField
+	Tags         []string
+	// FolderID is the folder the entry is stored in. Empty means the top
+	// level.
+	FolderID string
+}
+
+// NewEntryInput returns the input that leaves the entry unchanged when
+// passed to the update usecase.
+func NewEntryInput(en *domain.Entry) EntryInput {
+	input := EntryInput{
+		Type: en.Type,
+		Details: domain.EntryDetails{
+			Card:     en.Card,
+			Identity: en.Identity,
+			SSHKey:   en.SSHKey,
+		},
+		Title:        en.Title,
+		Username:     en.Username,
+		Password:     en.Password,
+		URL:          en.URL,
+		Notes:        en.Notes,
+		CustomFields: append([]domain.CustomField(nil), en.CustomFields...),
+		Tags:         append([]string(nil), en.Tags...),
+		FolderID:     en.FolderID,
+	}
+	if en.TOTP != nil {
+		input.TOTP = en.TOTP.String()
+	}
+	return input
+}
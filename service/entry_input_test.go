@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntryInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		setup func() *domain.Entry
+	}{
+		{
+			name: "succeed: login with TOTP, fields and tags",
+			setup: func() *domain.Entry {
+				en := domain.NewEntry("title", "username", "password", "url", "notes")
+				assert.NoError(t, en.SetTOTP("JBSWY3DPEHPK3PXP"))
+				assert.NoError(t, en.SetCustomFields([]domain.CustomField{{Name: "pin", Type: domain.FieldHidden, Value: "1234"}}))
+				assert.NoError(t, en.SetTags([]string{"work"}))
+				en.FolderID = "folder-id"
+				return en
+			},
+		},
+		{
+			name: "succeed: card",
+			setup: func() *domain.Entry {
+				en := domain.NewEntry("card", "", "", "", "")
+				assert.NoError(t, en.SetType(domain.EntryCard, domain.EntryDetails{Card: &domain.Card{Number: "4111111111111111", Expiry: "12/30"}}))
+				return en
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			en := test.setup()
+			want := *en
+
+			updated := *en
+			input := NewEntryInput(en)
+			updated.Update(input.Title, input.Username, input.Password, input.URL, input.Notes)
+			assert.NoError(t, updated.SetType(input.Type, input.Details))
+			assert.NoError(t, updated.SetTOTP(input.TOTP))
+			assert.NoError(t, updated.SetCustomFields(input.CustomFields))
+			assert.NoError(t, updated.SetTags(input.Tags))
+
+			updated.UpdatedAt = want.UpdatedAt
+			assert.Equal(t, want, updated)
+			assert.Equal(t, en.FolderID, input.FolderID)
+		})
+	}
+}
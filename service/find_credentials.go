@@ -0,0 +1,27 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type FindCredentialsUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewFindCredentialsUsecase(vaultRepo domain.VaultRepository) *FindCredentialsUsecase {
+	return &FindCredentialsUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute returns the logins matching the credential, best match first.
+func (uc *FindCredentialsUsecase) Execute(credential domain.Credential) ([]*domain.Entry, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	return vault.FindCredentials(credential), nil
+}
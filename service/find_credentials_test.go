@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCredentialsUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		setup      func() *mockVaultRepository
+		wantLength int
+		hasErr     bool
+	}{
+		{
+			name: "succeed: matching login",
+			setup: func() *mockVaultRepository {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("GitHub", "alice", "secret", "https://github.com", "")
+				vault.Entries[entry.ID] = entry
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			wantLength: 1,
+			hasErr:     false,
+		},
+		{
+			name: "succeed: no match",
+			setup: func() *mockVaultRepository {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("GitLab", "alice", "secret", "https://gitlab.com", "")
+				vault.Entries[entry.ID] = entry
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			wantLength: 0,
+			hasErr:     false,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := NewFindCredentialsUsecase(test.setup())
+			got, err := usecase.Execute(domain.Credential{Protocol: "https", Host: "github.com"})
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, got, test.wantLength)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type FindEntryUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewFindEntryUsecase(vaultRepo domain.VaultRepository) *FindEntryUsecase {
+	return &FindEntryUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute returns the entry with the ID or title ref, see
+// domain.Vault.FindEntry. Unlike GetEntryUsecase it does not mark the entry
+// as viewed.
+func (uc *FindEntryUsecase) Execute(ref string) (*domain.Entry, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	en, err := vault.FindEntry(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entry: %w", err)
+	}
+
+	return en, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindEntryUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	vault := domain.NewVault()
+	entry := domain.NewEntry("GitHub", "test username", "test password", "test url", "test notes")
+	vault.Entries[entry.ID] = entry
+
+	tests := []struct {
+		name    string
+		repo    *mockVaultRepository
+		ref     string
+		wantErr error
+		hasErr  bool
+	}{
+		{
+			name: "succeed: find by id",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			ref:    entry.ID,
+			hasErr: false,
+		},
+		{
+			name: "succeed: find by title",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			ref:    "github",
+			hasErr: false,
+		},
+		{
+			name: "failed: vault load error",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return nil, errors.New("load error")
+				},
+			},
+			ref:    entry.ID,
+			hasErr: true,
+		},
+		{
+			name: "failed: entry not found",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			ref:     "gitlab",
+			wantErr: domain.ErrEntryNotFound,
+			hasErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := NewFindEntryUsecase(test.repo)
+			got, err := usecase.Execute(test.ref)
+			if test.hasErr {
+				assert.Error(t, err)
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, entry.ID, got.ID)
+			}
+		})
+	}
+}
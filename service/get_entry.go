@@ -17,6 +17,12 @@ func NewGetEntryUsecase(vaultRepo domain.VaultRepository) *GetEntryUsecase {
 }
 
 func (uc *GetEntryUsecase) Execute(id string) (*domain.Entry, error) {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
 	vault, err := uc.vaultRepo.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load vault: %w", err)
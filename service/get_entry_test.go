@@ -32,6 +32,17 @@ func TestGetEntryUsecase_Execute(t *testing.T) {
 			},
 			hasErr: false,
 		},
+		{
+			name: "failed: vault locked",
+			setup: func() (*mockVaultRepository, string) {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}, "test-id"
+			},
+			hasErr: true,
+		},
 		{
 			name: "failed: vault load error",
 			setup: func() (*mockVaultRepository, string) {
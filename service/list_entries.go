@@ -24,3 +24,14 @@ func (uc *ListEntriesUsecase) Execute() ([]*domain.Entry, error) {
 
 	return vault.ListEntries(), nil
 }
+
+// ExecuteWithFilter lists the entries in the filter's folder, including its
+// subfolders, that carry the filter's tags.
+func (uc *ListEntriesUsecase) ExecuteWithFilter(filter domain.EntryFilter) ([]*domain.Entry, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	return vault.FilterEntries(filter), nil
+}
@@ -92,3 +92,87 @@ func TestListEntriesUsecase_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestListEntriesUsecase_ExecuteWithFilter(t *testing.T) {
+	t.Parallel()
+	newRepo := func() *mockVaultRepository {
+		vault := domain.NewVault()
+		folder := domain.Folder{ID: "folder-1", Name: "Work"}
+		vault.CreateFolder(folder)
+		entry1 := domain.NewEntry("title1", "username1", "password1", "url1", "notes1")
+		entry1.SetTags([]string{"work", "dev"})
+		entry2 := domain.NewEntry("title2", "username2", "password2", "url2", "notes2")
+		entry2.SetTags([]string{"work"})
+		entry2.FolderID = folder.ID
+		entry3 := domain.NewEntry("title3", "username3", "password3", "url3", "notes3")
+		entry3.SetTags([]string{"personal"})
+		vault.Entries[entry1.ID] = entry1
+		vault.Entries[entry2.ID] = entry2
+		vault.Entries[entry3.ID] = entry3
+		return &mockVaultRepository{
+			loadFunc: func() (*domain.Vault, error) {
+				return vault, nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		setup      func() *mockVaultRepository
+		filter     domain.EntryFilter
+		wantLength int
+		hasErr     bool
+	}{
+		{
+			name:       "succeed: all tags",
+			setup:      newRepo,
+			filter:     domain.EntryFilter{Tags: []string{"work", "dev"}, TagMatch: domain.TagMatchAll},
+			wantLength: 1,
+		},
+		{
+			name:       "succeed: any tag",
+			setup:      newRepo,
+			filter:     domain.EntryFilter{Tags: []string{"dev", "personal"}, TagMatch: domain.TagMatchAny},
+			wantLength: 2,
+		},
+		{
+			name:       "succeed: folder",
+			setup:      newRepo,
+			filter:     domain.EntryFilter{FolderID: "folder-1"},
+			wantLength: 1,
+		},
+		{
+			name:       "succeed: empty filter",
+			setup:      newRepo,
+			filter:     domain.EntryFilter{},
+			wantLength: 3,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewListEntriesUsecase(repo)
+			entries, err := usecase.ExecuteWithFilter(test.filter)
+			if test.hasErr {
+				assert.Error(t, err)
+				assert.Nil(t, entries)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, entries, test.wantLength)
+			}
+		})
+	}
+}
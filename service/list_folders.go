@@ -0,0 +1,27 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type ListFoldersUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewListFoldersUsecase(vaultRepo domain.VaultRepository) *ListFoldersUsecase {
+	return &ListFoldersUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute returns every folder, parents before their children.
+func (uc *ListFoldersUsecase) Execute() ([]*domain.Folder, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	return vault.ListFolders(), nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFoldersUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		setup   func() *mockVaultRepository
+		wantIDs []string
+		hasErr  bool
+	}{
+		{
+			name: "succeed: parents before children",
+			setup: func() *mockVaultRepository {
+				vault := domain.NewVault()
+				vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+				vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+				vault.CreateFolder(domain.Folder{ID: "personal", Name: "Personal"})
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			wantIDs: []string{"personal", "work", "clients"},
+			hasErr:  false,
+		},
+		{
+			name: "succeed: no folders",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			wantIDs: []string{},
+			hasErr:  false,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewListFoldersUsecase(repo)
+			folders, err := usecase.Execute()
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				ids := []string{}
+				for _, folder := range folders {
+					ids = append(ids, folder.ID)
+				}
+				assert.Equal(t, test.wantIDs, ids)
+			}
+		})
+	}
+}
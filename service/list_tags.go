@@ -0,0 +1,26 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type ListTagsUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewListTagsUsecase(vaultRepo domain.VaultRepository) *ListTagsUsecase {
+	return &ListTagsUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+func (uc *ListTagsUsecase) Execute() ([]domain.TagCount, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	return vault.Tags(), nil
+}
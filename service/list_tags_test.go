@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTagsUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		setup  func() *mockVaultRepository
+		want   []domain.TagCount
+		hasErr bool
+	}{
+		{
+			name: "succeed: tags with counts",
+			setup: func() *mockVaultRepository {
+				vault := domain.NewVault()
+				entry1 := domain.NewEntry("title1", "username1", "password1", "url1", "notes1")
+				entry1.SetTags([]string{"work", "dev"})
+				entry2 := domain.NewEntry("title2", "username2", "password2", "url2", "notes2")
+				entry2.SetTags([]string{"work"})
+				vault.Entries[entry1.ID] = entry1
+				vault.Entries[entry2.ID] = entry2
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			want: []domain.TagCount{
+				{Name: "dev", Count: 1},
+				{Name: "work", Count: 2},
+			},
+			hasErr: false,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewListTagsUsecase(repo)
+			tags, err := usecase.Execute()
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, tags)
+			}
+		})
+	}
+}
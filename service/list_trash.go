@@ -0,0 +1,27 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type ListTrashUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewListTrashUsecase(vaultRepo domain.VaultRepository) *ListTrashUsecase {
+	return &ListTrashUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute returns the trashed entries, most recently deleted first.
+func (uc *ListTrashUsecase) Execute() ([]*domain.TrashedEntry, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	return vault.ListTrash(), nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTrashUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		setup   func() *mockVaultRepository
+		wantIDs []string
+		hasErr  bool
+	}{
+		{
+			name: "succeed: most recently deleted first",
+			setup: func() *mockVaultRepository {
+				now := time.Now()
+				vault := domain.NewVault()
+				vault.Trash["old"] = &domain.TrashedEntry{Entry: &domain.Entry{ID: "old"}, DeletedAt: now.Add(-time.Hour)}
+				vault.Trash["new"] = &domain.TrashedEntry{Entry: &domain.Entry{ID: "new"}, DeletedAt: now}
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			wantIDs: []string{"new", "old"},
+			hasErr:  false,
+		},
+		{
+			name: "succeed: empty trash",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{}
+			},
+			wantIDs: []string{},
+			hasErr:  false,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewListTrashUsecase(repo)
+			trashed, err := usecase.Execute()
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				ids := []string{}
+				for _, t := range trashed {
+					ids = append(ids, t.Entry.ID)
+				}
+				assert.Equal(t, test.wantIDs, ids)
+			}
+		})
+	}
+}
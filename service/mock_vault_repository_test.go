@@ -6,6 +6,7 @@ type mockVaultRepository struct {
 	loadFunc   func() (*domain.Vault, error)
 	saveFunc   func(vault *domain.Vault) error
 	existsFunc func() bool
+	lockFunc   func() (func(), error)
 }
 
 func (m *mockVaultRepository) Load() (*domain.Vault, error) {
@@ -28,3 +29,10 @@ func (m *mockVaultRepository) Exists() bool {
 	}
 	return true
 }
+
+func (m *mockVaultRepository) Lock() (func(), error) {
+	if m.lockFunc != nil {
+		return m.lockFunc()
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type MoveEntryUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewMoveEntryUsecase(vaultRepo domain.VaultRepository) *MoveEntryUsecase {
+	return &MoveEntryUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute moves an entry into a folder, or to the top level if folderID is
+// empty.
+func (uc *MoveEntryUsecase) Execute(entryID, folderID string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := vault.MoveEntry(entryID, folderID); err != nil {
+		return fmt.Errorf("failed to move entry: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveEntryUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+		vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1", FolderID: "work"})
+		return vault
+	}
+
+	tests := []struct {
+		name     string
+		setup    func() *mockVaultRepository
+		entryID  string
+		folderID string
+		hasErr   bool
+	}{
+		{
+			name: "succeed: into subfolder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "clients",
+			hasErr:   false,
+		},
+		{
+			name: "succeed: to top level",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "",
+			hasErr:   false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "clients",
+			hasErr:   true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "clients",
+			hasErr:   true,
+		},
+		{
+			name: "failed: entry not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			entryID:  "missing",
+			folderID: "clients",
+			hasErr:   true,
+		},
+		{
+			name: "failed: folder not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "missing",
+			hasErr:   true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			entryID:  "entry-1",
+			folderID: "clients",
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewMoveEntryUsecase(repo)
+			err := usecase.Execute(test.entryID, test.folderID)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type MoveFolderUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewMoveFolderUsecase(vaultRepo domain.VaultRepository) *MoveFolderUsecase {
+	return &MoveFolderUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute moves a folder with its contents under parentID, or to the top
+// level if parentID is empty.
+func (uc *MoveFolderUsecase) Execute(id, parentID string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := vault.MoveFolder(id, parentID); err != nil {
+		return fmt.Errorf("failed to move folder: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
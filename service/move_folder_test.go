@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveFolderUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+		vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1", FolderID: "work"})
+		return vault
+	}
+
+	tests := []struct {
+		name     string
+		setup    func() *mockVaultRepository
+		id       string
+		parentID string
+		hasErr   bool
+	}{
+		{
+			name: "succeed: move to top level",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:       "clients",
+			parentID: "",
+			hasErr:   false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			id:       "clients",
+			parentID: "",
+			hasErr:   true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			id:       "clients",
+			parentID: "",
+			hasErr:   true,
+		},
+		{
+			name: "failed: into own subfolder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:       "work",
+			parentID: "clients",
+			hasErr:   true,
+		},
+		{
+			name: "failed: folder not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:       "missing",
+			parentID: "",
+			hasErr:   true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			id:       "clients",
+			parentID: "",
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewMoveFolderUsecase(repo)
+			err := usecase.Execute(test.id, test.parentID)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type PurgeEntryUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewPurgeEntryUsecase(vaultRepo domain.VaultRepository) *PurgeEntryUsecase {
+	return &PurgeEntryUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute permanently deletes an entry from the trash.
+func (uc *PurgeEntryUsecase) Execute(id string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := vault.PurgeEntry(id); err != nil {
+		return fmt.Errorf("failed to purge entry: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeEntryUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1"})
+		vault.Trash["trashed-1"] = &domain.TrashedEntry{
+			Entry:     &domain.Entry{ID: "trashed-1", Title: "title2"},
+			DeletedAt: time.Now(),
+		}
+		return vault
+	}
+
+	tests := []struct {
+		name   string
+		setup  func() *mockVaultRepository
+		id     string
+		hasErr bool
+	}{
+		{
+			name: "succeed: purge trashed entry",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						if _, ok := vault.Trash["trashed-1"]; ok {
+							return errors.New("entry still in trash")
+						}
+						return nil
+					},
+				}
+			},
+			id:     "trashed-1",
+			hasErr: false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			id:     "trashed-1",
+			hasErr: true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			id:     "trashed-1",
+			hasErr: true,
+		},
+		{
+			name: "failed: entry not in trash",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:     "entry-1",
+			hasErr: true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			id:     "trashed-1",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewPurgeEntryUsecase(repo)
+			err := usecase.Execute(test.id)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type PurgeTrashUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewPurgeTrashUsecase(vaultRepo domain.VaultRepository) *PurgeTrashUsecase {
+	return &PurgeTrashUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute permanently deletes the entries that have been in the trash for
+// at least maxAge. A zero maxAge empties the trash. It returns the number
+// of purged entries; the vault is only saved if there were any.
+func (uc *PurgeTrashUsecase) Execute(maxAge time.Duration) (int, error) {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	purged := vault.PurgeTrash(time.Now().Add(-maxAge))
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return 0, fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeTrashUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		now := time.Now()
+		vault := domain.NewVault()
+		vault.Trash["old"] = &domain.TrashedEntry{Entry: &domain.Entry{ID: "old"}, DeletedAt: now.Add(-40 * 24 * time.Hour)}
+		vault.Trash["new"] = &domain.TrashedEntry{Entry: &domain.Entry{ID: "new"}, DeletedAt: now.Add(-time.Hour)}
+		return vault
+	}
+
+	tests := []struct {
+		name       string
+		setup      func() *mockVaultRepository
+		maxAge     time.Duration
+		wantPurged int
+		hasErr     bool
+	}{
+		{
+			name: "succeed: purge old entries",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			maxAge:     30 * 24 * time.Hour,
+			wantPurged: 1,
+			hasErr:     false,
+		},
+		{
+			name: "succeed: empty the trash",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			maxAge:     0,
+			wantPurged: 2,
+			hasErr:     false,
+		},
+		{
+			name: "succeed: nothing to purge does not save",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("unexpected save")
+					},
+				}
+			},
+			maxAge:     365 * 24 * time.Hour,
+			wantPurged: 0,
+			hasErr:     false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			maxAge: 0,
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewPurgeTrashUsecase(repo)
+			purged, err := usecase.Execute(test.maxAge)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantPurged, purged)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type RenameFolderUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewRenameFolderUsecase(vaultRepo domain.VaultRepository) *RenameFolderUsecase {
+	return &RenameFolderUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+func (uc *RenameFolderUsecase) Execute(id, name string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := vault.RenameFolder(id, name); err != nil {
+		return fmt.Errorf("failed to rename folder: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
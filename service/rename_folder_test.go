@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameFolderUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		vault.CreateFolder(domain.Folder{ID: "work", Name: "Work"})
+		vault.CreateFolder(domain.Folder{ID: "clients", Name: "Clients", ParentID: "work"})
+		vault.CreateEntry(domain.Entry{ID: "entry-1", Title: "title1", FolderID: "work"})
+		return vault
+	}
+
+	tests := []struct {
+		name       string
+		setup      func() *mockVaultRepository
+		id         string
+		folderName string
+		hasErr     bool
+	}{
+		{
+			name: "succeed: rename folder",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:         "clients",
+			folderName: "Customers",
+			hasErr:     false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			id:         "clients",
+			folderName: "Customers",
+			hasErr:     true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			id:         "clients",
+			folderName: "Customers",
+			hasErr:     true,
+		},
+		{
+			name: "failed: folder not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:         "missing",
+			folderName: "Customers",
+			hasErr:     true,
+		},
+		{
+			name: "failed: empty name",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			id:         "clients",
+			folderName: "",
+			hasErr:     true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			id:         "clients",
+			folderName: "Customers",
+			hasErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewRenameFolderUsecase(repo)
+			err := usecase.Execute(test.id, test.folderName)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
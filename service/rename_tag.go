@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type RenameTagUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewRenameTagUsecase(vaultRepo domain.VaultRepository) *RenameTagUsecase {
+	return &RenameTagUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute renames a tag on every entry, merging it into the new name if
+// that tag already exists. It returns the number of entries changed.
+func (uc *RenameTagUsecase) Execute(from, to string) (int, error) {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	changed, err := vault.RenameTag(from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return 0, fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameTagUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() *domain.Vault {
+		vault := domain.NewVault()
+		entry1 := domain.NewEntry("title1", "username1", "password1", "url1", "notes1")
+		entry1.SetTags([]string{"work", "dev"})
+		entry2 := domain.NewEntry("title2", "username2", "password2", "url2", "notes2")
+		entry2.SetTags([]string{"job"})
+		vault.Entries[entry1.ID] = entry1
+		vault.Entries[entry2.ID] = entry2
+		return vault
+	}
+
+	tests := []struct {
+		name        string
+		setup       func() *mockVaultRepository
+		from        string
+		to          string
+		wantChanged int
+		hasErr      bool
+	}{
+		{
+			name: "succeed: merge tag",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			from:        "work",
+			to:          "job",
+			wantChanged: 1,
+			hasErr:      false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}
+			},
+			from:   "work",
+			to:     "job",
+			hasErr: true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() *mockVaultRepository {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}
+			},
+			from:   "work",
+			to:     "job",
+			hasErr: true,
+		},
+		{
+			name: "failed: tag not found",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}
+			},
+			from:   "travel",
+			to:     "trips",
+			hasErr: true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() *mockVaultRepository {
+				vault := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}
+			},
+			from:   "work",
+			to:     "job",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo := test.setup()
+			usecase := NewRenameTagUsecase(repo)
+			changed, err := usecase.Execute(test.from, test.to)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantChanged, changed)
+			}
+		})
+	}
+}
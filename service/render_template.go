@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type RenderTemplateUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewRenderTemplateUsecase(vaultRepo domain.VaultRepository) *RenderTemplateUsecase {
+	return &RenderTemplateUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute renders a text/template with functions that read the vault:
+//
+//	entry REF        the entry with the ID or title REF
+//	field REF NAME   a field of the entry REF, see domain.Entry.Field
+//	get ENTRY NAME   a field of an entry, as in {{range tagged "db"}}
+//	tagged TAG       the entries carrying TAG, sorted by title
+//	ref URI          the value of a pv:// secret reference
+//
+// A missing entry, field or tag fails the rendering instead of leaving a
+// gap in the output.
+func (uc *RenderTemplateUsecase) Execute(name, text string) ([]byte, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(templateFuncs(vault, time.Now())).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func templateFuncs(vault *domain.Vault, now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"entry": vault.FindEntry,
+		"field": func(ref, name string) (string, error) {
+			return vault.Resolve(domain.SecretReference{Entry: ref, Field: name}, now)
+		},
+		"get": func(en *domain.Entry, name string) (string, error) {
+			return en.Field(name, now)
+		},
+		"tagged": func(tag string) ([]*domain.Entry, error) {
+			tag, err := domain.NormalizeTag(tag)
+			if err != nil {
+				return nil, err
+			}
+			entries := vault.ListEntriesByTags([]string{tag}, domain.TagMatchAll)
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("%w: %s", domain.ErrTagNotFound, tag)
+			}
+			slices.SortFunc(entries, func(a, b *domain.Entry) int {
+				if c := strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)); c != 0 {
+					return c
+				}
+				return strings.Compare(a.ID, b.ID)
+			})
+			return entries, nil
+		},
+		"ref": func(uri string) (string, error) {
+			ref, err := domain.ParseSecretReference(uri)
+			if err != nil {
+				return "", err
+			}
+			return vault.Resolve(ref, now)
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplateUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	vault := domain.NewVault()
+	github := domain.NewEntry("GitHub", "alice", "gh-secret", "https://github.com", "")
+	primary := domain.NewEntry("Primary DB", "app", "db-secret-1", "postgres://primary", "")
+	replica := domain.NewEntry("Replica DB", "app", "db-secret-2", "postgres://replica", "")
+	assert.NoError(t, primary.SetTags([]string{"db"}))
+	assert.NoError(t, replica.SetTags([]string{"db"}))
+	assert.NoError(t, primary.SetCustomFields([]domain.CustomField{{Name: "port", Type: domain.FieldText, Value: "5432"}}))
+	for _, en := range []*domain.Entry{github, primary, replica} {
+		vault.Entries[en.ID] = en
+	}
+	repo := &mockVaultRepository{
+		loadFunc: func() (*domain.Vault, error) {
+			return vault, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		repo    *mockVaultRepository
+		text    string
+		want    string
+		wantErr error
+		hasErr  bool
+	}{
+		{
+			name:   "succeed: field by title and id",
+			repo:   repo,
+			text:   `user: {{ field "github" "username" }}` + "\n" + `token: {{ field "` + github.ID + `" "password" }}`,
+			want:   "user: alice\ntoken: gh-secret",
+			hasErr: false,
+		},
+		{
+			name:   "succeed: entry and ref",
+			repo:   repo,
+			text:   `{{ (entry "GitHub").URL }} {{ ref "pv://Primary%20DB/port" }}`,
+			want:   "https://github.com 5432",
+			hasErr: false,
+		},
+		{
+			name:   "succeed: range over tagged entries",
+			repo:   repo,
+			text:   `{{ range tagged "DB" }}{{ .Title }}={{ get . "password" }};{{ end }}`,
+			want:   "Primary DB=db-secret-1;Replica DB=db-secret-2;",
+			hasErr: false,
+		},
+		{
+			name:    "failed: missing entry",
+			repo:    repo,
+			text:    `{{ field "GitLab" "password" }}`,
+			wantErr: domain.ErrEntryNotFound,
+			hasErr:  true,
+		},
+		{
+			name:    "failed: missing field",
+			repo:    repo,
+			text:    `{{ field "GitHub" "pin" }}`,
+			wantErr: domain.ErrFieldNotFound,
+			hasErr:  true,
+		},
+		{
+			name:    "failed: missing tag",
+			repo:    repo,
+			text:    `{{ range tagged "cache" }}{{ end }}`,
+			wantErr: domain.ErrTagNotFound,
+			hasErr:  true,
+		},
+		{
+			name:   "failed: parse error",
+			repo:   repo,
+			text:   `{{ field "GitHub" `,
+			hasErr: true,
+		},
+		{
+			name: "failed: vault load error",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return nil, errors.New("load error")
+				},
+			},
+			text:   "plain",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := NewRenderTemplateUsecase(test.repo)
+			got, err := usecase.Execute("config.tmpl", test.text)
+			if test.hasErr {
+				assert.Error(t, err)
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type ResolveReferencesUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewResolveReferencesUsecase(vaultRepo domain.VaultRepository) *ResolveReferencesUsecase {
+	return &ResolveReferencesUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute returns the values of the referenced fields, in order. The vault
+// is loaded once, so that every value comes from the same revision.
+func (uc *ResolveReferencesUsecase) Execute(refs []domain.SecretReference) ([]string, error) {
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	now := time.Now()
+	values := make([]string, len(refs))
+	for i, ref := range refs {
+		value, err := vault.Resolve(ref, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
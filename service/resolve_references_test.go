@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveReferencesUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	vault := domain.NewVault()
+	entry := domain.NewEntry("GitHub", "alice", "secret", "https://github.com", "")
+	vault.Entries[entry.ID] = entry
+
+	tests := []struct {
+		name   string
+		repo   *mockVaultRepository
+		refs   []domain.SecretReference
+		want   []string
+		hasErr bool
+	}{
+		{
+			name: "succeed: resolve several references",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			refs: []domain.SecretReference{
+				{Entry: "GitHub", Field: "password"},
+				{Entry: entry.ID, Field: "username"},
+			},
+			want:   []string{"secret", "alice"},
+			hasErr: false,
+		},
+		{
+			name: "succeed: no references",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			refs:   nil,
+			want:   []string{},
+			hasErr: false,
+		},
+		{
+			name: "failed: vault load error",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return nil, errors.New("load error")
+				},
+			},
+			refs:   []domain.SecretReference{{Entry: "GitHub", Field: "password"}},
+			hasErr: true,
+		},
+		{
+			name: "failed: unknown field",
+			repo: &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+			},
+			refs:   []domain.SecretReference{{Entry: "GitHub", Field: "pin"}},
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := NewResolveReferencesUsecase(test.repo)
+			got, err := usecase.Execute(test.refs)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
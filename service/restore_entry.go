@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type RestoreEntryUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewRestoreEntryUsecase(vaultRepo domain.VaultRepository) *RestoreEntryUsecase {
+	return &RestoreEntryUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute moves an entry from the trash back to the vault.
+func (uc *RestoreEntryUsecase) Execute(id string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if _, err := vault.RestoreEntry(id); err != nil {
+		return fmt.Errorf("failed to restore entry: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
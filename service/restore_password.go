@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type RestorePasswordUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewRestorePasswordUsecase(vaultRepo domain.VaultRepository) *RestorePasswordUsecase {
+	return &RestorePasswordUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute makes the password at index in the entry's history current again.
+func (uc *RestorePasswordUsecase) Execute(id string, index int) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	en, err := vault.GetEntry(id)
+	if err != nil {
+		return fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	if err := en.RestorePassword(index); err != nil {
+		return fmt.Errorf("failed to restore password: %w", err)
+	}
+
+	if err := vault.UpdateEntry(*en); err != nil {
+		return fmt.Errorf("failed to update entry: %w", err)
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
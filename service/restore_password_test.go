@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestorePasswordUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	newVault := func() (*domain.Vault, string) {
+		vault := domain.NewVault()
+		entry := domain.NewEntry("title", "username", "old password", "url", "notes")
+		entry.Update("title", "username", "new password", "url", "notes")
+		vault.Entries[entry.ID] = entry
+		return vault, entry.ID
+	}
+
+	tests := []struct {
+		name         string
+		setup        func() (*mockVaultRepository, string, *domain.Vault)
+		index        int
+		wantPassword string
+		hasErr       bool
+	}{
+		{
+			name: "succeed: restore previous password",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				vault, id := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}, id, vault
+			},
+			index:        0,
+			wantPassword: "old password",
+			hasErr:       false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}, "test-id", nil
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: vault load error",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return nil, errors.New("load error")
+					},
+				}, "test-id", nil
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: entry not found",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				vault, _ := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}, "non-existent-id", vault
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: history entry not found",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				vault, id := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}, id, vault
+			},
+			index:  1,
+			hasErr: true,
+		},
+		{
+			name: "failed: vault save error",
+			setup: func() (*mockVaultRepository, string, *domain.Vault) {
+				vault, id := newVault()
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						return errors.New("save error")
+					},
+				}, id, vault
+			},
+			index:  0,
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo, id, vault := test.setup()
+			usecase := NewRestorePasswordUsecase(repo)
+			err := usecase.Execute(id, test.index)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantPassword, vault.Entries[id].Password)
+			}
+		})
+	}
+}
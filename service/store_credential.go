@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+type StoreCredentialUsecase struct {
+	vaultRepo domain.VaultRepository
+}
+
+func NewStoreCredentialUsecase(vaultRepo domain.VaultRepository) *StoreCredentialUsecase {
+	return &StoreCredentialUsecase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Execute updates the password of the best matching login or creates a login
+// for the host. The lookup and the change happen under one vault lock, so
+// that concurrent requests for the same host do not create duplicates.
+func (uc *StoreCredentialUsecase) Execute(credential domain.Credential, password string) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
+	vault, err := uc.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if entries := vault.FindCredentials(credential); len(entries) > 0 {
+		en := entries[0]
+		if en.Password == password {
+			return nil
+		}
+		en.Update(en.Title, en.Username, password, en.URL, en.Notes)
+		if err := vault.UpdateEntry(*en); err != nil {
+			return fmt.Errorf("failed to update entry: %w", err)
+		}
+	} else {
+		title := credential.Host
+		if credential.Path != "" {
+			title += "/" + strings.TrimPrefix(credential.Path, "/")
+		}
+		en := domain.NewEntry(title, credential.Username, password, credential.URL(), "")
+		if err := vault.CreateEntry(*en); err != nil {
+			return fmt.Errorf("failed to create entry: %w", err)
+		}
+	}
+
+	if err := uc.vaultRepo.Save(vault); err != nil {
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreCredentialUsecase_Execute(t *testing.T) {
+	t.Parallel()
+	credential := domain.Credential{Protocol: "https", Host: "github.com", Path: "ritarock/passvault.git", Username: "alice"}
+	tests := []struct {
+		name         string
+		setup        func() (*mockVaultRepository, *domain.Vault, *bool)
+		wantEntries  int
+		wantPassword string
+		wantSaved    bool
+		hasErr       bool
+	}{
+		{
+			name: "succeed: update matching login",
+			setup: func() (*mockVaultRepository, *domain.Vault, *bool) {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("GitHub", "alice", "old password", "https://github.com", "")
+				vault.Entries[entry.ID] = entry
+				return newStoreCredentialRepo(vault)
+			},
+			wantEntries:  1,
+			wantPassword: "new password",
+			wantSaved:    true,
+			hasErr:       false,
+		},
+		{
+			name: "succeed: create login for the host",
+			setup: func() (*mockVaultRepository, *domain.Vault, *bool) {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("GitLab", "alice", "old password", "https://gitlab.com", "")
+				vault.Entries[entry.ID] = entry
+				return newStoreCredentialRepo(vault)
+			},
+			wantEntries:  2,
+			wantPassword: "new password",
+			wantSaved:    true,
+			hasErr:       false,
+		},
+		{
+			name: "succeed: password unchanged",
+			setup: func() (*mockVaultRepository, *domain.Vault, *bool) {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("GitHub", "alice", "new password", "https://github.com", "")
+				vault.Entries[entry.ID] = entry
+				return newStoreCredentialRepo(vault)
+			},
+			wantEntries:  1,
+			wantPassword: "new password",
+			wantSaved:    false,
+			hasErr:       false,
+		},
+		{
+			name: "failed: lock error",
+			setup: func() (*mockVaultRepository, *domain.Vault, *bool) {
+				repo, vault, saved := newStoreCredentialRepo(domain.NewVault())
+				repo.lockFunc = func() (func(), error) {
+					return nil, errors.New("lock error")
+				}
+				return repo, vault, saved
+			},
+			wantEntries: 0,
+			wantSaved:   false,
+			hasErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			repo, vault, saved := test.setup()
+			usecase := NewStoreCredentialUsecase(repo)
+			err := usecase.Execute(credential, "new password")
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantSaved, *saved)
+			assert.Len(t, vault.Entries, test.wantEntries)
+			if test.wantPassword != "" {
+				entries := vault.FindCredentials(credential)
+				assert.Len(t, entries, 1)
+				assert.Equal(t, test.wantPassword, entries[0].Password)
+			}
+		})
+	}
+}
+
+// newStoreCredentialRepo returns a repository that only allows loading the
+// vault while it is locked.
+func newStoreCredentialRepo(vault *domain.Vault) (*mockVaultRepository, *domain.Vault, *bool) {
+	locked, saved := false, false
+	return &mockVaultRepository{
+		lockFunc: func() (func(), error) {
+			locked = true
+			return func() { locked = false }, nil
+		},
+		loadFunc: func() (*domain.Vault, error) {
+			if !locked {
+				return nil, errors.New("vault loaded without the lock")
+			}
+			return vault, nil
+		},
+		saveFunc: func(*domain.Vault) error {
+			saved = true
+			return nil
+		},
+	}, vault, &saved
+}
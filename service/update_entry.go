@@ -16,7 +16,28 @@ func NewUpdateEntryUsecase(vaultRepo domain.VaultRepository) *UpdateEntryUsecase
 	}
 }
 
-func (uc *UpdateEntryUsecase) Execute(id, title, username, password, url, notes string) error {
+func (uc *UpdateEntryUsecase) Execute(id string, input EntryInput) error {
+	return uc.update(id, func(*domain.Entry) EntryInput { return input })
+}
+
+// Edit applies edit to the entry's current fields. The entry is read and
+// saved under one vault lock, so changes saved by another process before the
+// lock was taken are kept.
+func (uc *UpdateEntryUsecase) Edit(id string, edit func(*EntryInput)) error {
+	return uc.update(id, func(en *domain.Entry) EntryInput {
+		input := NewEntryInput(en)
+		edit(&input)
+		return input
+	})
+}
+
+func (uc *UpdateEntryUsecase) update(id string, newInput func(*domain.Entry) EntryInput) error {
+	unlock, err := uc.vaultRepo.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+	defer unlock()
+
 	vault, err := uc.vaultRepo.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load vault: %w", err)
@@ -27,11 +48,27 @@ func (uc *UpdateEntryUsecase) Execute(id, title, username, password, url, notes
 		return fmt.Errorf("failed to get entry: %w", err)
 	}
 
-	en.Update(title, username, password, url, notes)
+	input := newInput(en)
+	en.Update(input.Title, input.Username, input.Password, input.URL, input.Notes)
+	if err := en.SetType(input.Type, input.Details); err != nil {
+		return fmt.Errorf("failed to set type: %w", err)
+	}
+	if err := en.SetTOTP(input.TOTP); err != nil {
+		return fmt.Errorf("failed to set TOTP: %w", err)
+	}
+	if err := en.SetCustomFields(input.CustomFields); err != nil {
+		return fmt.Errorf("failed to set custom fields: %w", err)
+	}
+	if err := en.SetTags(input.Tags); err != nil {
+		return fmt.Errorf("failed to set tags: %w", err)
+	}
 
 	if err := vault.UpdateEntry(*en); err != nil {
 		return fmt.Errorf("failed to update entry: %w", err)
 	}
+	if err := vault.MoveEntry(en.ID, input.FolderID); err != nil {
+		return fmt.Errorf("failed to move entry: %w", err)
+	}
 
 	if err := uc.vaultRepo.Save(vault); err != nil {
 		return fmt.Errorf("failed to save vault: %w", err)
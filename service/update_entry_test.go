@@ -11,14 +11,10 @@ import (
 func TestUpdateEntryUsecase_Execute(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name     string
-		setup    func() (*mockVaultRepository, string)
-		title    string
-		username string
-		password string
-		url      string
-		notes    string
-		hasErr   bool
+		name   string
+		setup  func() (*mockVaultRepository, string)
+		input  EntryInput
+		hasErr bool
 	}{
 		{
 			name: "succeed: update existing entry",
@@ -35,12 +31,79 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 					},
 				}, entry.ID
 			},
-			title:    "new title",
-			username: "new username",
-			password: "new password",
-			url:      "new url",
-			notes:    "new notes",
-			hasErr:   false,
+			input: EntryInput{
+				Title:    "new title",
+				Username: "new username",
+				Password: "new password",
+				URL:      "new url",
+				Notes:    "new notes",
+			},
+			hasErr: false,
+		},
+		{
+			name: "failed: invalid TOTP",
+			setup: func() (*mockVaultRepository, string) {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("old title", "old username", "old password", "old url", "old notes")
+				vault.Entries[entry.ID] = entry
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+				}, entry.ID
+			},
+			input: EntryInput{
+				Title:    "new title",
+				Password: "new password",
+				TOTP:     "not base32!",
+			},
+			hasErr: true,
+		},
+		{
+			name: "succeed: update custom fields",
+			setup: func() (*mockVaultRepository, string) {
+				vault := domain.NewVault()
+				entry := domain.NewEntry("old title", "old username", "old password", "old url", "old notes")
+				vault.Entries[entry.ID] = entry
+				return &mockVaultRepository{
+					loadFunc: func() (*domain.Vault, error) {
+						return vault, nil
+					},
+					saveFunc: func(vault *domain.Vault) error {
+						if len(vault.Entries[entry.ID].CustomFields) != 2 {
+							return errors.New("custom fields not saved")
+						}
+						return nil
+					},
+				}, entry.ID
+			},
+			input: EntryInput{
+				Title:    "new title",
+				Password: "new password",
+				CustomFields: []domain.CustomField{
+					{Name: "PIN", Type: domain.FieldHidden, Value: "1234"},
+					{Name: "Recovery", Type: domain.FieldEmail, Value: "alice@example.com"},
+				},
+			},
+			hasErr: false,
+		},
+		{
+			name: "failed: vault locked",
+			setup: func() (*mockVaultRepository, string) {
+				return &mockVaultRepository{
+					lockFunc: func() (func(), error) {
+						return nil, errors.New("locked")
+					},
+				}, "test-id"
+			},
+			input: EntryInput{
+				Title:    "new title",
+				Username: "new username",
+				Password: "new password",
+				URL:      "new url",
+				Notes:    "new notes",
+			},
+			hasErr: true,
 		},
 		{
 			name: "failed: vault load error",
@@ -51,12 +114,14 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 					},
 				}, "test-id"
 			},
-			title:    "new title",
-			username: "new username",
-			password: "new password",
-			url:      "new url",
-			notes:    "new notes",
-			hasErr:   true,
+			input: EntryInput{
+				Title:    "new title",
+				Username: "new username",
+				Password: "new password",
+				URL:      "new url",
+				Notes:    "new notes",
+			},
+			hasErr: true,
 		},
 		{
 			name: "failed: entry not found",
@@ -67,12 +132,14 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 					},
 				}, "non-existent-id"
 			},
-			title:    "new title",
-			username: "new username",
-			password: "new password",
-			url:      "new url",
-			notes:    "new notes",
-			hasErr:   true,
+			input: EntryInput{
+				Title:    "new title",
+				Username: "new username",
+				Password: "new password",
+				URL:      "new url",
+				Notes:    "new notes",
+			},
+			hasErr: true,
 		},
 		{
 			name: "failed: vault save error",
@@ -89,12 +156,14 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 					},
 				}, entry.ID
 			},
-			title:    "new title",
-			username: "new username",
-			password: "new password",
-			url:      "new url",
-			notes:    "new notes",
-			hasErr:   true,
+			input: EntryInput{
+				Title:    "new title",
+				Username: "new username",
+				Password: "new password",
+				URL:      "new url",
+				Notes:    "new notes",
+			},
+			hasErr: true,
 		},
 	}
 
@@ -103,7 +172,7 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 			t.Parallel()
 			repo, id := test.setup()
 			usecase := NewUpdateEntryUsecase(repo)
-			err := usecase.Execute(id, test.title, test.username, test.password, test.url, test.notes)
+			err := usecase.Execute(id, test.input)
 			if test.hasErr {
 				assert.Error(t, err)
 			} else {
@@ -112,3 +181,57 @@ func TestUpdateEntryUsecase_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateEntryUsecase_Edit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		id     string
+		hasErr bool
+	}{
+		{
+			name:   "succeed: keep fields saved since the entry was read",
+			id:     "test-id-1",
+			hasErr: false,
+		},
+		{
+			name:   "failed: entry not found",
+			id:     "unknown-id",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			vault := domain.NewVault()
+			vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "title", Username: "old username", Password: "password"})
+			// Another process changed the username after the entry was read.
+			vault.Entries["test-id-1"].Username = "new username"
+			var saved *domain.Vault
+			repo := &mockVaultRepository{
+				loadFunc: func() (*domain.Vault, error) {
+					return vault, nil
+				},
+				saveFunc: func(vault *domain.Vault) error {
+					saved = vault
+					return nil
+				},
+			}
+
+			err := NewUpdateEntryUsecase(repo).Edit(test.id, func(input *EntryInput) {
+				input.Title = "new title"
+			})
+			if test.hasErr {
+				assert.Error(t, err)
+				assert.Nil(t, saved)
+				return
+			}
+			assert.NoError(t, err)
+			entry := saved.Entries["test-id-1"]
+			assert.Equal(t, "new title", entry.Title)
+			assert.Equal(t, "new username", entry.Username)
+			assert.Equal(t, "password", entry.Password)
+		})
+	}
+}
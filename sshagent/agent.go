@@ -0,0 +1,398 @@
+// Package sshagent serves the SSH keys stored in the vault over the
+// ssh-agent protocol, so that private keys never have to be written to
+// ~/.ssh.
+package sshagent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	ErrAgentLocked  = errors.New("agent is locked")
+	ErrKeyNotFound  = errors.New("key not found")
+	ErrVaultKey     = errors.New("key is stored in the vault and cannot be removed")
+	ErrUseDenied    = errors.New("use of key was not confirmed")
+	ErrNoConfirm    = errors.New("key requires confirmation but no prompt is available")
+	ErrUnsupported  = errors.New("unsupported key constraint")
+	errUnknownFlags = errors.New("unsupported signature flags")
+)
+
+// ConfirmFunc asks the user whether the key with the comment may be used
+// for a signature.
+type ConfirmFunc func(comment string) (bool, error)
+
+type Options struct {
+	// Lifetime is how long the vault keys are served after the agent is
+	// created. Zero serves them until the agent stops.
+	Lifetime time.Duration
+	// ConfirmEachUse asks before every signature with a vault key.
+	ConfirmEachUse bool
+	// Confirm asks the user. Keys that require confirmation cannot be used
+	// without it.
+	Confirm ConfirmFunc
+}
+
+// key is a signer with the constraints it is used under.
+type key struct {
+	signer  ssh.Signer
+	comment string
+	confirm bool
+	// expiresAt is zero for keys without a lifetime.
+	expiresAt time.Time
+}
+
+// Agent implements agent.ExtendedAgent. The vault keys are read on every
+// request, so entries added or removed while the agent runs are picked up.
+// Keys added by clients, e.g. with ssh-add, are kept in memory only.
+type Agent struct {
+	mu             sync.Mutex
+	listEntriesUc  *service.ListEntriesUsecase
+	opts           Options
+	vaultExpiresAt time.Time
+	added          []key
+	// signers caches the parsed vault keys by their PEM text and passphrase,
+	// as decrypting a protected key is deliberately slow.
+	signers    map[string]ssh.Signer
+	passphrase []byte
+	locked     bool
+	now        func() time.Time
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+func NewAgent(listEntriesUc *service.ListEntriesUsecase, opts Options) *Agent {
+	a := &Agent{
+		listEntriesUc: listEntriesUc,
+		opts:          opts,
+		signers:       make(map[string]ssh.Signer),
+		now:           time.Now,
+	}
+	if opts.Lifetime > 0 {
+		a.vaultExpiresAt = a.now().Add(opts.Lifetime)
+	}
+	return a
+}
+
+// Serve answers agent requests on every connection accepted from l until l
+// is closed.
+func (a *Agent) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(a, conn)
+		}()
+	}
+}
+
+// keys returns the usable keys, vault keys first. It must be called with
+// a.mu held.
+func (a *Agent) keys() ([]key, error) {
+	now := a.now()
+	var keys []key
+	seen := make(map[string]bool)
+
+	if !a.vaultExpiresAt.IsZero() && !now.Before(a.vaultExpiresAt) {
+		// Expired vault keys must not stay decrypted in memory.
+		a.signers = nil
+	} else {
+		entries, err := a.listEntriesUc.ExecuteWithFilter(domain.EntryFilter{Type: domain.EntrySSHKey})
+		if err != nil {
+			return nil, err
+		}
+		cached := a.signers
+		a.signers = make(map[string]ssh.Signer, len(entries))
+		for _, en := range entries {
+			if en.SSHKey == nil {
+				continue
+			}
+			cacheKey := en.SSHKey.PrivateKey + "\x00" + en.SSHKey.Passphrase
+			signer, ok := cached[cacheKey]
+			if !ok {
+				var err error
+				if signer, err = parseSigner(en.SSHKey); err != nil {
+					// One unusable entry must not take the other keys down.
+					continue
+				}
+			}
+			a.signers[cacheKey] = signer
+			blob := string(signer.PublicKey().Marshal())
+			if seen[blob] {
+				continue
+			}
+			seen[blob] = true
+			keys = append(keys, key{
+				signer:    signer,
+				comment:   en.Title,
+				confirm:   a.opts.ConfirmEachUse,
+				expiresAt: a.vaultExpiresAt,
+			})
+		}
+	}
+
+	live := a.added[:0]
+	for _, k := range a.added {
+		if !k.expiresAt.IsZero() && !now.Before(k.expiresAt) {
+			continue
+		}
+		live = append(live, k)
+		if blob := string(k.signer.PublicKey().Marshal()); !seen[blob] {
+			seen[blob] = true
+			keys = append(keys, k)
+		}
+	}
+	a.added = live
+
+	return keys, nil
+}
+
+func parseSigner(sshKey *domain.SSHKey) (ssh.Signer, error) {
+	if sshKey.Passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase([]byte(sshKey.PrivateKey), []byte(sshKey.Passphrase))
+	}
+	return ssh.ParsePrivateKey([]byte(sshKey.PrivateKey))
+}
+
+func (a *Agent) List() ([]*agent.Key, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return []*agent.Key{}, nil
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*agent.Key, 0, len(keys))
+	for _, k := range keys {
+		pub := k.signer.PublicKey()
+		list = append(list, &agent.Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: k.comment})
+	}
+	return list, nil
+}
+
+func (a *Agent) Sign(pub ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(pub, data, 0)
+}
+
+// SignWithFlags signs data with the key. Signing requests are handled one
+// at a time, so that confirmation prompts do not overlap.
+func (a *Agent) SignWithFlags(pub ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return nil, ErrAgentLocked
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+	wanted := pub.Marshal()
+	for _, k := range keys {
+		if !bytes.Equal(k.signer.PublicKey().Marshal(), wanted) {
+			continue
+		}
+		if k.confirm {
+			if err := a.confirm(k.comment); err != nil {
+				return nil, err
+			}
+		}
+		return sign(k.signer, data, flags)
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (a *Agent) confirm(comment string) error {
+	if a.opts.Confirm == nil {
+		return ErrNoConfirm
+	}
+	ok, err := a.opts.Confirm(comment)
+	if err != nil {
+		return fmt.Errorf("failed to confirm use of key: %w", err)
+	}
+	if !ok {
+		return ErrUseDenied
+	}
+	return nil
+}
+
+func sign(signer ssh.Signer, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if flags == 0 {
+		return signer.Sign(rand.Reader, data)
+	}
+
+	algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", errUnknownFlags, flags)
+	}
+	switch flags {
+	case agent.SignatureFlagRsaSha256:
+		return algorithmSigner.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA256)
+	case agent.SignatureFlagRsaSha512:
+		return algorithmSigner.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnknownFlags, flags)
+	}
+}
+
+// Add keeps a key added by a client in memory, honoring its lifetime and
+// confirmation constraints.
+func (a *Agent) Add(added agent.AddedKey) error {
+	if len(added.ConstraintExtensions) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupported, added.ConstraintExtensions[0].ExtensionName)
+	}
+
+	signer, err := ssh.NewSignerFromKey(added.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if added.Certificate != nil {
+		if signer, err = ssh.NewCertSigner(added.Certificate, signer); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return ErrAgentLocked
+	}
+
+	k := key{signer: signer, comment: added.Comment, confirm: added.ConfirmBeforeUse || a.opts.ConfirmEachUse}
+	if added.LifetimeSecs > 0 {
+		k.expiresAt = a.now().Add(time.Duration(added.LifetimeSecs) * time.Second)
+	}
+
+	blob := signer.PublicKey().Marshal()
+	for i, existing := range a.added {
+		if bytes.Equal(existing.signer.PublicKey().Marshal(), blob) {
+			a.added[i] = k
+			return nil
+		}
+	}
+	a.added = append(a.added, k)
+	return nil
+}
+
+// Remove forgets a key added by a client. Vault keys are removed by
+// deleting their entry.
+func (a *Agent) Remove(pub ssh.PublicKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return ErrAgentLocked
+	}
+
+	wanted := pub.Marshal()
+	for i, k := range a.added {
+		if bytes.Equal(k.signer.PublicKey().Marshal(), wanted) {
+			a.added = append(a.added[:i], a.added[i+1:]...)
+			return nil
+		}
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if bytes.Equal(k.signer.PublicKey().Marshal(), wanted) {
+			return ErrVaultKey
+		}
+	}
+	return ErrKeyNotFound
+}
+
+// RemoveAll forgets the keys added by clients.
+func (a *Agent) RemoveAll() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return ErrAgentLocked
+	}
+	a.added = nil
+	return nil
+}
+
+// Lock stops listing and signing with any key until Unlock is called with
+// the same passphrase.
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return ErrAgentLocked
+	}
+	a.locked = true
+	a.passphrase = bytes.Clone(passphrase)
+	return nil
+}
+
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.locked {
+		return errors.New("agent is not locked")
+	}
+	if subtle.ConstantTimeCompare(passphrase, a.passphrase) != 1 {
+		return errors.New("incorrect passphrase")
+	}
+	a.locked = false
+	a.passphrase = nil
+	return nil
+}
+
+// Signers returns the signers of every key. It is only used by in-process
+// clients and does not ask for confirmation, so it refuses when any key
+// requires it.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.locked {
+		return nil, ErrAgentLocked
+	}
+
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]ssh.Signer, 0, len(keys))
+	for _, k := range keys {
+		if k.confirm {
+			return nil, ErrNoConfirm
+		}
+		signers = append(signers, k.signer)
+	}
+	return signers, nil
+}
+
+func (a *Agent) Extension(string, []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
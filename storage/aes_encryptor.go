@@ -4,7 +4,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/json"
 	"errors"
 	"io"
 )
@@ -13,71 +12,91 @@ var (
 	ErrDecryptionFailed = errors.New("decryption failed")
 )
 
+// EncryptedData is a GCM nonce and ciphertext pair. It is the legacy
+// version 1 vault format and the format of wrapped keys in key.json.
 type EncryptedData struct {
 	Nonce      []byte `json:"nonce"`
 	Ciphertext []byte `json:"ciphertext"`
 }
 
+// keySource supplies the data key used by an AESEncryptor.
+type keySource interface {
+	LoadKey() ([]byte, error)
+}
+
+type keySourceFunc func() ([]byte, error)
+
+func (f keySourceFunc) LoadKey() ([]byte, error) {
+	return f()
+}
+
 type AESEncryptor struct {
 	keyManager *KeyManager
+	keys       keySource
 }
 
 func NewAESEncryptor(keyManager *KeyManager) *AESEncryptor {
 	return &AESEncryptor{
 		keyManager: keyManager,
+		keys:       keyManager,
 	}
 }
 
 func (e *AESEncryptor) Encrypt(data []byte) ([]byte, error) {
-	key, err := e.keyManager.LoadKey()
+	key, err := e.keys.LoadKey()
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
+	return sealContainer(key, data)
+}
 
-	gcm, err := cipher.NewGCM(block)
+func (e *AESEncryptor) Decrypt(data []byte) ([]byte, error) {
+	key, err := e.keys.LoadKey()
 	if err != nil {
 		return nil, err
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
+	return openContainer(key, data)
+}
 
-	ciphertext := gcm.Seal(nil, nonce, data, nil)
+func (e *AESEncryptor) InitializeKey(password []byte) error {
+	return e.keyManager.InitializeKey(password)
+}
 
-	encrypted := EncryptedData{
-		Nonce:      nonce,
-		Ciphertext: ciphertext,
-	}
+func (e *AESEncryptor) Unlock(password []byte) error {
+	return e.keyManager.Unlock(password)
+}
 
-	return json.Marshal(encrypted)
+func (e *AESEncryptor) KeyExists() bool {
+	return e.keyManager.KeyExists()
 }
 
-func (e *AESEncryptor) Decrypt(data []byte) ([]byte, error) {
-	key, err := e.keyManager.LoadKey()
+func seal(key, plaintext []byte) (*EncryptedData, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
 
-	var encrypted EncryptedData
-	if err := json.Unmarshal(data, &encrypted); err != nil {
+	nonce, err := randomNonce(gcm.NonceSize())
+	if err != nil {
 		return nil, err
 	}
 
-	block, err := aes.NewCipher(key)
+	return &EncryptedData{
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+func open(key []byte, encrypted *EncryptedData) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+	if len(encrypted.Nonce) != gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
 	}
 
 	plaintext, err := gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
@@ -88,10 +107,18 @@ func (e *AESEncryptor) Decrypt(data []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
-func (e *AESEncryptor) InitializeKey() error {
-	return e.keyManager.InitializeKey()
+func randomNonce(size int) ([]byte, error) {
+	nonce := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
 }
 
-func (e *AESEncryptor) KeyExists() bool {
-	return e.keyManager.KeyExists()
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/ritarock/passvault/domain"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,8 +19,8 @@ func TestAESEncryptor_Encrypt(t *testing.T) {
 		{
 			name: "succeed: encrypt data",
 			setup: func(dir string) *KeyManager {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 				return km
 			},
 			data:   []byte("test data"),
@@ -28,7 +29,7 @@ func TestAESEncryptor_Encrypt(t *testing.T) {
 		{
 			name: "failed: key not found",
 			setup: func(dir string) *KeyManager {
-				return NewKeyManager(dir)
+				return newTestKeyManager(dir)
 			},
 			data:   []byte("test data"),
 			hasErr: true,
@@ -52,11 +53,12 @@ func TestAESEncryptor_Encrypt(t *testing.T) {
 				assert.NotNil(t, encrypted)
 				assert.NotEqual(t, test.data, encrypted)
 
-				var encData EncryptedData
-				err := json.Unmarshal(encrypted, &encData)
+				c, err := parseContainer(encrypted)
 				assert.NoError(t, err)
-				assert.NotEmpty(t, encData.Nonce)
-				assert.NotEmpty(t, encData.Ciphertext)
+				assert.Equal(t, byte(ContainerVersion), c.Version)
+				assert.Equal(t, byte(CipherAES256GCM), c.Cipher)
+				assert.NotEmpty(t, c.Nonce)
+				assert.NotEmpty(t, c.Ciphertext)
 			}
 		})
 	}
@@ -72,8 +74,8 @@ func TestAESEncryptor_Decrypt(t *testing.T) {
 		{
 			name: "succeed: decrypt data",
 			setup: func(dir string, data []byte) ([]byte, error) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 				encryptor := NewAESEncryptor(km)
 				return encryptor.Encrypt(data)
 			},
@@ -82,8 +84,8 @@ func TestAESEncryptor_Decrypt(t *testing.T) {
 		{
 			name: "failed: invalid encrypted data",
 			setup: func(dir string, data []byte) ([]byte, error) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 				return []byte("invalid data"), nil
 			},
 			hasErr: true,
@@ -91,21 +93,66 @@ func TestAESEncryptor_Decrypt(t *testing.T) {
 		{
 			name: "failed: tampered ciphertext",
 			setup: func(dir string, data []byte) ([]byte, error) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 				encryptor := NewAESEncryptor(km)
 				encrypted, err := encryptor.Encrypt(data)
 				if err != nil {
 					return nil, err
 				}
 
-				var encData EncryptedData
-				json.Unmarshal(encrypted, &encData)
-				encData.Ciphertext[0] ^= 0xFF
-				return json.Marshal(encData)
+				encrypted[len(encrypted)-1] ^= 0xFF
+				return encrypted, nil
 			},
 			hasErr: true,
 		},
+		{
+			name: "failed: tampered header",
+			setup: func(dir string, data []byte) ([]byte, error) {
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
+				encryptor := NewAESEncryptor(km)
+				encrypted, err := encryptor.Encrypt(data)
+				if err != nil {
+					return nil, err
+				}
+
+				c, _ := parseContainer(encrypted)
+				c.header[len(c.header)-1] ^= 0xFF
+				return encrypted, nil
+			},
+			hasErr: true,
+		},
+		{
+			name: "failed: unsupported version",
+			setup: func(dir string, data []byte) ([]byte, error) {
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
+				encryptor := NewAESEncryptor(km)
+				encrypted, err := encryptor.Encrypt(data)
+				if err != nil {
+					return nil, err
+				}
+
+				encrypted[len(containerMagic)] = ContainerVersion + 1
+				return encrypted, nil
+			},
+			hasErr: true,
+		},
+		{
+			name: "succeed: legacy JSON format",
+			setup: func(dir string, data []byte) ([]byte, error) {
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
+				key, _ := km.LoadKey()
+				encrypted, err := seal(key, data)
+				if err != nil {
+					return nil, err
+				}
+				return json.Marshal(encrypted)
+			},
+			hasErr: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -117,8 +164,9 @@ func TestAESEncryptor_Decrypt(t *testing.T) {
 			encrypted, err := test.setup(tmpDir, originalData)
 			assert.NoError(t, err)
 
-			km := NewKeyManager(tmpDir)
+			km := newTestKeyManager(tmpDir)
 			encryptor := NewAESEncryptor(km)
+			assert.NoError(t, encryptor.Unlock(testPassword))
 			decrypted, err := encryptor.Decrypt(encrypted)
 
 			if test.hasErr {
@@ -147,8 +195,8 @@ func TestAESEncryptor_EncryptDecrypt(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 			tmpDir := t.TempDir()
-			km := NewKeyManager(tmpDir)
-			km.InitializeKey()
+			km := newTestKeyManager(tmpDir)
+			km.InitializeKey(testPassword)
 			encryptor := NewAESEncryptor(km)
 
 			encrypted, err := encryptor.Encrypt(test.data)
@@ -162,13 +210,57 @@ func TestAESEncryptor_EncryptDecrypt(t *testing.T) {
 	}
 }
 
+func TestAESEncryptor_Unlock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		password []byte
+		wantErr  error
+	}{
+		{
+			name:     "succeed: correct password",
+			password: testPassword,
+		},
+		{
+			name:     "failed: wrong password",
+			password: []byte("wrong password"),
+			wantErr:  domain.ErrInvalidPassword,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			km.InitializeKey(testPassword)
+			encrypted, err := NewAESEncryptor(km).Encrypt([]byte("test data"))
+			assert.NoError(t, err)
+
+			encryptor := NewAESEncryptor(newTestKeyManager(tmpDir))
+			err = encryptor.Unlock(test.password)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				_, err := encryptor.Decrypt(encrypted)
+				assert.ErrorIs(t, err, ErrVaultLocked)
+			} else {
+				assert.NoError(t, err)
+				decrypted, err := encryptor.Decrypt(encrypted)
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("test data"), decrypted)
+			}
+		})
+	}
+}
+
 func TestAESEncryptor_InitializeKey(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
-	km := NewKeyManager(tmpDir)
+	km := newTestKeyManager(tmpDir)
 	encryptor := NewAESEncryptor(km)
 
-	err := encryptor.InitializeKey()
+	err := encryptor.InitializeKey(testPassword)
 	assert.NoError(t, err)
 	assert.True(t, encryptor.KeyExists())
 }
@@ -183,8 +275,8 @@ func TestAESEncryptor_KeyExists(t *testing.T) {
 		{
 			name: "succeed: key exists",
 			setup: func(dir string) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 			},
 			want: true,
 		},
@@ -201,7 +293,7 @@ func TestAESEncryptor_KeyExists(t *testing.T) {
 			tmpDir := t.TempDir()
 			test.setup(tmpDir)
 
-			km := NewKeyManager(tmpDir)
+			km := newTestKeyManager(tmpDir)
 			encryptor := NewAESEncryptor(km)
 			result := encryptor.KeyExists()
 			assert.Equal(t, test.want, result)
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	BackupDirName  = "backups"
+	backupPrefix   = "vault-"
+	backupSuffix   = ".json.enc"
+	backupIDLayout = "20060102T150405.000Z"
+)
+
+var (
+	ErrBackupNotFound      = errors.New("backup not found")
+	ErrBackupUndecryptable = errors.New("backup cannot be decrypted with the current key")
+)
+
+// RetentionPolicy decides which backups survive pruning. A backup is kept
+// if it is one of the KeepLast newest backups, the newest backup of one of
+// the KeepDaily most recent days, or the newest backup of one of the
+// KeepWeekly most recent ISO weeks. The newest backup is always kept.
+type RetentionPolicy struct {
+	KeepLast   int `json:"keep_last"`
+	KeepDaily  int `json:"keep_daily"`
+	KeepWeekly int `json:"keep_weekly"`
+}
+
+var DefaultRetentionPolicy = RetentionPolicy{
+	KeepLast:   10,
+	KeepDaily:  7,
+	KeepWeekly: 4,
+}
+
+type Backup struct {
+	ID        string
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// BackupManager keeps timestamped copies of the encrypted vault. Backups
+// are stored exactly as they were on disk, so they stay encrypted with the
+// vault key.
+type BackupManager struct {
+	dir       string
+	retention RetentionPolicy
+	fs        fileSystem
+	now       func() time.Time
+}
+
+func NewBackupManager(baseDir string, retention RetentionPolicy) *BackupManager {
+	return &BackupManager{
+		dir:       filepath.Join(baseDir, BackupDirName),
+		retention: retention,
+		fs:        osFileSystem{},
+		now:       time.Now,
+	}
+}
+
+func (m *BackupManager) SetRetention(retention RetentionPolicy) {
+	m.retention = retention
+}
+
+// Create stores encryptedData as a new backup and prunes old backups
+// according to the retention policy. Pruning errors are ignored.
+func (m *BackupManager) Create(encryptedData []byte) (*Backup, error) {
+	if err := m.fs.MkdirAll(m.dir, DirPermission); err != nil {
+		return nil, err
+	}
+
+	createdAt := m.now().UTC().Truncate(time.Millisecond)
+	path := m.path(backupID(createdAt))
+	for {
+		if _, err := m.fs.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		createdAt = createdAt.Add(time.Millisecond)
+		path = m.path(backupID(createdAt))
+	}
+
+	if err := writeFileAtomic(m.fs, path, encryptedData, VaultPermission); err != nil {
+		return nil, err
+	}
+
+	// The new backup is written, so a backup that cannot be removed must
+	// not fail the save it was taken for. Pruning is retried next time.
+	_ = m.Prune()
+
+	return &Backup{
+		ID:        backupID(createdAt),
+		Path:      path,
+		CreatedAt: createdAt,
+		Size:      int64(len(encryptedData)),
+	}, nil
+}
+
+// List returns all backups, newest first.
+func (m *BackupManager) List() ([]Backup, error) {
+	entries, err := m.fs.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		createdAt, err := time.Parse(backupIDLayout, id)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		backups = append(backups, Backup{
+			ID:        id,
+			Path:      filepath.Join(m.dir, name),
+			CreatedAt: createdAt,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// Find returns the backup whose ID equals or uniquely starts with id.
+func (m *BackupManager) Find(id string) (*Backup, error) {
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Backup
+	for i, backup := range backups {
+		if backup.ID == id {
+			return &backups[i], nil
+		}
+		if id != "" && strings.HasPrefix(backup.ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("ambiguous backup id: %s", id)
+			}
+			found = &backups[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, id)
+	}
+
+	return found, nil
+}
+
+// Read returns the encrypted contents of a backup.
+func (m *BackupManager) Read(backup *Backup) ([]byte, error) {
+	return m.fs.ReadFile(backup.Path)
+}
+
+// Prune removes the backups that the retention policy does not keep.
+func (m *BackupManager) Prune() error {
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	keep := m.retention.keep(backups)
+
+	var errs []error
+	for _, backup := range backups {
+		if keep[backup.ID] {
+			continue
+		}
+		if err := m.fs.Remove(backup.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *BackupManager) path(id string) string {
+	return filepath.Join(m.dir, backupPrefix+id+backupSuffix)
+}
+
+// keep returns the IDs of the backups to keep. backups must be sorted
+// newest first.
+func (p RetentionPolicy) keep(backups []Backup) map[string]bool {
+	keep := make(map[string]bool)
+	if len(backups) > 0 {
+		keep[backups[0].ID] = true
+	}
+
+	for i := 0; i < len(backups) && i < p.KeepLast; i++ {
+		keep[backups[i].ID] = true
+	}
+
+	keepNewestPer := func(limit int, period func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, backup := range backups {
+			if len(seen) >= limit {
+				return
+			}
+			key := period(backup.CreatedAt.Local())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keep[backup.ID] = true
+		}
+	}
+
+	keepNewestPer(p.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPer(p.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+
+	return keep
+}
+
+func backupID(t time.Time) string {
+	return t.UTC().Format(backupIDLayout)
+}
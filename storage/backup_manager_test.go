@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBackupManager(dir string, retention RetentionPolicy, times ...time.Time) *BackupManager {
+	m := NewBackupManager(dir, retention)
+	m.now = func() time.Time {
+		t := times[0]
+		if len(times) > 1 {
+			times = times[1:]
+		}
+		return t
+	}
+	return m
+}
+
+func TestBackupManager_Create(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := newTestBackupManager(tmpDir, DefaultRetentionPolicy, now, now)
+
+	first, err := m.Create([]byte("first"))
+	assert.NoError(t, err)
+	assert.Equal(t, "20261018T120000.000Z", first.ID)
+
+	second, err := m.Create([]byte("second"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	data, err := m.Read(second)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	info, err := os.Stat(second.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(VaultPermission), info.Mode().Perm())
+
+	backups, err := m.List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2)
+	assert.Equal(t, second.ID, backups[0].ID)
+}
+
+func TestBackupManager_Find(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		id      string
+		wantID  string
+		wantErr error
+		hasErr  bool
+	}{
+		{
+			name:   "succeed: exact id",
+			id:     "20261018T120000.000Z",
+			wantID: "20261018T120000.000Z",
+		},
+		{
+			name:   "succeed: unique prefix",
+			id:     "20261017",
+			wantID: "20261017T120000.000Z",
+		},
+		{
+			name:   "failed: ambiguous prefix",
+			id:     "2026",
+			hasErr: true,
+		},
+		{
+			name:    "failed: not found",
+			id:      "2025",
+			wantErr: ErrBackupNotFound,
+			hasErr:  true,
+		},
+	}
+
+	tmpDir := t.TempDir()
+	m := newTestBackupManager(tmpDir, DefaultRetentionPolicy,
+		time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	)
+	m.Create([]byte("first"))
+	m.Create([]byte("second"))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			backup, err := m.Find(test.id)
+			if test.hasErr {
+				assert.Error(t, err)
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantID, backup.ID)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicy_Keep(t *testing.T) {
+	t.Parallel()
+	day := func(d, h int) time.Time {
+		return time.Date(2026, 10, d, h, 0, 0, 0, time.Local)
+	}
+	// Newest first: three backups on the 18th, two on the 17th, one each
+	// on the 11th and the 1st.
+	times := []time.Time{day(18, 15), day(18, 12), day(18, 9), day(17, 20), day(17, 8), day(11, 10), day(1, 10)}
+	var backups []Backup
+	for _, ts := range times {
+		backups = append(backups, Backup{ID: backupID(ts), CreatedAt: ts})
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []time.Time
+	}{
+		{
+			name:   "succeed: keep last",
+			policy: RetentionPolicy{KeepLast: 2},
+			want:   []time.Time{day(18, 15), day(18, 12)},
+		},
+		{
+			name:   "succeed: keep daily",
+			policy: RetentionPolicy{KeepDaily: 3},
+			want:   []time.Time{day(18, 15), day(17, 20), day(11, 10)},
+		},
+		{
+			name:   "succeed: keep weekly",
+			policy: RetentionPolicy{KeepWeekly: 2},
+			want:   []time.Time{day(18, 15), day(11, 10)},
+		},
+		{
+			name:   "succeed: combined",
+			policy: RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 3},
+			want:   []time.Time{day(18, 15), day(17, 20), day(11, 10), day(1, 10)},
+		},
+		{
+			name:   "succeed: newest is always kept",
+			policy: RetentionPolicy{},
+			want:   []time.Time{day(18, 15)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			keep := test.policy.keep(backups)
+			assert.Len(t, keep, len(test.want))
+			for _, ts := range test.want {
+				assert.True(t, keep[backupID(ts)], "expected %s to be kept", ts)
+			}
+		})
+	}
+}
+
+func TestBackupManager_Prune(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := range 5 {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+	}
+	m := newTestBackupManager(tmpDir, RetentionPolicy{KeepLast: 3}, times...)
+
+	for range times {
+		_, err := m.Create([]byte("data"))
+		assert.NoError(t, err)
+	}
+
+	backups, err := m.List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 3)
+	assert.Equal(t, backupID(times[4]), backups[0].ID)
+	assert.Equal(t, backupID(times[2]), backups[2].ID)
+}
+
+func TestBackupManager_CreateIgnoresPruneErrors(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := newTestBackupManager(tmpDir, RetentionPolicy{KeepLast: 1}, start, start.Add(time.Minute))
+	m.fs = &faultyFileSystem{failRemove: true}
+
+	_, err := m.Create([]byte("first"))
+	assert.NoError(t, err)
+	_, err = m.Create([]byte("second"))
+	assert.NoError(t, err)
+
+	backups, err := m.List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2, "the backup that cannot be removed is kept")
+	assert.Error(t, m.Prune())
+}
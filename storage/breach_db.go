@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hibpPrefixLength is the length of the hash prefix that names a range file
+// of the Pwned Passwords k-anonymity API.
+const hibpPrefixLength = 5
+
+var ErrInvalidBreachDB = errors.New("invalid breach database")
+
+// BreachDB looks passwords up in a local copy of the Pwned Passwords SHA-1
+// hashes. Passwords never leave the machine.
+//
+// The copy is either a single file with one HASH:COUNT line per hash,
+// sorted by hash, or a directory of range files as served by the range
+// API: each file is named after a five character hash prefix, optionally
+// with a .txt extension, and holds SUFFIX:COUNT lines.
+type BreachDB struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// OpenBreachDB opens the hash file or range directory at path.
+func OpenBreachDB(path string) (*BreachDB, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach database: %w", err)
+	}
+	if info.IsDir() {
+		return &BreachDB{path: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach database: %w", err)
+	}
+	return &BreachDB{path: path, file: file, size: info.Size()}, nil
+}
+
+func (db *BreachDB) Close() error {
+	if db.file == nil {
+		return nil
+	}
+	return db.file.Close()
+}
+
+// Breached returns how often password appears in the database.
+func (db *BreachDB) Breached(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if db.file == nil {
+		return db.lookupRange(hash)
+	}
+	return db.lookupSorted(hash)
+}
+
+// lookupSorted binary searches the sorted hash file. lo always points at
+// the start of a line, and a matching line has to start before hi.
+func (db *BreachDB) lookupSorted(hash string) (int, error) {
+	lo, hi := int64(0), db.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := db.lineAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start < 0 || start >= hi {
+			hi = mid
+			continue
+		}
+
+		lineHash, count, err := parseBreachLine(line)
+		if err != nil {
+			return 0, err
+		}
+		switch strings.Compare(lineHash, hash) {
+		case 0:
+			return count, nil
+		case -1:
+			lo = start + int64(len(line))
+		default:
+			hi = start
+		}
+	}
+	return 0, nil
+}
+
+// lineAfter returns the first line that starts at or after offset,
+// including its line break, and where it starts. start is -1 if there is
+// no such line.
+func (db *BreachDB) lineAfter(offset int64) (int64, []byte, error) {
+	start := offset
+	if offset > 0 {
+		// Skip the rest of the line offset falls into, unless offset is
+		// right after a line break.
+		start = offset - 1
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(db.file, start, db.size-start))
+	if offset > 0 {
+		skipped, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return -1, nil, nil
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read breach database: %w", err)
+		}
+		start += int64(len(skipped))
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return 0, nil, fmt.Errorf("failed to read breach database: %w", err)
+	}
+	if len(line) == 0 {
+		return -1, nil, nil
+	}
+	return start, line, nil
+}
+
+// lookupRange searches the range file for the prefix of hash.
+func (db *BreachDB) lookupRange(hash string) (int, error) {
+	prefix, suffix := hash[:hibpPrefixLength], hash[hibpPrefixLength:]
+
+	var data []byte
+	var err error
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		data, err = os.ReadFile(filepath.Join(db.path, name))
+		if !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		// A missing range file means the copy is incomplete, not that the
+		// password is safe.
+		return 0, fmt.Errorf("%w: range file %s is missing", ErrInvalidBreachDB, prefix)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read breach database: %w", err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\r\n"), []byte("\n"))
+	i := sort.Search(len(lines), func(i int) bool {
+		lineSuffix, _, _ := parseBreachLine(lines[i])
+		return lineSuffix >= suffix
+	})
+	if i == len(lines) {
+		return 0, nil
+	}
+	lineSuffix, count, err := parseBreachLine(lines[i])
+	if err != nil || lineSuffix != suffix {
+		return 0, err
+	}
+	return count, nil
+}
+
+// parseBreachLine splits a HASH:COUNT line. A line without a count is
+// counted once.
+func parseBreachLine(line []byte) (string, int, error) {
+	text := strings.TrimRight(string(line), "\r\n")
+	hash, countText, found := strings.Cut(text, ":")
+	if !found {
+		return strings.ToUpper(hash), 1, nil
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidBreachDB, text)
+	}
+	return strings.ToUpper(hash), count, nil
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+var breachedPasswords = map[string]int{
+	"password":  9545824,
+	"123456":    37359195,
+	"letmein":   285041,
+	"qwerty":    10556095,
+	"iloveyou":  2338215,
+	"trustno1":  153059,
+	"sunshine":  481324,
+	"monkey":    1177793,
+	"dragon":    1122009,
+	"baseball1": 101434,
+}
+
+// writeSortedBreachFile writes the hashes sorted by hash, with filler
+// hashes in between, and CRLF line breaks like the published file.
+func writeSortedBreachFile(t *testing.T) string {
+	t.Helper()
+	var lines []string
+	for password, count := range breachedPasswords {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(password), count))
+	}
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(fmt.Sprintf("filler-%d", i)), i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeBreachRanges writes range files for the prefixes of the breached
+// passwords and of "not-breached".
+func writeBreachRanges(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	ranges := map[string][]string{}
+	for password, count := range breachedPasswords {
+		hash := sha1Hex(password)
+		ranges[hash[:5]] = append(ranges[hash[:5]], fmt.Sprintf("%s:%d", hash[5:], count))
+	}
+	ranges[sha1Hex("not-breached")[:5]] = append(ranges[sha1Hex("not-breached")[:5]], "0000000000000000000000000000000000F:1")
+
+	for prefix, lines := range ranges {
+		sort.Strings(lines)
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestBreachDB_Breached(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		setup    func(*testing.T) string
+		password string
+		want     int
+		hasErr   bool
+	}{
+		{
+			name:     "succeed: sorted file hit",
+			setup:    writeSortedBreachFile,
+			password: "letmein",
+			want:     breachedPasswords["letmein"],
+		},
+		{
+			name:     "succeed: sorted file miss",
+			setup:    writeSortedBreachFile,
+			password: "not-breached",
+			want:     0,
+		},
+		{
+			name:     "succeed: range directory hit",
+			setup:    writeBreachRanges,
+			password: "trustno1",
+			want:     breachedPasswords["trustno1"],
+		},
+		{
+			name:     "succeed: range directory miss",
+			setup:    writeBreachRanges,
+			password: "not-breached",
+			want:     0,
+		},
+		{
+			name:     "failed: range file missing",
+			setup:    func(t *testing.T) string { return t.TempDir() },
+			password: "password",
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			db, err := OpenBreachDB(test.setup(t))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			got, err := db.Breached(test.password)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBreachDB_BreachedFindsEveryLine(t *testing.T) {
+	t.Parallel()
+	db, err := OpenBreachDB(writeSortedBreachFile(t))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	for password, count := range breachedPasswords {
+		got, err := db.Breached(password)
+		assert.NoError(t, err)
+		assert.Equal(t, count, got, password)
+	}
+	for i := 0; i < 200; i++ {
+		got, err := db.Breached(fmt.Sprintf("filler-%d", i))
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, got)
+	}
+}
+
+func TestOpenBreachDB_Missing(t *testing.T) {
+	t.Parallel()
+	_, err := OpenBreachDB(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
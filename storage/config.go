@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+const ConfigFileName = "config.json"
+
+// Config holds user settings read from config.json in the passvault
+// directory. Settings missing from the file keep their defaults.
+type Config struct {
+	Backup    RetentionPolicy `json:"backup"`
+	Trash     TrashConfig     `json:"trash"`
+	Audit     AuditConfig     `json:"audit"`
+	Generator GeneratorConfig `json:"generator"`
+}
+
+type TrashConfig struct {
+	// PurgeAfterDays is how long deleted entries stay in the trash before
+	// they are purged at startup. Zero keeps them until purged by hand.
+	PurgeAfterDays int `json:"purge_after_days"`
+}
+
+// PurgeAfter returns the trash retention as a duration.
+func (c TrashConfig) PurgeAfter() time.Duration {
+	return time.Duration(c.PurgeAfterDays) * 24 * time.Hour
+}
+
+type AuditConfig struct {
+	// MaxPasswordAgeDays reports passwords older than this. Zero disables
+	// the check.
+	MaxPasswordAgeDays int `json:"max_password_age_days"`
+	// MinScore reports passwords with a lower strength score, from 0 to 4.
+	MinScore int `json:"min_score"`
+	// BreachDB is the path of a local Pwned Passwords copy, see
+	// OpenBreachDB. Empty disables the breach check.
+	BreachDB string `json:"breach_db,omitempty"`
+}
+
+// Options returns the audit settings for the vault audit. The breach
+// database is opened by the caller.
+func (c AuditConfig) Options() domain.AuditOptions {
+	return domain.AuditOptions{
+		MaxPasswordAge: time.Duration(c.MaxPasswordAgeDays) * 24 * time.Hour,
+		MinScore:       c.MinScore,
+	}
+}
+
+type GeneratorConfig struct {
+	// Wordlist is the path of a passphrase wordlist replacing the built-in
+	// EFF large wordlist.
+	Wordlist string `json:"wordlist,omitempty"`
+}
+
+// PasswordGenerator returns a generator using the configured wordlist.
+func (c GeneratorConfig) PasswordGenerator() (*domain.PasswordGenerator, error) {
+	if c.Wordlist == "" {
+		return domain.NewPasswordGenerator(), nil
+	}
+
+	data, err := os.ReadFile(c.Wordlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+	words, err := domain.ParseWordlist(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPassphraseGenerator(words), nil
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Backup: DefaultRetentionPolicy,
+		Trash:  TrashConfig{PurgeAfterDays: 30},
+		Audit:  AuditConfig{MaxPasswordAgeDays: 365, MinScore: 3},
+	}
+}
+
+// LoadConfig reads the config file in baseDir. A missing file yields the
+// default configuration.
+func LoadConfig(baseDir string) (*Config, error) {
+	config := DefaultConfig()
+
+	data, err := os.ReadFile(filepath.Join(baseDir, ConfigFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ConfigFileName, err)
+	}
+	if config.Trash.PurgeAfterDays < 0 {
+		return nil, fmt.Errorf("invalid %s: trash.purge_after_days must not be negative", ConfigFileName)
+	}
+	if config.Audit.MaxPasswordAgeDays < 0 {
+		return nil, fmt.Errorf("invalid %s: audit.max_password_age_days must not be negative", ConfigFileName)
+	}
+	if config.Audit.MinScore < 0 || config.Audit.MinScore > 4 {
+		return nil, fmt.Errorf("invalid %s: audit.min_score must be between 0 and 4", ConfigFileName)
+	}
+
+	return config, nil
+}
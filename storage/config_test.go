@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		data   string
+		want   *Config
+		hasErr bool
+	}{
+		{
+			name: "succeed: missing file uses defaults",
+			want: DefaultConfig(),
+		},
+		{
+			name: "succeed: partial file keeps other defaults",
+			data: `{"backup": {"keep_last": 3}}`,
+			want: &Config{
+				Backup: RetentionPolicy{
+					KeepLast:   3,
+					KeepDaily:  DefaultRetentionPolicy.KeepDaily,
+					KeepWeekly: DefaultRetentionPolicy.KeepWeekly,
+				},
+				Trash: DefaultConfig().Trash,
+				Audit: DefaultConfig().Audit,
+			},
+		},
+		{
+			name: "succeed: trash purge disabled",
+			data: `{"trash": {"purge_after_days": 0}}`,
+			want: &Config{
+				Backup: DefaultRetentionPolicy,
+				Trash:  TrashConfig{PurgeAfterDays: 0},
+				Audit:  DefaultConfig().Audit,
+			},
+		},
+		{
+			name: "succeed: audit settings",
+			data: `{"audit": {"max_password_age_days": 90, "min_score": 4, "breach_db": "/data/pwned.txt"}}`,
+			want: &Config{
+				Backup: DefaultRetentionPolicy,
+				Trash:  DefaultConfig().Trash,
+				Audit:  AuditConfig{MaxPasswordAgeDays: 90, MinScore: 4, BreachDB: "/data/pwned.txt"},
+			},
+		},
+		{
+			name:   "failed: negative password age",
+			data:   `{"audit": {"max_password_age_days": -1}}`,
+			hasErr: true,
+		},
+		{
+			name:   "failed: score out of range",
+			data:   `{"audit": {"min_score": 5}}`,
+			hasErr: true,
+		},
+		{
+			name:   "failed: negative trash age",
+			data:   `{"trash": {"purge_after_days": -1}}`,
+			hasErr: true,
+		},
+		{
+			name:   "failed: invalid json",
+			data:   `{"backup":`,
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			if test.data != "" {
+				os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(test.data), 0600)
+			}
+
+			config, err := LoadConfig(tmpDir)
+			if test.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, config)
+			}
+		})
+	}
+}
+
+func TestGeneratorConfig_PasswordGenerator(t *testing.T) {
+	t.Parallel()
+
+	words := make([]string, domain.MinWordlistSize)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%d", i)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		hasErr bool
+	}{
+		{
+			name: "succeed: custom wordlist",
+			data: strings.Join(words, "\n"),
+		},
+		{
+			name:   "failed: wordlist too small",
+			data:   "one\ntwo\nthree",
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "words.txt")
+			os.WriteFile(path, []byte(test.data), 0600)
+
+			pg, err := GeneratorConfig{Wordlist: path}.PasswordGenerator()
+			if test.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			got, err := pg.GenerateWithOptions(domain.PasswordOptions{
+				Mode:       domain.PasswordModePassphrase,
+				Passphrase: domain.PassphraseOptions{Words: 3, Separator: " "},
+			})
+			assert.NoError(t, err)
+			for _, word := range strings.Fields(got) {
+				assert.Contains(t, words, word)
+			}
+		})
+	}
+}
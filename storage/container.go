@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Vault files start with a small binary header followed by the GCM
+// ciphertext:
+//
+//	magic      4 bytes  "PVLT"
+//	version    1 byte   ContainerVersion
+//	cipher     1 byte   CipherAES256GCM
+//	nonce len  1 byte
+//	nonce      n bytes
+//	ciphertext rest
+//
+// The whole header is passed to GCM as additional data, so changing any
+// header byte makes decryption fail. Key derivation parameters are not part
+// of the container; they are stored per key slot in key.json.
+//
+// Files without the magic are version 1: bare JSON EncryptedData without
+// additional data. They are still readable and are rewritten in the current
+// format on the next save.
+const (
+	ContainerVersion       = 2
+	LegacyContainerVersion = 1
+	CipherAES256GCM        = 1
+)
+
+var containerMagic = []byte("PVLT")
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported vault format")
+)
+
+type container struct {
+	Version    byte
+	Cipher     byte
+	Nonce      []byte
+	Ciphertext []byte
+	header     []byte
+}
+
+// sealContainer encrypts plaintext into the current container format.
+func sealContainer(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomNonce(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(containerMagic)+3+len(nonce))
+	header = append(header, containerMagic...)
+	header = append(header, ContainerVersion, CipherAES256GCM, byte(len(nonce)))
+	header = append(header, nonce...)
+
+	// The ciphertext is appended to a copy, as Seal must not write over the
+	// additional data it is still reading.
+	return gcm.Seal(append([]byte(nil), header...), nonce, plaintext, header), nil
+}
+
+// openContainer decrypts data in the current or a legacy container format.
+func openContainer(key, data []byte) ([]byte, error) {
+	c, err := parseContainer(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Version == LegacyContainerVersion {
+		return open(key, &EncryptedData{Nonce: c.Nonce, Ciphertext: c.Ciphertext})
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Nonce) != gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+
+	plaintext, err := gcm.Open(nil, c.Nonce, c.Ciphertext, c.header)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return plaintext, nil
+}
+
+func parseContainer(data []byte) (*container, error) {
+	if !bytes.HasPrefix(data, containerMagic) {
+		return parseLegacyContainer(data)
+	}
+
+	rest := data[len(containerMagic):]
+	if len(rest) < 3 {
+		return nil, fmt.Errorf("%w: truncated header", ErrUnsupportedFormat)
+	}
+
+	version, cipherID, nonceLen := rest[0], rest[1], int(rest[2])
+	if version != ContainerVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedFormat, version)
+	}
+	if cipherID != CipherAES256GCM {
+		return nil, fmt.Errorf("%w: cipher %d", ErrUnsupportedFormat, cipherID)
+	}
+
+	rest = rest[3:]
+	if len(rest) < nonceLen {
+		return nil, fmt.Errorf("%w: truncated header", ErrUnsupportedFormat)
+	}
+
+	headerLen := len(containerMagic) + 3 + nonceLen
+	return &container{
+		Version:    version,
+		Cipher:     cipherID,
+		Nonce:      rest[:nonceLen],
+		Ciphertext: rest[nonceLen:],
+		header:     data[:headerLen],
+	}, nil
+}
+
+func parseLegacyContainer(data []byte) (*container, error) {
+	var encrypted EncryptedData
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	return &container{
+		Version:    LegacyContainerVersion,
+		Cipher:     CipherAES256GCM,
+		Nonce:      encrypted.Nonce,
+		Ciphertext: encrypted.Ciphertext,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainer(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	current, _ := sealContainer(key, []byte("test data"))
+	legacy, _ := seal(key, []byte("test data"))
+	legacyData, _ := json.Marshal(legacy)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantVersion byte
+		hasErr      bool
+	}{
+		{name: "succeed: current format", data: current, wantVersion: ContainerVersion},
+		{name: "succeed: legacy format", data: legacyData, wantVersion: LegacyContainerVersion},
+		{name: "failed: truncated header", data: []byte("PVLT\x02"), hasErr: true},
+		{name: "failed: truncated nonce", data: []byte("PVLT\x02\x01\x0cabc"), hasErr: true},
+		{name: "failed: unknown cipher", data: []byte("PVLT\x02\x09\x00"), hasErr: true},
+		{name: "failed: garbage", data: []byte("not a vault"), hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := parseContainer(test.data)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrUnsupportedFormat)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantVersion, c.Version)
+
+			plaintext, err := openContainer(key, test.data)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("test data"), plaintext)
+		})
+	}
+}
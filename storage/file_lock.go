@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LockFileName       = "vault.lock"
+	DefaultLockTimeout = 3 * time.Second
+	lockRetryInterval  = 50 * time.Millisecond
+)
+
+// errLockBusy is returned by tryLockFile when another process holds the
+// lock.
+var errLockBusy = errors.New("lock is held by another process")
+
+// LockedError reports that another passvault process holds the vault lock.
+type LockedError struct {
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("vault is locked by PID %d", e.PID)
+	}
+	return "vault is locked by another process"
+}
+
+// fileLock is an advisory inter-process lock backed by a lock file. The
+// holder writes its PID into the file so that waiters can report it.
+type fileLock struct {
+	f *os.File
+}
+
+func acquireFileLock(path string, timeout time.Duration) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, KeyPermission)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockBusy) {
+			f.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			pid := readLockPID(f)
+			f.Close()
+			return nil, &LockedError{PID: pid}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+
+	return &fileLock{f: f}, nil
+}
+
+func (l *fileLock) release() error {
+	l.f.Truncate(0)
+	if err := unlockFile(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+func readLockPID(f *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
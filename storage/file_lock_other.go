@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package storage
+
+import "os"
+
+// Platforms without flock or LockFileEx rely on the vault revision check
+// alone to detect concurrent writers.
+func tryLockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
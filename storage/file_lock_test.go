@@ -0,0 +1,115 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileVaultRepository_Lock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		held   bool
+		hasErr bool
+	}{
+		{
+			name:   "succeed: lock is free",
+			held:   false,
+			hasErr: false,
+		},
+		{
+			name:   "failed: lock is held by another repository",
+			held:   true,
+			hasErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			holder := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+			repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+			repo.lockTimeout = 100 * time.Millisecond
+
+			if test.held {
+				unlock, err := holder.Lock()
+				assert.NoError(t, err)
+				defer unlock()
+			}
+
+			unlock, err := repo.Lock()
+
+			if test.hasErr {
+				var lockedErr *LockedError
+				assert.ErrorAs(t, err, &lockedErr)
+				assert.Equal(t, os.Getpid(), lockedErr.PID)
+				assert.Contains(t, err.Error(), "vault is locked by PID")
+			} else {
+				assert.NoError(t, err)
+				unlock()
+			}
+		})
+	}
+}
+
+func TestFileVaultRepository_LockReleased(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	first := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+	second := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+	second.lockTimeout = 2 * time.Second
+
+	unlock, err := first.Lock()
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		unlock()
+	}()
+
+	unlockSecond, err := second.Lock()
+	assert.NoError(t, err)
+	unlockSecond()
+
+	_, err = os.Stat(filepath.Join(tmpDir, LockFileName))
+	assert.NoError(t, err)
+}
+
+func TestFileVaultRepository_SaveDetectsConflict(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	first := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+	second := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+	assert.NoError(t, first.Save(domain.NewVault()))
+
+	a, err := first.Load()
+	assert.NoError(t, err)
+	b, err := second.Load()
+	assert.NoError(t, err)
+
+	a.CreateEntry(domain.Entry{ID: "test-id-1", Title: "first"})
+	assert.NoError(t, first.Save(a))
+	assert.Equal(t, uint64(2), a.Revision)
+
+	b.CreateEntry(domain.Entry{ID: "test-id-2", Title: "second"})
+	err = second.Save(b)
+	assert.ErrorIs(t, err, domain.ErrVaultConflict)
+	assert.Equal(t, uint64(1), b.Revision)
+
+	loaded, err := second.Load()
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Entries, 1)
+	assert.Contains(t, loaded.Entries, "test-id-1")
+
+	loaded.CreateEntry(domain.Entry{ID: "test-id-2", Title: "second"})
+	assert.NoError(t, second.Save(loaded))
+	assert.Equal(t, uint64(3), loaded.Revision)
+}
@@ -1,10 +1,15 @@
 package storage
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ritarock/passvault/domain"
 )
@@ -20,19 +25,71 @@ var (
 )
 
 type FileVaultRepository struct {
-	vaultPath string
-	cryptoSvc domain.CryptoService
+	vaultPath   string
+	lockPath    string
+	lockTimeout time.Duration
+	cryptoSvc   domain.CryptoService
+	migrations  *MigrationRegistry
+	backups     *BackupManager
+	fs          fileSystem
+
+	// mu guards the digest, revision and content digest of the vault file
+	// as last read or written by this repository.
+	mu           sync.Mutex
+	lastDigest   [sha256.Size]byte
+	lastRevision uint64
+	lastContent  [sha256.Size]byte
 }
 
 func NewFileVaultRepository(baseDir string, cryptoSvc domain.CryptoService) *FileVaultRepository {
 	return &FileVaultRepository{
-		vaultPath: filepath.Join(baseDir, VaultFileName),
-		cryptoSvc: cryptoSvc,
+		vaultPath:   filepath.Join(baseDir, VaultFileName),
+		lockPath:    filepath.Join(baseDir, LockFileName),
+		lockTimeout: DefaultLockTimeout,
+		cryptoSvc:   cryptoSvc,
+		migrations:  DefaultMigrationRegistry(),
+		backups:     NewBackupManager(baseDir, DefaultRetentionPolicy),
+		fs:          osFileSystem{},
+	}
+}
+
+// withCryptoService returns a repository that reads and writes the same
+// files with a different crypto service.
+func (r *FileVaultRepository) withCryptoService(cryptoSvc domain.CryptoService) *FileVaultRepository {
+	return &FileVaultRepository{
+		vaultPath:   r.vaultPath,
+		lockPath:    r.lockPath,
+		lockTimeout: r.lockTimeout,
+		cryptoSvc:   cryptoSvc,
+		migrations:  r.migrations,
+		backups:     r.backups,
+		fs:          r.fs,
+	}
+}
+
+// Backups returns the manager of the rolling backups taken before each save.
+func (r *FileVaultRepository) Backups() *BackupManager {
+	return r.backups
+}
+
+// Lock takes an exclusive advisory lock on the vault directory, waiting up
+// to the lock timeout. A *LockedError names the process holding it.
+func (r *FileVaultRepository) Lock() (func(), error) {
+	dir := filepath.Dir(r.lockPath)
+	if err := r.fs.MkdirAll(dir, DirPermission); err != nil {
+		return nil, err
+	}
+
+	lock, err := acquireFileLock(r.lockPath, r.lockTimeout)
+	if err != nil {
+		return nil, err
 	}
+
+	return func() { lock.release() }, nil
 }
 
 func (r *FileVaultRepository) Exists() bool {
-	_, err := os.Stat(r.vaultPath)
+	_, err := r.fs.Stat(r.vaultPath)
 	return err == nil
 }
 
@@ -41,39 +98,356 @@ func (r *FileVaultRepository) Load() (*domain.Vault, error) {
 		return nil, ErrVaultNotFound
 	}
 
-	encryptedData, err := os.ReadFile(r.vaultPath)
+	encryptedData, err := r.fs.ReadFile(r.vaultPath)
 	if err != nil {
 		return nil, err
 	}
 
-	decryptedData, err := r.cryptoSvc.Decrypt(encryptedData)
+	vault, err := r.decode(encryptedData)
 	if err != nil {
 		return nil, err
 	}
 
-	var vault domain.Vault
-	if err := json.Unmarshal(decryptedData, &vault); err != nil {
+	content, err := contentDigest(vault)
+	if err != nil {
 		return nil, err
 	}
 
-	return &vault, nil
+	r.remember(encryptedData, vault.Revision, content)
+	return vault, nil
 }
 
+// Save encrypts the vault and atomically replaces the vault file, so a crash
+// or a full disk never leaves a truncated vault behind. The previous vault
+// file is kept as a backup, unless the save only records that entries were
+// viewed. It returns domain.ErrVaultConflict if the file was saved with a
+// newer revision since the vault was loaded.
 func (r *FileVaultRepository) Save(vault *domain.Vault) error {
 	dir := filepath.Dir(r.vaultPath)
-	if err := os.MkdirAll(dir, DirPermission); err != nil {
+	if err := r.fs.MkdirAll(dir, DirPermission); err != nil {
 		return err
 	}
 
-	jsonData, err := json.MarshalIndent(vault, "", "  ")
+	previous, err := r.readCurrent()
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		if err := r.checkRevision(previous, vault.Revision); err != nil {
+			return err
+		}
+	}
+
+	return r.write(vault, previous)
+}
+
+// Restore replaces the vault with a backup. The backup must decrypt with
+// the current key; the vault it replaces is backed up first.
+func (r *FileVaultRepository) Restore(id string) (*Backup, error) {
+	backup, err := r.backups.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData, err := r.backups.Read(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	vault, err := r.decode(encryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupUndecryptable, err)
+	}
+
+	previous, err := r.readCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	// Continue from the revision on disk so that other processes holding
+	// the replaced vault see their next save as a conflict.
+	if previous != nil {
+		if current, err := r.decode(previous); err == nil && current.Revision > vault.Revision {
+			vault.Revision = current.Revision
+		}
+	}
+
+	if err := r.write(vault, previous); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// readCurrent returns the vault file as it is on disk, or nil if there is
+// none yet.
+func (r *FileVaultRepository) readCurrent() ([]byte, error) {
+	encryptedData, err := r.fs.ReadFile(r.vaultPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return encryptedData, err
+}
+
+// write backs up previous, then saves vault with the next revision.
+// Viewing entries would otherwise push the backups taken before real
+// changes out of the retention policy.
+func (r *FileVaultRepository) write(vault *domain.Vault, previous []byte) error {
+	content, err := contentDigest(vault)
 	if err != nil {
 		return err
 	}
 
-	encryptedData, err := r.cryptoSvc.Encrypt(jsonData)
+	if previous != nil && !r.sameContent(previous, content) {
+		if _, err := r.backups.Create(previous); err != nil {
+			return fmt.Errorf("failed to back up vault: %w", err)
+		}
+	}
+
+	vault.Revision++
+	encryptedData, err := r.encode(vault)
+	if err != nil {
+		vault.Revision--
+		return err
+	}
+
+	if err := writeFileAtomic(r.fs, r.vaultPath, encryptedData, VaultPermission); err != nil {
+		if !errors.Is(err, errDirNotSynced) {
+			vault.Revision--
+			return err
+		}
+		// The new vault is in place, so its revision is the one to check
+		// the next save against.
+		r.remember(encryptedData, vault.Revision, content)
+		return err
+	}
+
+	r.remember(encryptedData, vault.Revision, content)
+	return nil
+}
+
+// checkRevision compares the revision of the vault file on disk with the
+// revision the caller loaded. The file is only decrypted when it changed
+// since this repository last read or wrote it.
+func (r *FileVaultRepository) checkRevision(encryptedData []byte, revision uint64) error {
+	diskRevision, ok := r.knownRevision(encryptedData)
+	if !ok {
+		current, err := r.decode(encryptedData)
+		if err != nil {
+			return fmt.Errorf("failed to read current vault: %w", err)
+		}
+		diskRevision = current.Revision
+	}
+
+	if diskRevision != revision {
+		return fmt.Errorf("%w: revision %d on disk, %d loaded", domain.ErrVaultConflict, diskRevision, revision)
+	}
+
+	return nil
+}
+
+func (r *FileVaultRepository) remember(encryptedData []byte, revision uint64, content [sha256.Size]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastDigest = sha256.Sum256(encryptedData)
+	r.lastRevision = revision
+	r.lastContent = content
+}
+
+func (r *FileVaultRepository) knownRevision(encryptedData []byte) (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lastDigest != sha256.Sum256(encryptedData) {
+		return 0, false
+	}
+	return r.lastRevision, true
+}
+
+// sameContent reports whether encryptedData is the vault file this
+// repository last read or wrote and its content equals content. An unknown
+// file is never the same, so it is backed up.
+func (r *FileVaultRepository) sameContent(encryptedData []byte, content [sha256.Size]byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastDigest == sha256.Sum256(encryptedData) && r.lastContent == content
+}
+
+// contentDigest hashes the vault without its revision and the times its
+// entries were last viewed, which change without the user editing anything.
+func contentDigest(vault *domain.Vault) ([sha256.Size]byte, error) {
+	content := *vault
+	content.Revision = 0
+	content.Entries = make(map[string]*domain.Entry, len(vault.Entries))
+	for id, en := range vault.Entries {
+		e := *en
+		e.LastViewedAt = time.Time{}
+		content.Entries[id] = &e
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+func (r *FileVaultRepository) encode(vault *domain.Vault) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(vault, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.cryptoSvc.Encrypt(jsonData)
+}
+
+func (r *FileVaultRepository) decode(encryptedData []byte) (*domain.Vault, error) {
+	decryptedData, err := r.cryptoSvc.Decrypt(encryptedData)
+	if err != nil {
+		return nil, err
+	}
+
+	decryptedData, err = r.migrate(encryptedData, decryptedData)
+	if err != nil {
+		return nil, err
+	}
+
+	var vault domain.Vault
+	if err := json.Unmarshal(decryptedData, &vault); err != nil {
+		return nil, err
+	}
+
+	return &vault, nil
+}
+
+// migrate upgrades an older vault document to the current schema. The
+// encrypted original is kept next to the vault before anything is changed.
+func (r *FileVaultRepository) migrate(encryptedData, decryptedData []byte) ([]byte, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(decryptedData, &header); err != nil {
+		return nil, err
+	}
+
+	needed, err := r.migrations.NeedsMigration(header.Version)
+	if err != nil || !needed {
+		return decryptedData, err
+	}
+
+	if err := r.backupBeforeMigration(encryptedData, normalizeVersion(header.Version)); err != nil {
+		return nil, fmt.Errorf("failed to back up vault before migration: %w", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(decryptedData, &doc); err != nil {
+		return nil, err
+	}
+
+	if err := r.migrations.Migrate(doc); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+// backupBeforeMigration keeps one copy of the vault per schema version it
+// was migrated from. An existing backup is never overwritten.
+func (r *FileVaultRepository) backupBeforeMigration(encryptedData []byte, version string) error {
+	path := r.migrationBackupPath(version)
+	if _, err := r.fs.Stat(path); err == nil {
+		return nil
+	}
+
+	return writeFileAtomic(r.fs, path, encryptedData, VaultPermission)
+}
+
+func (r *FileVaultRepository) migrationBackupPath(version string) string {
+	return fmt.Sprintf("%s.v%s.bak", r.vaultPath, version)
+}
+
+// ReencryptBackups re-encrypts the backups, including the ones kept before
+// migrations, from the key of previous to the current key, so they stay
+// readable after the key they were written with is gone.
+func (r *FileVaultRepository) ReencryptBackups(previous domain.CryptoService) error {
+	paths, err := r.backupPaths()
 	if err != nil {
 		return err
 	}
+	return r.reencryptFiles(paths, previous, r.cryptoSvc)
+}
+
+// backupPaths returns the paths of the rolling backups and of the backups
+// kept before migrations.
+func (r *FileVaultRepository) backupPaths() ([]string, error) {
+	backups, err := r.backups.List()
+	if err != nil {
+		return nil, err
+	}
 
-	return os.WriteFile(r.vaultPath, encryptedData, VaultPermission)
+	paths, err := r.migrationBackups()
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range backups {
+		paths = append(paths, backup.Path)
+	}
+	return paths, nil
+}
+
+// reencryptFiles rewrites the encrypted files at paths from the key of from
+// to the key of to. Files that to already decrypts are left as they are, so
+// an interrupted run can be repeated. A file that neither decrypts is
+// reported rather than skipped, as it would be lost with the old key.
+func (r *FileVaultRepository) reencryptFiles(paths []string, from, to domain.CryptoService) error {
+	var errs []error
+	for _, path := range paths {
+		encryptedData, err := r.fs.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		plaintext, err := from.Decrypt(encryptedData)
+		if err != nil {
+			if _, err := to.Decrypt(encryptedData); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrBackupUndecryptable, filepath.Base(path)))
+			}
+			continue
+		}
+
+		reencrypted, err := to.Encrypt(plaintext)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := writeFileAtomic(r.fs, path, reencrypted, VaultPermission); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// migrationBackups returns the paths of the backups kept before migrations.
+func (r *FileVaultRepository) migrationBackups() ([]string, error) {
+	dir := filepath.Dir(r.vaultPath)
+	entries, err := r.fs.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(r.vaultPath) + ".v"
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".bak") {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths, nil
 }
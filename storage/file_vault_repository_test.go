@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ritarock/passvault/domain"
@@ -27,7 +30,11 @@ func (m *mockCryptoService) Decrypt(data []byte) ([]byte, error) {
 	return data, nil
 }
 
-func (m *mockCryptoService) InitializeKey() error {
+func (m *mockCryptoService) InitializeKey(password []byte) error {
+	return nil
+}
+
+func (m *mockCryptoService) Unlock(password []byte) error {
 	return nil
 }
 
@@ -233,8 +240,8 @@ func TestFileVaultRepository_SaveAndLoad(t *testing.T) {
 			t.Parallel()
 			tmpDir := t.TempDir()
 
-			km := NewKeyManager(tmpDir)
-			km.InitializeKey()
+			km := newTestKeyManager(tmpDir)
+			km.InitializeKey(testPassword)
 			encryptor := NewAESEncryptor(km)
 			repo := NewFileVaultRepository(tmpDir, encryptor)
 
@@ -259,3 +266,305 @@ func TestFileVaultRepository_SaveAndLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestFileVaultRepository_UpgradesLegacyFormatOnSave(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	km := newTestKeyManager(tmpDir)
+	km.InitializeKey(testPassword)
+	key, _ := km.LoadKey()
+
+	vault := domain.NewVault()
+	vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "test title"})
+	plaintext, _ := json.Marshal(vault)
+	legacy, _ := seal(key, plaintext)
+	legacyData, _ := json.Marshal(legacy)
+	os.WriteFile(filepath.Join(tmpDir, VaultFileName), legacyData, VaultPermission)
+
+	repo := NewFileVaultRepository(tmpDir, NewAESEncryptor(km))
+	loaded, err := repo.Load()
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Entries, 1)
+
+	assert.NoError(t, repo.Save(loaded))
+	data, _ := os.ReadFile(filepath.Join(tmpDir, VaultFileName))
+	c, err := parseContainer(data)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(ContainerVersion), c.Version)
+
+	reloaded, err := repo.Load()
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.Entries, 1)
+}
+
+func TestFileVaultRepository_LoadMigratesOlderVault(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		version     string
+		wantVersion string
+		wantBackup  bool
+		wantErr     error
+	}{
+		{
+			name:        "succeed: migrate older vault",
+			version:     "1.0",
+			wantVersion: "1.2",
+			wantBackup:  true,
+		},
+		{
+			name:        "succeed: current vault is untouched",
+			version:     "1.2",
+			wantVersion: "1.2",
+			wantBackup:  false,
+		},
+		{
+			name:    "failed: newer vault is refused",
+			version: "1.3",
+			wantErr: ErrVaultTooNew,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+			repo.migrations = NewMigrationRegistry("1.2", testMigrations()...)
+
+			original := []byte(`{"version":"` + test.version + `","entries":{"test-id-1":{"id":"test-id-1","title":"test title"}}}`)
+			os.WriteFile(filepath.Join(tmpDir, VaultFileName), original, VaultPermission)
+
+			vault, err := repo.Load()
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				assert.Nil(t, vault)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantVersion, vault.Version)
+			assert.Len(t, vault.Entries, 1)
+
+			backup, err := os.ReadFile(repo.migrationBackupPath(test.version))
+			if test.wantBackup {
+				assert.NoError(t, err)
+				assert.Equal(t, original, backup)
+			} else {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+}
+
+func TestFileVaultRepository_SaveKeepsBackup(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+	vault := domain.NewVault()
+	assert.NoError(t, repo.Save(vault))
+	backups, err := repo.Backups().List()
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+
+	before, _ := os.ReadFile(filepath.Join(tmpDir, VaultFileName))
+	vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "test title"})
+	assert.NoError(t, repo.Save(vault))
+
+	backups, err = repo.Backups().List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	data, err := repo.Backups().Read(&backups[0])
+	assert.NoError(t, err)
+	assert.Equal(t, before, data)
+}
+
+func TestFileVaultRepository_SaveSkipsBackupForViews(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+	vault := domain.NewVault()
+	vault.CreateEntry(*domain.NewEntry("test title", "", "password", "", ""))
+	assert.NoError(t, repo.Save(vault))
+
+	for i := 0; i < 3; i++ {
+		loaded, err := repo.Load()
+		assert.NoError(t, err)
+		for _, en := range loaded.Entries {
+			en.MarkAsViewed()
+		}
+		assert.NoError(t, repo.Save(loaded))
+	}
+	backups, err := repo.Backups().List()
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+
+	loaded, err := repo.Load()
+	assert.NoError(t, err)
+	for _, en := range loaded.Entries {
+		en.Update("new title", "", "password", "", "")
+	}
+	assert.NoError(t, repo.Save(loaded))
+	backups, err = repo.Backups().List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestFileVaultRepository_Restore(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		backup  []byte
+		id      func(*Backup) string
+		wantErr error
+	}{
+		{
+			name: "succeed: restore backup",
+			id:   func(b *Backup) string { return b.ID },
+		},
+		{
+			name:    "failed: backup does not decrypt",
+			backup:  []byte("corrupted"),
+			id:      func(b *Backup) string { return b.ID },
+			wantErr: ErrBackupUndecryptable,
+		},
+		{
+			name:    "failed: backup not found",
+			id:      func(b *Backup) string { return "1999" },
+			wantErr: ErrBackupNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+			vault := domain.NewVault()
+			vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "original"})
+			assert.NoError(t, repo.Save(vault))
+			vault.CreateEntry(domain.Entry{ID: "test-id-2", Title: "added"})
+			assert.NoError(t, repo.Save(vault))
+
+			backups, _ := repo.Backups().List()
+			backup := &backups[0]
+			if test.backup != nil {
+				assert.NoError(t, os.WriteFile(backup.Path, test.backup, VaultPermission))
+			}
+
+			_, err := repo.Restore(test.id(backup))
+
+			loaded, loadErr := repo.Load()
+			assert.NoError(t, loadErr)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				assert.Len(t, loaded.Entries, 2)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, loaded.Entries, 1)
+			assert.Equal(t, uint64(3), loaded.Revision)
+
+			backups, _ = repo.Backups().List()
+			assert.Len(t, backups, 2, "the replaced vault must be backed up")
+		})
+	}
+}
+
+func TestFileVaultRepository_LoadsVaultWithoutNewerFields(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+	original := []byte(`{"version":"1.0","entries":{"test-id-1":{"id":"test-id-1","title":"test title","password":"test password"}}}`)
+	os.WriteFile(filepath.Join(tmpDir, VaultFileName), original, VaultPermission)
+
+	vault, err := repo.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CurrentVaultVersion, vault.Version)
+	entry, err := vault.GetEntry("test-id-1")
+	assert.NoError(t, err)
+	assert.Empty(t, entry.CustomFields)
+	assert.Nil(t, entry.TOTP)
+
+	entry.SetCustomFields([]domain.CustomField{
+		{Name: "PIN", Type: domain.FieldHidden, Value: "1234"},
+		{Name: "Expires", Type: domain.FieldDate, Value: "2027-01-31"},
+	})
+	assert.NoError(t, repo.Save(vault))
+
+	loaded, err := repo.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, entry.CustomFields, loaded.Entries["test-id-1"].CustomFields)
+}
+
+func TestFileVaultRepository_ReencryptBackups(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		extra   []byte
+		wantErr error
+	}{
+		{
+			name: "succeed: backups written with key.bin",
+		},
+		{
+			name:    "failed: backup under another key",
+			extra:   []byte("corrupted"),
+			wantErr: ErrBackupUndecryptable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			legacyKey := make([]byte, KeySize)
+			assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, LegacyKeyFileName), legacyKey, KeyPermission))
+
+			km := newTestKeyManager(tmpDir)
+			assert.NoError(t, km.UnlockLegacy())
+			repo := NewFileVaultRepository(tmpDir, NewAESEncryptor(km))
+			plaintext := []byte(`{"version":"1.0"}`)
+			encryptedData, err := repo.cryptoSvc.Encrypt(plaintext)
+			assert.NoError(t, err)
+			assert.NoError(t, repo.backupBeforeMigration(encryptedData, "1.0"))
+			_, err = repo.backups.Create(encryptedData)
+			assert.NoError(t, err)
+			if test.extra != nil {
+				assert.NoError(t, os.WriteFile(repo.migrationBackupPath("0.9"), test.extra, VaultPermission))
+			}
+
+			legacy, err := km.LegacyEncryptor()
+			assert.NoError(t, err)
+			assert.NoError(t, km.InitializeKey(testPassword))
+
+			err = repo.ReencryptBackups(legacy)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			backups, err := repo.Backups().List()
+			assert.NoError(t, err)
+			for _, path := range []string{repo.migrationBackupPath("1.0"), backups[0].Path} {
+				data, err := os.ReadFile(path)
+				assert.NoError(t, err)
+				decrypted, err := repo.cryptoSvc.Decrypt(data)
+				assert.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+
+			if test.wantErr == nil {
+				assert.NoError(t, repo.ReencryptBackups(legacy), "re-encrypted backups are left alone")
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// errDirNotSynced is returned by writeFileAtomic when path was already
+// replaced but the directory could not be flushed.
+var errDirNotSynced = errors.New("file was replaced but its directory could not be synced")
+
+// fileSystem is the set of file operations used by the storage package.
+// Tests substitute it to simulate full disks and interrupted writes.
+type fileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	MkdirAll(path string, perm os.FileMode) error
+	OpenFile(name string, flag int, perm os.FileMode) (file, error)
+	CreateTemp(dir, pattern string) (file, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	SyncDir(dir string) error
+}
+
+type file interface {
+	Name() string
+	Write(p []byte) (int, error)
+	Chmod(mode os.FileMode) error
+	Sync() error
+	Close() error
+}
+
+type osFileSystem struct{}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) OpenFile(name string, flag int, perm os.FileMode) (file, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFileSystem) CreateTemp(dir, pattern string) (file, error) {
+	return os.CreateTemp(dir, pattern)
+}
+
+func (osFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// SyncDir flushes directory metadata so a completed rename survives a
+// crash. Windows does not support syncing directories.
+func (osFileSystem) SyncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// writeFileAtomic writes data to a temporary file next to path, flushes it,
+// renames it over path and flushes the directory, so path holds either the
+// old or the new contents but never a partial file. Errors wrapping
+// errDirNotSynced mean path holds the new contents.
+func writeFileAtomic(fsys fileSystem, path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := fsys.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if err := writeAndSync(tmp, data, perm); err != nil {
+		fsys.Remove(tmpPath)
+		return err
+	}
+
+	if err := fsys.Rename(tmpPath, path); err != nil {
+		fsys.Remove(tmpPath)
+		return err
+	}
+
+	if err := fsys.SyncDir(dir); err != nil {
+		return fmt.Errorf("%w: %w", errDirNotSynced, err)
+	}
+	return nil
+}
+
+// WriteSecretFile atomically writes a file holding secrets outside the
+// vault. Only the owner may read it, also when it replaces an existing file.
+func WriteSecretFile(path string, data []byte) error {
+	return writeFileAtomic(osFileSystem{}, path, data, VaultPermission)
+}
+
+// writeFileSynced writes data to path and flushes it to disk before
+// returning.
+func writeFileSynced(fsys fileSystem, path string, data []byte, perm os.FileMode) error {
+	f, err := fsys.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if err := writeAndSync(f, data, perm); err != nil {
+		return err
+	}
+	return fsys.SyncDir(filepath.Dir(path))
+}
+
+func writeAndSync(f file, data []byte, perm os.FileMode) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
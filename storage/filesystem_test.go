@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var errInjected = errors.New("injected failure")
+
+// faultyFileSystem wraps the real file system and fails selected
+// operations to simulate crashes and full disks.
+type faultyFileSystem struct {
+	osFileSystem
+	writeLimit int // bytes accepted before a write fails; 0 disables
+	failSync   bool
+	failRename bool
+	failRemove bool
+	// failSyncDir fails flushing the directory after the rename.
+	failSyncDir bool
+}
+
+func (f *faultyFileSystem) CreateTemp(dir, pattern string) (file, error) {
+	tmp, err := f.osFileSystem.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyFile{file: tmp, fs: f}, nil
+}
+
+func (f *faultyFileSystem) Rename(oldpath, newpath string) error {
+	if f.failRename {
+		return errInjected
+	}
+	return f.osFileSystem.Rename(oldpath, newpath)
+}
+
+func (f *faultyFileSystem) Remove(name string) error {
+	if f.failRemove {
+		return errInjected
+	}
+	return f.osFileSystem.Remove(name)
+}
+
+func (f *faultyFileSystem) SyncDir(dir string) error {
+	if f.failSyncDir {
+		return errInjected
+	}
+	return f.osFileSystem.SyncDir(dir)
+}
+
+type faultyFile struct {
+	file
+	fs      *faultyFileSystem
+	written int
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.fs.writeLimit > 0 && f.written+len(p) > f.fs.writeLimit {
+		n, _ := f.file.Write(p[:f.fs.writeLimit-f.written])
+		f.written += n
+		return n, errInjected
+	}
+	n, err := f.file.Write(p)
+	f.written += n
+	return n, err
+}
+
+func (f *faultyFile) Sync() error {
+	if f.fs.failSync {
+		return errInjected
+	}
+	return f.file.Sync()
+}
+
+func TestFileVaultRepository_SaveIsAtomic(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		fs   *faultyFileSystem
+	}{
+		{
+			name: "failed: partial write",
+			fs:   &faultyFileSystem{writeLimit: 10},
+		},
+		{
+			name: "failed: sync error",
+			fs:   &faultyFileSystem{failSync: true},
+		},
+		{
+			name: "failed: rename error",
+			fs:   &faultyFileSystem{failRename: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+			original := domain.NewVault()
+			original.CreateEntry(domain.Entry{ID: "test-id-1", Title: "original"})
+			assert.NoError(t, repo.Save(original))
+			before, _ := os.ReadFile(filepath.Join(tmpDir, VaultFileName))
+
+			updated, err := repo.Load()
+			assert.NoError(t, err)
+			repo.fs = test.fs
+			updated.UpdateEntry(domain.Entry{ID: "test-id-1", Title: "updated"})
+			updated.CreateEntry(domain.Entry{ID: "test-id-2", Title: "new"})
+			err = repo.Save(updated)
+			assert.ErrorIs(t, err, errInjected)
+
+			after, _ := os.ReadFile(filepath.Join(tmpDir, VaultFileName))
+			assert.Equal(t, before, after)
+
+			repo.fs = osFileSystem{}
+			loaded, err := repo.Load()
+			assert.NoError(t, err)
+			assert.Len(t, loaded.Entries, 1)
+			assert.Equal(t, "original", loaded.Entries["test-id-1"].Title)
+
+			tmpFiles, _ := filepath.Glob(filepath.Join(tmpDir, "*.tmp-*"))
+			assert.Empty(t, tmpFiles, "temporary files must be cleaned up")
+		})
+	}
+}
+
+func TestFileVaultRepository_SaveKeepsRevisionAfterRename(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	repo := NewFileVaultRepository(tmpDir, &mockCryptoService{})
+
+	vault := domain.NewVault()
+	vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "original"})
+	assert.NoError(t, repo.Save(vault))
+
+	repo.fs = &faultyFileSystem{failSyncDir: true}
+	vault.UpdateEntry(domain.Entry{ID: "test-id-1", Title: "updated"})
+	err := repo.Save(vault)
+	assert.ErrorIs(t, err, errInjected)
+	assert.ErrorIs(t, err, errDirNotSynced)
+
+	repo.fs = osFileSystem{}
+	loaded, err := repo.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", loaded.Entries["test-id-1"].Title)
+	assert.Equal(t, loaded.Revision, vault.Revision, "the replaced vault keeps its revision")
+
+	vault.CreateEntry(domain.Entry{ID: "test-id-2", Title: "new"})
+	assert.NoError(t, repo.Save(vault))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "file")
+
+	assert.NoError(t, writeFileAtomic(osFileSystem{}, path, []byte("first"), VaultPermission))
+	assert.NoError(t, writeFileAtomic(osFileSystem{}, path, []byte("second"), VaultPermission))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(VaultPermission), info.Mode().Perm())
+
+	files, _ := os.ReadDir(tmpDir)
+	assert.Len(t, files, 1)
+}
+
+func TestWriteSecretFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+
+	assert.NoError(t, WriteSecretFile(path, []byte("secret")))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), data)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
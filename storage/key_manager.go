@@ -2,34 +2,133 @@ package storage
 
 import (
 	"crypto/rand"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ritarock/passvault/domain"
+	"golang.org/x/crypto/argon2"
 )
 
 const (
-	KeySize       = 32 // AES-256
-	KeyFileName   = "key.bin"
-	KeyPermission = 0600
+	KeySize           = 32 // AES-256
+	SaltSize          = 16
+	KeyFileName       = "key.json"
+	LegacyKeyFileName = "key.bin"
+	KeyPermission     = 0600
+	KeyHeaderVersion  = 2
+	KDFArgon2id       = "argon2id"
+
+	// Upper bounds for KDF parameters read from key.json, so that a damaged
+	// or hostile header cannot make unlocking take forever or exhaust memory.
+	maxKDFTime   = 100
+	maxKDFMemory = 4 * 1024 * 1024 // KiB
 )
 
 var (
-	ErrKeyNotFound = errors.New("encryption key not found")
+	ErrKeyNotFound      = errors.New("encryption key not found")
+	ErrVaultLocked      = errors.New("vault is locked")
+	ErrRotationPending  = errors.New("a key rotation is in progress")
+	ErrNoRotationActive = errors.New("no key rotation in progress")
+	ErrRecoveryRotation = errors.New("unlock with a master password or keyfile to rotate the key")
+	ErrInvalidKDFParams = errors.New("invalid key derivation parameters")
 )
 
+// KDFParams describes how a key slot's wrapping key is derived from its
+// secret.
+type KDFParams struct {
+	Algorithm string `json:"algorithm"`
+	Salt      []byte `json:"salt"`
+	Time      uint32 `json:"time"`
+	Memory    uint32 `json:"memory"` // KiB
+	Threads   uint8  `json:"threads"`
+}
+
+// validate checks parameters read from disk before they reach Argon2, which
+// panics on zero values.
+func (p KDFParams) validate() error {
+	switch {
+	case p.Algorithm != KDFArgon2id:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, p.Algorithm)
+	case len(p.Salt) != SaltSize:
+		return fmt.Errorf("%w: salt is %d bytes, want %d", ErrInvalidKDFParams, len(p.Salt), SaltSize)
+	case p.Time == 0 || p.Time > maxKDFTime:
+		return fmt.Errorf("%w: time %d is not in 1..%d", ErrInvalidKDFParams, p.Time, maxKDFTime)
+	case p.Threads == 0:
+		return fmt.Errorf("%w: threads must not be zero", ErrInvalidKDFParams)
+	case p.Memory < 8*uint32(p.Threads) || p.Memory > maxKDFMemory:
+		return fmt.Errorf("%w: memory %d KiB is not in %d..%d", ErrInvalidKDFParams, p.Memory, 8*uint32(p.Threads), maxKDFMemory)
+	}
+	return nil
+}
+
+func DefaultKDFParams() KDFParams {
+	return KDFParams{
+		Algorithm: KDFArgon2id,
+		Time:      3,
+		Memory:    64 * 1024,
+		Threads:   4,
+	}
+}
+
+// keyHeader is the on-disk vault header. The vault is encrypted with a
+// random data key, and every slot holds a copy of that key wrapped by a key
+// derived from one unlock secret, so slots can be added or revoked without
+// re-encrypting the vault.
+type keyHeader struct {
+	Version int       `json:"version"`
+	Slots   []KeySlot `json:"slots,omitempty"`
+
+	// Version 1 headers derived the vault key from the master password
+	// directly. They are upgraded to a single password slot on unlock.
+	KDF      *KDFParams     `json:"kdf,omitempty"`
+	Verifier *EncryptedData `json:"verifier,omitempty"`
+
+	Pending *pendingRotation `json:"pending,omitempty"`
+}
+
+// pendingRotation records a key rotation that has not been committed yet.
+// Its slots wrap the new data key and replace the current slots once the
+// vault has been re-encrypted and verified.
+type pendingRotation struct {
+	Slots     []KeySlot `json:"slots"`
+	StartedAt time.Time `json:"started_at"`
+}
+
 type KeyManager struct {
-	keyPath string
+	keyPath       string
+	legacyKeyPath string
+	kdfParams     KDFParams
+	key           []byte
+	kek           []byte
+	pendingKey    []byte
+	pendingKEK    []byte
+	fs            fileSystem
 }
 
 func NewKeyManager(baseDir string) *KeyManager {
 	return &KeyManager{
-		keyPath: filepath.Join(baseDir, KeyFileName),
+		keyPath:       filepath.Join(baseDir, KeyFileName),
+		legacyKeyPath: filepath.Join(baseDir, LegacyKeyFileName),
+		kdfParams:     DefaultKDFParams(),
+		fs:            osFileSystem{},
 	}
 }
 
-func (km *KeyManager) InitializeKey() error {
+// InitializeKey generates a new data key protected by a single master
+// password slot and leaves the key manager unlocked.
+func (km *KeyManager) InitializeKey(password []byte) error {
+	if len(password) == 0 {
+		return domain.ErrEmptyPassword
+	}
+
 	dir := filepath.Dir(km.keyPath)
-	if err := os.MkdirAll(dir, DirPermission); err != nil {
+	if err := km.fs.MkdirAll(dir, DirPermission); err != nil {
 		return err
 	}
 
@@ -38,26 +137,519 @@ func (km *KeyManager) InitializeKey() error {
 		return err
 	}
 
-	return os.WriteFile(km.keyPath, key, KeyPermission)
+	slot, kek, err := km.newSlot(SlotPassword, password, key)
+	if err != nil {
+		return err
+	}
+
+	header := &keyHeader{
+		Version: KeyHeaderVersion,
+		Slots:   []KeySlot{*slot},
+	}
+	if err := km.saveHeader(header); err != nil {
+		return err
+	}
+
+	km.key = key
+	km.kek = kek
+	return nil
+}
+
+// Unlock unwraps the data key with the master password and keeps it in
+// memory. It returns domain.ErrInvalidPassword if the password is wrong.
+func (km *KeyManager) Unlock(password []byte) error {
+	return km.UnlockWith(SlotPassword, password)
+}
+
+// UnlockWith tries every slot of the given type with the secret.
+func (km *KeyManager) UnlockWith(slotType SlotType, secret []byte) error {
+	header, err := km.loadHeader()
+	if err != nil {
+		return err
+	}
+
+	if header.Version == 1 && slotType == SlotPassword {
+		return km.upgradeHeader(header, secret)
+	}
+
+	secret = normalizeSecret(slotType, secret)
+	key, kek, err := openSlots(header.Slots, slotType, secret)
+	if err != nil {
+		return err
+	}
+
+	// The secret may only open the new key of an interrupted rotation, as
+	// rotating with a password slot sets a new password.
+	var pendingKey, pendingKEK []byte
+	if header.Pending != nil {
+		pendingKey, pendingKEK, err = openSlots(header.Pending.Slots, slotType, secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	if key == nil && pendingKey == nil {
+		if slotType == SlotPassword {
+			return domain.ErrInvalidPassword
+		}
+		return ErrInvalidSlotSecret
+	}
+
+	km.key = key
+	km.kek = kek
+	km.pendingKey = pendingKey
+	km.pendingKEK = pendingKEK
+	return nil
+}
+
+// openSlots returns the data key and wrapping key of the first slot of the
+// given type that the secret opens, or nil keys if none does.
+func openSlots(slots []KeySlot, slotType SlotType, secret []byte) ([]byte, []byte, error) {
+	for _, slot := range slots {
+		if slot.Type != slotType {
+			continue
+		}
+
+		kek, err := deriveKey(secret, slot.KDF)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if key, err := open(kek, &slot.WrappedKey); err == nil {
+			return key, kek, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// Slots returns the metadata of every key slot.
+func (km *KeyManager) Slots() ([]KeySlot, error) {
+	header, err := km.loadHeader()
+	if err != nil {
+		return nil, err
+	}
+	return header.Slots, nil
+}
+
+// AddSlot wraps the unlocked data key with a new secret.
+func (km *KeyManager) AddSlot(slotType SlotType, secret []byte) (*KeySlot, error) {
+	if len(secret) == 0 {
+		return nil, domain.ErrEmptyPassword
+	}
+
+	key, err := km.LoadKey()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := km.loadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.Pending != nil {
+		return nil, ErrRotationPending
+	}
+
+	slot, _, err := km.newSlot(slotType, secret, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Slots = append(header.Slots, *slot)
+	if err := km.saveHeader(header); err != nil {
+		return nil, err
+	}
+
+	return slot, nil
+}
+
+// RevokeSlot removes the slot whose ID equals or uniquely starts with id.
+// The last remaining slot cannot be revoked.
+func (km *KeyManager) RevokeSlot(id string) (*KeySlot, error) {
+	if _, err := km.LoadKey(); err != nil {
+		return nil, err
+	}
+
+	header, err := km.loadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.Pending != nil {
+		return nil, ErrRotationPending
+	}
+
+	index := -1
+	for i, slot := range header.Slots {
+		if slot.ID == id {
+			index = i
+			break
+		}
+		if id != "" && strings.HasPrefix(slot.ID, id) {
+			if index >= 0 {
+				return nil, fmt.Errorf("ambiguous key slot id: %s", id)
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, ErrSlotNotFound
+	}
+	if len(header.Slots) == 1 {
+		return nil, ErrLastKeySlot
+	}
+
+	revoked := header.Slots[index]
+	header.Slots = append(header.Slots[:index], header.Slots[index+1:]...)
+	if err := km.saveHeader(header); err != nil {
+		return nil, err
+	}
+
+	return &revoked, nil
+}
+
+// BeginRotation generates a new data key and records it in the header,
+// wrapped by a key freshly derived from secret with a new salt, so neither
+// the old wrapping key nor the old header can unwrap it. For a password
+// slot secret is the new master password. A keyfile slot must be given the
+// keyfile that unlocked the vault, and recovery codes cannot be carried
+// over. The current key stays in use until CommitRotation is called.
+func (km *KeyManager) BeginRotation(secret []byte) error {
+	if km.key == nil || km.kek == nil {
+		return ErrVaultLocked
+	}
+
+	header, err := km.loadHeader()
+	if err != nil {
+		return err
+	}
+	if header.Pending != nil {
+		return ErrRotationPending
+	}
+
+	slot, err := km.unlockedSlot(header)
+	if err != nil {
+		return err
+	}
+
+	switch slot.Type {
+	case SlotPassword:
+		if len(secret) == 0 {
+			return domain.ErrEmptyPassword
+		}
+	case SlotKeyfile:
+		key, _, err := openSlots([]KeySlot{*slot}, slot.Type, normalizeSecret(slot.Type, secret))
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return ErrInvalidSlotSecret
+		}
+	default:
+		return ErrRecoveryRotation
+	}
+
+	newKey := make([]byte, KeySize)
+	if _, err := rand.Read(newKey); err != nil {
+		return err
+	}
+
+	pending, pendingKEK, err := km.newSlot(slot.Type, secret, newKey)
+	if err != nil {
+		return err
+	}
+
+	header.Pending = &pendingRotation{
+		Slots:     []KeySlot{*pending},
+		StartedAt: time.Now(),
+	}
+	if err := km.saveHeader(header); err != nil {
+		return err
+	}
+
+	km.pendingKey = newKey
+	km.pendingKEK = pendingKEK
+	return nil
+}
+
+// CommitRotation replaces the current slots with the pending ones and
+// retires the old data key. Slots that could not be carried over are
+// dropped.
+func (km *KeyManager) CommitRotation() error {
+	header, err := km.loadHeader()
+	if err != nil {
+		return err
+	}
+	if header.Pending == nil || km.pendingKey == nil {
+		return ErrNoRotationActive
+	}
+
+	header.Slots = header.Pending.Slots
+	header.Pending = nil
+	if err := km.saveHeader(header); err != nil {
+		return err
+	}
+
+	clear(km.key)
+	clear(km.kek)
+	km.key = km.pendingKey
+	km.kek = km.pendingKEK
+	km.pendingKey = nil
+	km.pendingKEK = nil
+	return nil
+}
+
+// AbortRotation discards a pending rotation and keeps the current key.
+func (km *KeyManager) AbortRotation() error {
+	header, err := km.loadHeader()
+	if err != nil {
+		return err
+	}
+	if header.Pending == nil {
+		return ErrNoRotationActive
+	}
+
+	header.Pending = nil
+	if err := km.saveHeader(header); err != nil {
+		return err
+	}
+
+	clear(km.pendingKey)
+	clear(km.pendingKEK)
+	km.pendingKey = nil
+	km.pendingKEK = nil
+	return nil
+}
+
+// UnlockedSlot returns the slot whose secret unlocked the vault.
+func (km *KeyManager) UnlockedSlot() (*KeySlot, error) {
+	if km.kek == nil {
+		return nil, ErrVaultLocked
+	}
+
+	header, err := km.loadHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return km.unlockedSlot(header)
+}
+
+// RotationPending reports whether the header records an unfinished
+// rotation.
+func (km *KeyManager) RotationPending() bool {
+	header, err := km.loadHeader()
+	return err == nil && header.Pending != nil
+}
+
+// PendingKey returns the new data key of an unfinished rotation.
+func (km *KeyManager) PendingKey() ([]byte, error) {
+	if km.pendingKey == nil {
+		return nil, ErrNoRotationActive
+	}
+	return km.pendingKey, nil
+}
+
+// Lock discards the in-memory vault keys.
+func (km *KeyManager) Lock() {
+	clear(km.key)
+	clear(km.kek)
+	clear(km.pendingKey)
+	clear(km.pendingKEK)
+	km.key = nil
+	km.kek = nil
+	km.pendingKey = nil
+	km.pendingKEK = nil
 }
 
 func (km *KeyManager) KeyExists() bool {
-	_, err := os.Stat(km.keyPath)
+	_, err := km.fs.Stat(km.keyPath)
 	return err == nil
 }
 
 func (km *KeyManager) LoadKey() ([]byte, error) {
-	if !km.KeyExists() {
-		return nil, ErrKeyNotFound
+	if km.key == nil {
+		if !km.KeyExists() {
+			return nil, ErrKeyNotFound
+		}
+		return nil, ErrVaultLocked
+	}
+	return km.key, nil
+}
+
+// LegacyKeyExists reports whether a raw key.bin from an earlier version is
+// still present.
+func (km *KeyManager) LegacyKeyExists() bool {
+	_, err := km.fs.Stat(km.legacyKeyPath)
+	return err == nil
+}
+
+// UnlockLegacy loads the raw key.bin so an existing vault can be read once
+// and re-encrypted under a master password.
+func (km *KeyManager) UnlockLegacy() error {
+	key, err := km.fs.ReadFile(km.legacyKeyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrKeyNotFound
+		}
+		return err
+	}
+
+	if len(key) != KeySize {
+		return errors.New("invalid key size")
 	}
-	key, err := os.ReadFile(km.keyPath)
+
+	km.key = key
+	return nil
+}
+
+// LegacyEncryptor returns an encryptor that keeps using the raw key.bin
+// after the key manager moved on to a new key, to re-encrypt what was
+// written with it.
+func (km *KeyManager) LegacyEncryptor() (*AESEncryptor, error) {
+	key, err := km.fs.ReadFile(km.legacyKeyPath)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrKeyNotFound
+		}
 		return nil, err
 	}
-
 	if len(key) != KeySize {
 		return nil, errors.New("invalid key size")
 	}
 
-	return key, nil
+	return &AESEncryptor{keys: keySourceFunc(func() ([]byte, error) {
+		return key, nil
+	})}, nil
+}
+
+func (km *KeyManager) RemoveLegacyKey() error {
+	return km.fs.Remove(km.legacyKeyPath)
+}
+
+// upgradeHeader turns a version 1 header into a version 2 header whose only
+// slot wraps the previously derived key, so the vault stays readable.
+func (km *KeyManager) upgradeHeader(header *keyHeader, password []byte) error {
+	if header.KDF == nil || header.Verifier == nil {
+		return errors.New("invalid key header")
+	}
+
+	key, err := deriveKey(password, *header.KDF)
+	if err != nil {
+		return err
+	}
+
+	if _, err := open(key, header.Verifier); err != nil {
+		return domain.ErrInvalidPassword
+	}
+
+	slot, kek, err := km.newSlot(SlotPassword, password, key)
+	if err != nil {
+		return err
+	}
+
+	upgraded := &keyHeader{
+		Version: KeyHeaderVersion,
+		Slots:   []KeySlot{*slot},
+	}
+	if err := km.saveHeader(upgraded); err != nil {
+		return err
+	}
+
+	km.key = key
+	km.kek = kek
+	return nil
+}
+
+// newSlot wraps key with a key derived from secret and returns the slot
+// together with that wrapping key.
+func (km *KeyManager) newSlot(slotType SlotType, secret, key []byte) (*KeySlot, []byte, error) {
+	params := km.kdfParams
+	params.Salt = make([]byte, SaltSize)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, nil, err
+	}
+
+	kek, err := deriveKey(normalizeSecret(slotType, secret), params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := seal(kek, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &KeySlot{
+		ID:         uuid.New().String(),
+		Type:       slotType,
+		KDF:        params,
+		WrappedKey: *wrapped,
+		CreatedAt:  time.Now(),
+	}, kek, nil
+}
+
+// unlockedSlot finds the current slot that the in-memory wrapping key opens.
+func (km *KeyManager) unlockedSlot(header *keyHeader) (*KeySlot, error) {
+	for i, slot := range header.Slots {
+		if _, err := open(km.kek, &slot.WrappedKey); err == nil {
+			return &header.Slots[i], nil
+		}
+	}
+	return nil, ErrSlotNotFound
+}
+
+func (km *KeyManager) loadHeader() (*keyHeader, error) {
+	data, err := km.fs.ReadFile(km.keyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	var header keyHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+
+	return &header, nil
+}
+
+func (h *keyHeader) validate() error {
+	if h.KDF != nil {
+		if err := h.KDF.validate(); err != nil {
+			return err
+		}
+	}
+	slots := h.Slots
+	if h.Pending != nil {
+		slots = append(slots[:len(slots):len(slots)], h.Pending.Slots...)
+	}
+	for _, slot := range slots {
+		if err := slot.KDF.validate(); err != nil {
+			return fmt.Errorf("key slot %s: %w", slot.ID, err)
+		}
+	}
+	return nil
+}
+
+func (km *KeyManager) saveHeader(header *keyHeader) error {
+	data, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(km.fs, km.keyPath, data, KeyPermission)
+}
+
+func deriveKey(password []byte, params KDFParams) ([]byte, error) {
+	switch params.Algorithm {
+	case KDFArgon2id:
+		return argon2.IDKey(password, params.Salt, params.Time, params.Memory, params.Threads, KeySize), nil
+	default:
+		return nil, errors.New("unsupported key derivation function: " + params.Algorithm)
+	}
 }
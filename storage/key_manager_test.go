@@ -1,31 +1,58 @@
 package storage
 
 import (
+	"crypto/rand"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/ritarock/passvault/domain"
 	"github.com/stretchr/testify/assert"
 )
 
+var testPassword = []byte("correct horse battery staple")
+
+// newTestKeyManager returns a key manager with cheap KDF parameters so tests
+// do not spend most of their time in Argon2.
+func newTestKeyManager(dir string) *KeyManager {
+	km := NewKeyManager(dir)
+	km.kdfParams = KDFParams{
+		Algorithm: KDFArgon2id,
+		Time:      1,
+		Memory:    1024,
+		Threads:   1,
+	}
+	return km
+}
+
 func TestKeyManager_InitializeKey(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name   string
-		setup  func(string)
-		hasErr bool
+		name     string
+		setup    func(string)
+		password []byte
+		hasErr   bool
 	}{
 		{
-			name:   "succeed: create new key",
-			setup:  func(dir string) {},
-			hasErr: false,
+			name:     "succeed: create new key",
+			setup:    func(dir string) {},
+			password: testPassword,
+			hasErr:   false,
 		},
 		{
 			name: "succeed: create key with non-existent directory",
 			setup: func(dir string) {
 				os.RemoveAll(dir)
 			},
-			hasErr: false,
+			password: testPassword,
+			hasErr:   false,
+		},
+		{
+			name:     "failed: empty password",
+			setup:    func(dir string) {},
+			password: []byte{},
+			hasErr:   true,
 		},
 	}
 
@@ -35,18 +62,526 @@ func TestKeyManager_InitializeKey(t *testing.T) {
 			tmpDir := t.TempDir()
 			test.setup(tmpDir)
 
-			km := NewKeyManager(tmpDir)
-			err := km.InitializeKey()
+			km := newTestKeyManager(tmpDir)
+			err := km.InitializeKey(test.password)
 
 			if test.hasErr {
 				assert.Error(t, err)
+				assert.False(t, km.KeyExists())
 			} else {
 				assert.NoError(t, err)
 				assert.True(t, km.KeyExists())
 
-				key, err := os.ReadFile(filepath.Join(tmpDir, KeyFileName))
+				data, err := os.ReadFile(filepath.Join(tmpDir, KeyFileName))
 				assert.NoError(t, err)
-				assert.Equal(t, KeySize, len(key))
+
+				var header keyHeader
+				assert.NoError(t, json.Unmarshal(data, &header))
+				assert.Equal(t, KeyHeaderVersion, header.Version)
+				assert.Len(t, header.Slots, 1)
+				assert.Equal(t, SlotPassword, header.Slots[0].Type)
+				assert.Equal(t, KDFArgon2id, header.Slots[0].KDF.Algorithm)
+				assert.Len(t, header.Slots[0].KDF.Salt, SaltSize)
+				assert.NotContains(t, string(data), string(test.password))
+
+				key, err := km.LoadKey()
+				assert.NoError(t, err)
+				assert.Len(t, key, KeySize)
+			}
+		})
+	}
+}
+
+func TestKeyManager_Unlock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		setup    func(string)
+		password []byte
+		wantErr  error
+	}{
+		{
+			name: "succeed: correct password",
+			setup: func(dir string) {
+				newTestKeyManager(dir).InitializeKey(testPassword)
+			},
+			password: testPassword,
+		},
+		{
+			name: "failed: wrong password",
+			setup: func(dir string) {
+				newTestKeyManager(dir).InitializeKey(testPassword)
+			},
+			password: []byte("wrong password"),
+			wantErr:  domain.ErrInvalidPassword,
+		},
+		{
+			name:     "failed: key not found",
+			setup:    func(dir string) {},
+			password: testPassword,
+			wantErr:  ErrKeyNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			test.setup(tmpDir)
+
+			km := newTestKeyManager(tmpDir)
+			err := km.Unlock(test.password)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				_, err := km.LoadKey()
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				key, err := km.LoadKey()
+				assert.NoError(t, err)
+				assert.Len(t, key, KeySize)
+			}
+		})
+	}
+}
+
+func TestKeyManager_UnlockDerivesSameKey(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	km := newTestKeyManager(tmpDir)
+	assert.NoError(t, km.InitializeKey(testPassword))
+	want, err := km.LoadKey()
+	assert.NoError(t, err)
+
+	other := newTestKeyManager(tmpDir)
+	assert.NoError(t, other.Unlock(testPassword))
+	got, err := other.LoadKey()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestKeyManager_UnlockUpgradesVersion1Header(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	km := newTestKeyManager(tmpDir)
+
+	params := km.kdfParams
+	params.Salt = make([]byte, SaltSize)
+	rand.Read(params.Salt)
+	key, err := deriveKey(testPassword, params)
+	assert.NoError(t, err)
+	verifier, err := seal(key, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, km.saveHeader(&keyHeader{Version: 1, KDF: &params, Verifier: verifier}))
+
+	assert.ErrorIs(t, km.Unlock([]byte("wrong password")), domain.ErrInvalidPassword)
+	assert.NoError(t, km.Unlock(testPassword))
+	got, err := km.LoadKey()
+	assert.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	header, err := km.loadHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, KeyHeaderVersion, header.Version)
+	assert.Nil(t, header.KDF)
+	assert.Len(t, header.Slots, 1)
+
+	other := newTestKeyManager(tmpDir)
+	assert.NoError(t, other.Unlock(testPassword))
+	got, err = other.LoadKey()
+	assert.NoError(t, err)
+	assert.Equal(t, key, got)
+}
+
+func TestKeyManager_UnlockRejectsInvalidKDFParams(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		modify func(*KDFParams)
+	}{
+		{
+			name:   "failed: zero time",
+			modify: func(p *KDFParams) { p.Time = 0 },
+		},
+		{
+			name:   "failed: zero threads",
+			modify: func(p *KDFParams) { p.Threads = 0 },
+		},
+		{
+			name:   "failed: zero memory",
+			modify: func(p *KDFParams) { p.Memory = 0 },
+		},
+		{
+			name:   "failed: too much memory",
+			modify: func(p *KDFParams) { p.Memory = maxKDFMemory + 1 },
+		},
+		{
+			name:   "failed: short salt",
+			modify: func(p *KDFParams) { p.Salt = p.Salt[:4] },
+		},
+		{
+			name:   "failed: unknown algorithm",
+			modify: func(p *KDFParams) { p.Algorithm = "scrypt" },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			assert.NoError(t, km.InitializeKey(testPassword))
+
+			header, err := km.loadHeader()
+			assert.NoError(t, err)
+			test.modify(&header.Slots[0].KDF)
+			assert.NoError(t, km.saveHeader(header))
+
+			other := newTestKeyManager(tmpDir)
+			assert.ErrorIs(t, other.Unlock(testPassword), ErrInvalidKDFParams)
+		})
+	}
+}
+
+func TestKeyManager_AddSlot(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		slotType SlotType
+		secret   []byte
+		unlock   []byte
+		hasErr   bool
+	}{
+		{
+			name:     "succeed: second password",
+			slotType: SlotPassword,
+			secret:   []byte("second password"),
+			unlock:   []byte("second password"),
+		},
+		{
+			name:     "succeed: keyfile",
+			slotType: SlotKeyfile,
+			secret:   []byte("keyfile contents"),
+			unlock:   []byte("keyfile contents"),
+		},
+		{
+			name:     "succeed: recovery code is normalized",
+			slotType: SlotRecovery,
+			secret:   []byte("ABCDE-FGHIJ"),
+			unlock:   []byte("abcde fghij\n"),
+		},
+		{
+			name:     "failed: empty secret",
+			slotType: SlotKeyfile,
+			secret:   []byte{},
+			hasErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			assert.NoError(t, km.InitializeKey(testPassword))
+			want, _ := km.LoadKey()
+
+			slot, err := km.AddSlot(test.slotType, test.secret)
+
+			if test.hasErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.slotType, slot.Type)
+			assert.False(t, slot.CreatedAt.IsZero())
+
+			slots, err := km.Slots()
+			assert.NoError(t, err)
+			assert.Len(t, slots, 2)
+
+			other := newTestKeyManager(tmpDir)
+			assert.NoError(t, other.UnlockWith(test.slotType, test.unlock))
+			got, err := other.LoadKey()
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			assert.NoError(t, newTestKeyManager(tmpDir).Unlock(testPassword))
+		})
+	}
+}
+
+func TestKeyManager_AddSlotRequiresUnlock(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	newTestKeyManager(tmpDir).InitializeKey(testPassword)
+
+	_, err := newTestKeyManager(tmpDir).AddSlot(SlotPassword, []byte("another"))
+	assert.ErrorIs(t, err, ErrVaultLocked)
+}
+
+func TestKeyManager_UnlockWith(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	km := newTestKeyManager(tmpDir)
+	km.InitializeKey(testPassword)
+	km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+
+	tests := []struct {
+		name     string
+		slotType SlotType
+		secret   []byte
+		wantErr  error
+	}{
+		{
+			name:     "succeed: keyfile",
+			slotType: SlotKeyfile,
+			secret:   []byte("keyfile contents"),
+		},
+		{
+			name:     "failed: wrong keyfile",
+			slotType: SlotKeyfile,
+			secret:   []byte("other contents"),
+			wantErr:  ErrInvalidSlotSecret,
+		},
+		{
+			name:     "failed: password used as keyfile",
+			slotType: SlotKeyfile,
+			secret:   testPassword,
+			wantErr:  ErrInvalidSlotSecret,
+		},
+		{
+			name:     "failed: no recovery slot",
+			slotType: SlotRecovery,
+			secret:   []byte("ABCDE"),
+			wantErr:  ErrInvalidSlotSecret,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := newTestKeyManager(tmpDir).UnlockWith(test.slotType, test.secret)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKeyManager_RevokeSlot(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		setup   func(*KeyManager) string
+		wantErr error
+	}{
+		{
+			name: "succeed: revoke by id",
+			setup: func(km *KeyManager) string {
+				slot, _ := km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+				return slot.ID
+			},
+		},
+		{
+			name: "succeed: revoke by prefix",
+			setup: func(km *KeyManager) string {
+				slot, _ := km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+				return slot.ID[:8]
+			},
+		},
+		{
+			name: "failed: slot not found",
+			setup: func(km *KeyManager) string {
+				km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+				return "does-not-exist"
+			},
+			wantErr: ErrSlotNotFound,
+		},
+		{
+			name: "failed: last slot",
+			setup: func(km *KeyManager) string {
+				slots, _ := km.Slots()
+				return slots[0].ID
+			},
+			wantErr: ErrLastKeySlot,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			km.InitializeKey(testPassword)
+			id := test.setup(km)
+
+			revoked, err := km.RevokeSlot(id)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, SlotKeyfile, revoked.Type)
+			slots, _ := km.Slots()
+			assert.Len(t, slots, 1)
+			err = newTestKeyManager(tmpDir).UnlockWith(SlotKeyfile, []byte("keyfile contents"))
+			assert.ErrorIs(t, err, ErrInvalidSlotSecret)
+		})
+	}
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		finish  func(*KeyManager) error
+		wantNew bool
+	}{
+		{
+			name:    "succeed: commit replaces key",
+			finish:  (*KeyManager).CommitRotation,
+			wantNew: true,
+		},
+		{
+			name:    "succeed: abort keeps key",
+			finish:  (*KeyManager).AbortRotation,
+			wantNew: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			km.InitializeKey(testPassword)
+			oldKey := append([]byte(nil), km.key...)
+
+			assert.NoError(t, km.BeginRotation(testPassword))
+			assert.True(t, km.RotationPending())
+			assert.ErrorIs(t, km.BeginRotation(testPassword), ErrRotationPending)
+			_, err := km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+			assert.ErrorIs(t, err, ErrRotationPending)
+
+			pendingKey, err := km.PendingKey()
+			assert.NoError(t, err)
+			assert.NotEqual(t, oldKey, pendingKey)
+			pendingKey = append([]byte(nil), pendingKey...)
+
+			reopened := newTestKeyManager(tmpDir)
+			assert.NoError(t, reopened.Unlock(testPassword))
+			reopenedPending, err := reopened.PendingKey()
+			assert.NoError(t, err)
+			assert.Equal(t, pendingKey, reopenedPending)
+
+			assert.NoError(t, test.finish(km))
+			assert.False(t, km.RotationPending())
+
+			other := newTestKeyManager(tmpDir)
+			assert.NoError(t, other.Unlock(testPassword))
+			key, _ := other.LoadKey()
+			if test.wantNew {
+				assert.Equal(t, pendingKey, key)
+			} else {
+				assert.Equal(t, oldKey, key)
+			}
+		})
+	}
+}
+
+func TestKeyManager_RotationRederivesSlot(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	km := newTestKeyManager(tmpDir)
+	assert.NoError(t, km.InitializeKey(testPassword))
+	oldKEK := append([]byte(nil), km.kek...)
+	newPassword := []byte("new master password")
+
+	assert.NoError(t, km.BeginRotation(newPassword))
+
+	header, err := km.loadHeader()
+	assert.NoError(t, err)
+	assert.Len(t, header.Pending.Slots, 1)
+	pending := header.Pending.Slots[0]
+	assert.NotEqual(t, header.Slots[0].KDF.Salt, pending.KDF.Salt)
+	_, err = open(oldKEK, &pending.WrappedKey)
+	assert.Error(t, err, "the old wrapping key must not unwrap the new key")
+
+	assert.NoError(t, km.CommitRotation())
+	reopened := newTestKeyManager(tmpDir)
+	assert.ErrorIs(t, reopened.Unlock(testPassword), domain.ErrInvalidPassword)
+	assert.NoError(t, reopened.Unlock(newPassword))
+	key, _ := reopened.LoadKey()
+	assert.Equal(t, km.key, key)
+}
+
+func TestKeyManager_BeginRotationSecret(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		slotType SlotType
+		unlock   []byte
+		secret   []byte
+		wantErr  error
+	}{
+		{
+			name:     "succeed: keyfile that unlocked the vault",
+			slotType: SlotKeyfile,
+			unlock:   []byte("keyfile contents"),
+			secret:   []byte("keyfile contents"),
+		},
+		{
+			name:     "failed: other keyfile",
+			slotType: SlotKeyfile,
+			unlock:   []byte("keyfile contents"),
+			secret:   []byte("other contents"),
+			wantErr:  ErrInvalidSlotSecret,
+		},
+		{
+			name:     "failed: empty password",
+			slotType: SlotPassword,
+			unlock:   testPassword,
+			secret:   nil,
+			wantErr:  domain.ErrEmptyPassword,
+		},
+		{
+			name:     "failed: recovery code",
+			slotType: SlotRecovery,
+			unlock:   []byte("ABCD-EFGH"),
+			secret:   []byte("ABCD-EFGH"),
+			wantErr:  ErrRecoveryRotation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			km := newTestKeyManager(tmpDir)
+			assert.NoError(t, km.InitializeKey(testPassword))
+			if test.slotType != SlotPassword {
+				_, err := km.AddSlot(test.slotType, test.unlock)
+				assert.NoError(t, err)
+			}
+
+			unlocked := newTestKeyManager(tmpDir)
+			assert.NoError(t, unlocked.UnlockWith(test.slotType, test.unlock))
+			err := unlocked.BeginRotation(test.secret)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				assert.False(t, unlocked.RotationPending())
+			} else {
+				assert.NoError(t, err)
+				assert.NoError(t, unlocked.CommitRotation())
+				assert.NoError(t, newTestKeyManager(tmpDir).UnlockWith(test.slotType, test.secret))
 			}
 		})
 	}
@@ -62,8 +597,8 @@ func TestKeyManager_KeyExists(t *testing.T) {
 		{
 			name: "succeed: key exists",
 			setup: func(dir string) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
 			},
 			want: true,
 		},
@@ -80,7 +615,7 @@ func TestKeyManager_KeyExists(t *testing.T) {
 			tmpDir := t.TempDir()
 			test.setup(tmpDir)
 
-			km := NewKeyManager(tmpDir)
+			km := newTestKeyManager(tmpDir)
 			result := km.KeyExists()
 			assert.Equal(t, test.want, result)
 		})
@@ -91,32 +626,46 @@ func TestKeyManager_LoadKey(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name   string
-		setup  func(string)
+		setup  func(string) *KeyManager
 		hasErr bool
 		errMsg string
 	}{
 		{
-			name: "succeed: load existing key",
-			setup: func(dir string) {
-				km := NewKeyManager(dir)
-				km.InitializeKey()
+			name: "succeed: load unlocked key",
+			setup: func(dir string) *KeyManager {
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
+				return km
 			},
 			hasErr: false,
 		},
 		{
-			name:   "failed: key not found",
-			setup:  func(dir string) {},
+			name: "failed: key not found",
+			setup: func(dir string) *KeyManager {
+				return newTestKeyManager(dir)
+			},
 			hasErr: true,
 			errMsg: "encryption key not found",
 		},
 		{
-			name: "failed: invalid key size",
-			setup: func(dir string) {
-				os.MkdirAll(dir, DirPermission)
-				os.WriteFile(filepath.Join(dir, KeyFileName), []byte("invalid"), KeyPermission)
+			name: "failed: vault locked",
+			setup: func(dir string) *KeyManager {
+				newTestKeyManager(dir).InitializeKey(testPassword)
+				return newTestKeyManager(dir)
 			},
 			hasErr: true,
-			errMsg: "invalid key size",
+			errMsg: "vault is locked",
+		},
+		{
+			name: "failed: locked after Lock",
+			setup: func(dir string) *KeyManager {
+				km := newTestKeyManager(dir)
+				km.InitializeKey(testPassword)
+				km.Lock()
+				return km
+			},
+			hasErr: true,
+			errMsg: "vault is locked",
 		},
 	}
 
@@ -124,9 +673,8 @@ func TestKeyManager_LoadKey(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 			tmpDir := t.TempDir()
-			test.setup(tmpDir)
+			km := test.setup(tmpDir)
 
-			km := NewKeyManager(tmpDir)
 			key, err := km.LoadKey()
 
 			if test.hasErr {
@@ -143,3 +691,64 @@ func TestKeyManager_LoadKey(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyManager_UnlockLegacy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		setup  func(string) []byte
+		hasErr bool
+		errMsg string
+	}{
+		{
+			name: "succeed: load legacy key",
+			setup: func(dir string) []byte {
+				key := make([]byte, KeySize)
+				rand.Read(key)
+				os.WriteFile(filepath.Join(dir, LegacyKeyFileName), key, KeyPermission)
+				return key
+			},
+			hasErr: false,
+		},
+		{
+			name:   "failed: legacy key not found",
+			setup:  func(dir string) []byte { return nil },
+			hasErr: true,
+			errMsg: "encryption key not found",
+		},
+		{
+			name: "failed: invalid key size",
+			setup: func(dir string) []byte {
+				os.WriteFile(filepath.Join(dir, LegacyKeyFileName), []byte("invalid"), KeyPermission)
+				return nil
+			},
+			hasErr: true,
+			errMsg: "invalid key size",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			want := test.setup(tmpDir)
+
+			km := newTestKeyManager(tmpDir)
+			err := km.UnlockLegacy()
+
+			if test.hasErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, km.LegacyKeyExists())
+				key, err := km.LoadKey()
+				assert.NoError(t, err)
+				assert.Equal(t, want, key)
+
+				assert.NoError(t, km.RemoveLegacyKey())
+				assert.False(t, km.LegacyKeyExists())
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const RotationFileSuffix = ".rotating"
+
+// KeyRotator replaces the data key and re-encrypts the vault with it.
+//
+// The rotation is journaled in the key header: the new key is recorded as
+// pending first, the vault is re-encrypted into a side file, verified and
+// renamed into place, and only then is the old key retired. Recover inspects
+// the vault after a crash and either finishes or discards the rotation.
+type KeyRotator struct {
+	keyManager *KeyManager
+	vaultRepo  *FileVaultRepository
+	pending    *FileVaultRepository
+}
+
+func NewKeyRotator(keyManager *KeyManager, vaultRepo *FileVaultRepository) *KeyRotator {
+	pendingCrypto := &AESEncryptor{
+		keyManager: keyManager,
+		keys:       keySourceFunc(keyManager.PendingKey),
+	}
+
+	return &KeyRotator{
+		keyManager: keyManager,
+		vaultRepo:  vaultRepo,
+		pending:    vaultRepo.withCryptoService(pendingCrypto),
+	}
+}
+
+// Rotate re-encrypts the vault with a new data key wrapped by secret, see
+// KeyManager.BeginRotation.
+func (r *KeyRotator) Rotate(secret []byte) error {
+	if err := r.Recover(); err != nil {
+		return err
+	}
+
+	vault, err := r.vaultRepo.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load vault: %w", err)
+	}
+
+	if err := r.keyManager.BeginRotation(secret); err != nil {
+		return fmt.Errorf("failed to begin rotation: %w", err)
+	}
+
+	encryptedData, err := r.pending.encode(vault)
+	if err != nil {
+		return r.abort(fmt.Errorf("failed to encrypt vault: %w", err))
+	}
+
+	rotatingPath := r.rotatingPath()
+	if err := writeFileSynced(r.vaultRepo.fs, rotatingPath, encryptedData, VaultPermission); err != nil {
+		return r.abort(fmt.Errorf("failed to write vault: %w", err))
+	}
+
+	if err := r.verify(rotatingPath, len(vault.Entries)); err != nil {
+		return r.abort(fmt.Errorf("failed to verify re-encrypted vault: %w", err))
+	}
+
+	if err := r.vaultRepo.fs.Rename(rotatingPath, r.vaultRepo.vaultPath); err != nil {
+		return r.abort(fmt.Errorf("failed to replace vault: %w", err))
+	}
+	if err := r.vaultRepo.fs.SyncDir(filepath.Dir(r.vaultRepo.vaultPath)); err != nil {
+		return fmt.Errorf("failed to sync vault directory: %w", err)
+	}
+
+	return r.commit()
+}
+
+// Recover completes or discards a rotation interrupted by a crash. It is a
+// no-op when no rotation is pending.
+func (r *KeyRotator) Recover() error {
+	if err := r.vaultRepo.fs.Remove(r.rotatingPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove partial vault: %w", err)
+	}
+
+	if !r.keyManager.RotationPending() {
+		return nil
+	}
+
+	encryptedData, err := r.vaultRepo.fs.ReadFile(r.vaultRepo.vaultPath)
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	if _, err := r.vaultRepo.decode(encryptedData); err == nil {
+		return r.keyManager.AbortRotation()
+	}
+
+	if _, err := r.pending.decode(encryptedData); err == nil {
+		return r.commit()
+	}
+
+	return fmt.Errorf("%w: unlock with the secret that started it to recover", ErrRotationPending)
+}
+
+// commit re-encrypts the backups with the new key and retires the old key.
+// The rotation is committed even if some backups could not be rewritten,
+// since the vault itself already uses the new key.
+func (r *KeyRotator) commit() error {
+	backupErr := r.reencryptBackups()
+
+	if err := r.keyManager.CommitRotation(); err != nil {
+		return fmt.Errorf("failed to commit rotation: %w", err)
+	}
+
+	if backupErr != nil {
+		return fmt.Errorf("failed to re-encrypt backups: %w", backupErr)
+	}
+
+	return nil
+}
+
+// reencryptBackups rewrites every backup under the pending key, so backups
+// stay restorable after the rotation. This includes the backups kept before
+// migrations.
+func (r *KeyRotator) reencryptBackups() error {
+	paths, err := r.vaultRepo.backupPaths()
+	if err != nil {
+		return err
+	}
+
+	return r.vaultRepo.reencryptFiles(paths, r.vaultRepo.cryptoSvc, r.pending.cryptoSvc)
+}
+
+func (r *KeyRotator) verify(path string, wantEntries int) error {
+	encryptedData, err := r.vaultRepo.fs.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	vault, err := r.pending.decode(encryptedData)
+	if err != nil {
+		return err
+	}
+
+	if len(vault.Entries) != wantEntries {
+		return fmt.Errorf("expected %d entries, got %d", wantEntries, len(vault.Entries))
+	}
+
+	return nil
+}
+
+func (r *KeyRotator) abort(err error) error {
+	r.vaultRepo.fs.Remove(r.rotatingPath())
+	if abortErr := r.keyManager.AbortRotation(); abortErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to abort rotation: %w", abortErr))
+	}
+	return err
+}
+
+func (r *KeyRotator) rotatingPath() string {
+	return r.vaultRepo.vaultPath + RotationFileSuffix
+}
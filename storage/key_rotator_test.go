@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRotation(t *testing.T) (string, *KeyManager, *FileVaultRepository, []byte) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	km := newTestKeyManager(tmpDir)
+	assert.NoError(t, km.InitializeKey(testPassword))
+	repo := NewFileVaultRepository(tmpDir, NewAESEncryptor(km))
+
+	vault := domain.NewVault()
+	vault.CreateEntry(domain.Entry{ID: "test-id-1", Title: "test title", Password: "test password"})
+	assert.NoError(t, repo.Save(vault))
+
+	oldKey, _ := km.LoadKey()
+	return tmpDir, km, repo, append([]byte(nil), oldKey...)
+}
+
+func reopen(t *testing.T, dir string) (*KeyManager, *FileVaultRepository) {
+	t.Helper()
+	km := newTestKeyManager(dir)
+	assert.NoError(t, km.Unlock(testPassword))
+	return km, NewFileVaultRepository(dir, NewAESEncryptor(km))
+}
+
+func TestKeyRotator_Rotate(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, oldKey := setupRotation(t)
+	km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+
+	err := NewKeyRotator(km, repo).Rotate(testPassword)
+	assert.NoError(t, err)
+
+	newKey, err := km.LoadKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldKey, newKey)
+	assert.False(t, km.RotationPending())
+	_, err = os.Stat(repo.vaultPath + RotationFileSuffix)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	slots, err := km.Slots()
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.Equal(t, SlotPassword, slots[0].Type)
+
+	reopenedKM, reopenedRepo := reopen(t, tmpDir)
+	key, _ := reopenedKM.LoadKey()
+	assert.Equal(t, newKey, key)
+
+	vault, err := reopenedRepo.Load()
+	assert.NoError(t, err)
+	entry, err := vault.GetEntry("test-id-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "test password", entry.Password)
+
+	oldRepo := NewFileVaultRepository(tmpDir, &AESEncryptor{keys: keySourceFunc(func() ([]byte, error) {
+		return oldKey, nil
+	})})
+	_, err = oldRepo.Load()
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestKeyRotator_Recover(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		// crash simulates a rotation interrupted after some steps.
+		crash       func(*testing.T, *KeyManager, *FileVaultRepository)
+		wantRotated bool
+	}{
+		{
+			name: "succeed: crash after pending key was recorded",
+			crash: func(t *testing.T, km *KeyManager, repo *FileVaultRepository) {
+				assert.NoError(t, km.BeginRotation(testPassword))
+			},
+			wantRotated: false,
+		},
+		{
+			name: "succeed: crash while writing re-encrypted vault",
+			crash: func(t *testing.T, km *KeyManager, repo *FileVaultRepository) {
+				assert.NoError(t, km.BeginRotation(testPassword))
+				os.WriteFile(repo.vaultPath+RotationFileSuffix, []byte("partial"), VaultPermission)
+			},
+			wantRotated: false,
+		},
+		{
+			name: "succeed: crash after vault was replaced",
+			crash: func(t *testing.T, km *KeyManager, repo *FileVaultRepository) {
+				vault, err := repo.Load()
+				assert.NoError(t, err)
+				rotator := NewKeyRotator(km, repo)
+				assert.NoError(t, km.BeginRotation(testPassword))
+				data, err := rotator.pending.encode(vault)
+				assert.NoError(t, err)
+				assert.NoError(t, os.WriteFile(repo.vaultPath, data, VaultPermission))
+			},
+			wantRotated: true,
+		},
+		{
+			name:        "succeed: nothing pending",
+			crash:       func(t *testing.T, km *KeyManager, repo *FileVaultRepository) {},
+			wantRotated: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir, km, repo, oldKey := setupRotation(t)
+			test.crash(t, km, repo)
+
+			reopenedKM, reopenedRepo := reopen(t, tmpDir)
+			err := NewKeyRotator(reopenedKM, reopenedRepo).Recover()
+			assert.NoError(t, err)
+			assert.False(t, reopenedKM.RotationPending())
+
+			_, err = os.Stat(repo.vaultPath + RotationFileSuffix)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			key, _ := reopenedKM.LoadKey()
+			if test.wantRotated {
+				assert.NotEqual(t, oldKey, key)
+			} else {
+				assert.Equal(t, oldKey, key)
+			}
+
+			vault, err := reopenedRepo.Load()
+			assert.NoError(t, err)
+			assert.Len(t, vault.Entries, 1)
+		})
+	}
+}
+
+func TestKeyRotator_RecoverWithNewPassword(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, oldKey := setupRotation(t)
+	newPassword := []byte("new master password")
+
+	vault, err := repo.Load()
+	assert.NoError(t, err)
+	rotator := NewKeyRotator(km, repo)
+	assert.NoError(t, km.BeginRotation(newPassword))
+	data, err := rotator.pending.encode(vault)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(repo.vaultPath, data, VaultPermission))
+
+	reopenedKM := newTestKeyManager(tmpDir)
+	assert.NoError(t, reopenedKM.Unlock(newPassword))
+	reopenedRepo := NewFileVaultRepository(tmpDir, NewAESEncryptor(reopenedKM))
+	assert.NoError(t, NewKeyRotator(reopenedKM, reopenedRepo).Recover())
+	assert.False(t, reopenedKM.RotationPending())
+
+	key, err := reopenedKM.LoadKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldKey, key)
+	loaded, err := reopenedRepo.Load()
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Entries, 1)
+}
+
+func TestKeyRotator_RecoverWithOtherSlot(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, _ := setupRotation(t)
+	km.AddSlot(SlotKeyfile, []byte("keyfile contents"))
+
+	vault, _ := repo.Load()
+	rotator := NewKeyRotator(km, repo)
+	assert.NoError(t, km.BeginRotation(testPassword))
+	data, _ := rotator.pending.encode(vault)
+	os.WriteFile(repo.vaultPath, data, VaultPermission)
+
+	other := newTestKeyManager(tmpDir)
+	assert.NoError(t, other.UnlockWith(SlotKeyfile, []byte("keyfile contents")))
+	otherRepo := NewFileVaultRepository(tmpDir, NewAESEncryptor(other))
+	err := NewKeyRotator(other, otherRepo).Recover()
+	assert.ErrorIs(t, err, ErrRotationPending)
+	assert.True(t, other.RotationPending())
+}
+
+func TestKeyRotator_RotateReencryptsBackups(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, _ := setupRotation(t)
+
+	vault, err := repo.Load()
+	assert.NoError(t, err)
+	vault.CreateEntry(domain.Entry{ID: "test-id-2", Title: "second"})
+	assert.NoError(t, repo.Save(vault))
+
+	backups, err := repo.Backups().List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	assert.NoError(t, NewKeyRotator(km, repo).Rotate(testPassword))
+
+	_, reopenedRepo := reopen(t, tmpDir)
+	restored, err := reopenedRepo.Restore(backups[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, backups[0].ID, restored.ID)
+
+	loaded, err := reopenedRepo.Load()
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Entries, 1)
+}
+
+func TestKeyRotator_RotateReencryptsMigrationBackups(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, _ := setupRotation(t)
+
+	plaintext := []byte(`{"version":"1.0","entries":{}}`)
+	encryptedData, err := repo.cryptoSvc.Encrypt(plaintext)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.backupBeforeMigration(encryptedData, "1.0"))
+
+	assert.NoError(t, NewKeyRotator(km, repo).Rotate(testPassword))
+
+	_, reopenedRepo := reopen(t, tmpDir)
+	data, err := os.ReadFile(reopenedRepo.migrationBackupPath("1.0"))
+	assert.NoError(t, err)
+	decrypted, err := reopenedRepo.cryptoSvc.Decrypt(data)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestKeyRotator_RotateReportsUndecryptableBackups(t *testing.T) {
+	t.Parallel()
+	tmpDir, km, repo, _ := setupRotation(t)
+	assert.NoError(t, repo.backupBeforeMigration([]byte("corrupted"), "1.0"))
+
+	err := NewKeyRotator(km, repo).Rotate(testPassword)
+	assert.ErrorIs(t, err, ErrBackupUndecryptable)
+	assert.False(t, km.RotationPending(), "the vault itself is rotated")
+
+	_, reopenedRepo := reopen(t, tmpDir)
+	_, err = reopenedRepo.Load()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	KeyfileSize      = 64
+	RecoveryCodeSize = 20
+)
+
+var (
+	ErrInvalidSlotSecret = errors.New("no key slot matches the given secret")
+	ErrSlotNotFound      = errors.New("key slot not found")
+	ErrLastKeySlot       = errors.New("cannot revoke the last key slot")
+	ErrUnknownSlotType   = errors.New("unknown key slot type")
+)
+
+type SlotType string
+
+const (
+	SlotPassword SlotType = "password"
+	SlotKeyfile  SlotType = "keyfile"
+	SlotRecovery SlotType = "recovery"
+)
+
+func ParseSlotType(s string) (SlotType, error) {
+	switch t := SlotType(strings.ToLower(s)); t {
+	case SlotPassword, SlotKeyfile, SlotRecovery:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSlotType, s)
+	}
+}
+
+// KeySlot holds a copy of the data key wrapped by a key derived from one
+// unlock secret.
+type KeySlot struct {
+	ID         string        `json:"id"`
+	Type       SlotType      `json:"type"`
+	KDF        KDFParams     `json:"kdf"`
+	WrappedKey EncryptedData `json:"wrapped_key"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// GenerateKeyfile writes a new random keyfile to path. Existing files are
+// never overwritten.
+func GenerateKeyfile(path string) error {
+	secret := make([]byte, KeyfileSize)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, KeyPermission)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(secret); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// GenerateRecoveryCode returns a random recovery code formatted in groups of
+// four base32 characters.
+func GenerateRecoveryCode() (string, error) {
+	raw := make([]byte, RecoveryCodeSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	var groups []string
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:min(i+4, len(encoded))])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// normalizeSecret makes recovery codes insensitive to case, spaces and
+// dashes. Other secrets are used as-is.
+func normalizeSecret(slotType SlotType, secret []byte) []byte {
+	if slotType != SlotRecovery {
+		return secret
+	}
+
+	normalized := make([]byte, 0, len(secret))
+	for _, c := range secret {
+		switch {
+		case c == '-' || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c >= 'a' && c <= 'z':
+			normalized = append(normalized, c-'a'+'A')
+		default:
+			normalized = append(normalized, c)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSlotType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   SlotType
+		hasErr bool
+	}{
+		{name: "succeed: password", input: "password", want: SlotPassword},
+		{name: "succeed: keyfile upper case", input: "KEYFILE", want: SlotKeyfile},
+		{name: "succeed: recovery", input: "recovery", want: SlotRecovery},
+		{name: "failed: unknown", input: "fingerprint", hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseSlotType(test.input)
+			if test.hasErr {
+				assert.ErrorIs(t, err, ErrUnknownSlotType)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	t.Parallel()
+	code, err := GenerateRecoveryCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z2-7]{4}(-[A-Z2-7]{4}){7}$`), code)
+
+	other, err := GenerateRecoveryCode()
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestGenerateKeyfile(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "vault.key")
+
+	assert.NoError(t, GenerateKeyfile(path))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, data, KeyfileSize)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(KeyPermission), info.Mode().Perm())
+
+	assert.Error(t, GenerateKeyfile(path), "existing keyfile must not be overwritten")
+}
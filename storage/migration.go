@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ritarock/passvault/domain"
+)
+
+var (
+	ErrVaultTooNew      = errors.New("vault was written by a newer version of passvault")
+	ErrMigrationMissing = errors.New("no migration path")
+)
+
+// Migration upgrades the decoded JSON document of a vault from one schema
+// version to the next. Migrate must not touch the "version" key; the
+// registry sets it after each step.
+type Migration struct {
+	From    string
+	To      string
+	Migrate func(doc map[string]any) error
+}
+
+// vaultMigrations lists every schema upgrade in order. Add a step here and
+// bump domain.CurrentVaultVersion whenever the vault JSON changes shape.
+var vaultMigrations = []Migration{
+	{
+		From: "1.0",
+		To:   "1.1",
+		// 1.1 adds the revision counter used for optimistic concurrency.
+		Migrate: func(doc map[string]any) error {
+			if _, ok := doc["revision"]; !ok {
+				doc["revision"] = 0
+			}
+			return nil
+		},
+	},
+	{
+		From: "1.1",
+		To:   "1.2",
+		// 1.2 adds password history. Existing entries start without one;
+		// the bump keeps older builds from dropping it on save.
+		Migrate: func(doc map[string]any) error {
+			return nil
+		},
+	},
+	{
+		From: "1.2",
+		To:   "1.3",
+		// 1.3 adds optional TOTP parameters to entries.
+		Migrate: func(doc map[string]any) error {
+			return nil
+		},
+	},
+	{
+		From: "1.3",
+		To:   "1.4",
+		// 1.4 adds optional custom fields to entries.
+		Migrate: func(doc map[string]any) error {
+			return nil
+		},
+	},
+	{
+		From: "1.4",
+		To:   "1.5",
+		// 1.5 adds optional tags to entries.
+		Migrate: func(doc map[string]any) error {
+			return nil
+		},
+	},
+	{
+		From: "1.5",
+		To:   "1.6",
+		// 1.6 adds the folder tree and a folder reference on entries.
+		Migrate: func(doc map[string]any) error {
+			if _, ok := doc["folders"]; !ok {
+				doc["folders"] = map[string]any{}
+			}
+			return nil
+		},
+	},
+	{
+		From: "1.6",
+		To:   "1.7",
+		// 1.7 adds the trash for deleted entries.
+		Migrate: func(doc map[string]any) error {
+			if _, ok := doc["trash"]; !ok {
+				doc["trash"] = map[string]any{}
+			}
+			return nil
+		},
+	},
+	{
+		From: "1.7",
+		To:   "1.8",
+		// 1.8 adds entry types. Every existing entry is a login.
+		Migrate: func(doc map[string]any) error {
+			setType := func(entry any) {
+				if entry, ok := entry.(map[string]any); ok {
+					if _, ok := entry["type"]; !ok {
+						entry["type"] = string(domain.EntryLogin)
+					}
+				}
+			}
+
+			entries, _ := doc["entries"].(map[string]any)
+			for _, entry := range entries {
+				setType(entry)
+			}
+			trash, _ := doc["trash"].(map[string]any)
+			for _, trashed := range trash {
+				if trashed, ok := trashed.(map[string]any); ok {
+					setType(trashed["entry"])
+				}
+			}
+			return nil
+		},
+	},
+}
+
+type MigrationRegistry struct {
+	current    string
+	migrations map[string]Migration
+}
+
+func NewMigrationRegistry(current string, migrations ...Migration) *MigrationRegistry {
+	registry := &MigrationRegistry{
+		current:    current,
+		migrations: make(map[string]Migration, len(migrations)),
+	}
+	for _, m := range migrations {
+		registry.migrations[m.From] = m
+	}
+	return registry
+}
+
+func DefaultMigrationRegistry() *MigrationRegistry {
+	return NewMigrationRegistry(domain.CurrentVaultVersion, vaultMigrations...)
+}
+
+// NeedsMigration reports whether a vault of the given version has to be
+// upgraded. Vaults newer than the current version are rejected.
+func (r *MigrationRegistry) NeedsMigration(version string) (bool, error) {
+	cmp, err := compareVersions(normalizeVersion(version), r.current)
+	if err != nil {
+		return false, err
+	}
+	if cmp > 0 {
+		return false, fmt.Errorf("%w: vault version %s, supported %s", ErrVaultTooNew, version, r.current)
+	}
+	return cmp < 0, nil
+}
+
+// Migrate upgrades doc step by step until it reaches the current version.
+func (r *MigrationRegistry) Migrate(doc map[string]any) error {
+	version, _ := doc["version"].(string)
+	version = normalizeVersion(version)
+
+	for version != r.current {
+		if _, err := r.NeedsMigration(version); err != nil {
+			return err
+		}
+
+		m, ok := r.migrations[version]
+		if !ok {
+			return fmt.Errorf("%w from version %s", ErrMigrationMissing, version)
+		}
+
+		if err := m.Migrate(doc); err != nil {
+			return fmt.Errorf("failed to migrate vault from %s to %s: %w", m.From, m.To, err)
+		}
+
+		version = m.To
+		doc["version"] = version
+	}
+
+	return nil
+}
+
+// normalizeVersion treats vaults without a version as the first release.
+func normalizeVersion(version string) string {
+	if version == "" {
+		return "1.0"
+	}
+	return version
+}
+
+func compareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([2]int, error) {
+	var parsed [2]int
+
+	major, minor, ok := strings.Cut(version, ".")
+	if !ok {
+		return parsed, fmt.Errorf("invalid vault version: %q", version)
+	}
+
+	var err error
+	if parsed[0], err = strconv.Atoi(major); err != nil {
+		return parsed, fmt.Errorf("invalid vault version: %q", version)
+	}
+	if parsed[1], err = strconv.Atoi(minor); err != nil {
+		return parsed, fmt.Errorf("invalid vault version: %q", version)
+	}
+
+	return parsed, nil
+}
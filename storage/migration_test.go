@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ritarock/passvault/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			From: "1.0",
+			To:   "1.1",
+			Migrate: func(doc map[string]any) error {
+				doc["steps"] = append(stepsOf(doc), "1.1")
+				return nil
+			},
+		},
+		{
+			From: "1.1",
+			To:   "1.2",
+			Migrate: func(doc map[string]any) error {
+				doc["steps"] = append(stepsOf(doc), "1.2")
+				return nil
+			},
+		},
+	}
+}
+
+func stepsOf(doc map[string]any) []any {
+	steps, _ := doc["steps"].([]any)
+	return steps
+}
+
+func TestMigrationRegistry_NeedsMigration(t *testing.T) {
+	t.Parallel()
+	registry := NewMigrationRegistry("1.2", testMigrations()...)
+	tests := []struct {
+		name    string
+		version string
+		want    bool
+		wantErr error
+	}{
+		{name: "succeed: older version", version: "1.0", want: true},
+		{name: "succeed: missing version", version: "", want: true},
+		{name: "succeed: current version", version: "1.2", want: false},
+		{name: "failed: newer minor version", version: "1.3", wantErr: ErrVaultTooNew},
+		{name: "failed: newer major version", version: "2.0", wantErr: ErrVaultTooNew},
+		{name: "failed: invalid version", version: "one", wantErr: errors.New("invalid vault version")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := registry.NeedsMigration(test.version)
+			if test.wantErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMigrationRegistry_Migrate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		registry  *MigrationRegistry
+		doc       map[string]any
+		wantSteps []any
+		wantErr   error
+	}{
+		{
+			name:      "succeed: migrate step by step",
+			registry:  NewMigrationRegistry("1.2", testMigrations()...),
+			doc:       map[string]any{"version": "1.0"},
+			wantSteps: []any{"1.1", "1.2"},
+		},
+		{
+			name:      "succeed: migrate from intermediate version",
+			registry:  NewMigrationRegistry("1.2", testMigrations()...),
+			doc:       map[string]any{"version": "1.1"},
+			wantSteps: []any{"1.2"},
+		},
+		{
+			name:      "succeed: already current",
+			registry:  NewMigrationRegistry("1.2", testMigrations()...),
+			doc:       map[string]any{"version": "1.2"},
+			wantSteps: nil,
+		},
+		{
+			name:     "failed: missing step",
+			registry: NewMigrationRegistry("1.2", testMigrations()[1:]...),
+			doc:      map[string]any{"version": "1.0"},
+			wantErr:  ErrMigrationMissing,
+		},
+		{
+			name:     "failed: too new",
+			registry: NewMigrationRegistry("1.2", testMigrations()...),
+			doc:      map[string]any{"version": "1.3"},
+			wantErr:  ErrVaultTooNew,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := test.registry.Migrate(test.doc)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "1.2", test.doc["version"])
+			assert.Equal(t, test.wantSteps, stepsOf(test.doc))
+		})
+	}
+}
+
+func TestDefaultMigrationRegistry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		doc   map[string]any
+		check func(*testing.T, map[string]any)
+	}{
+		{
+			name: "succeed: 1.0 gains a revision counter",
+			doc:  map[string]any{"version": "1.0", "entries": map[string]any{}},
+			check: func(t *testing.T, doc map[string]any) {
+				assert.Equal(t, 0, doc["revision"])
+			},
+		},
+		{
+			name: "succeed: existing revision is kept",
+			doc:  map[string]any{"version": "1.0", "revision": float64(7)},
+			check: func(t *testing.T, doc map[string]any) {
+				assert.Equal(t, float64(7), doc["revision"])
+			},
+		},
+		{
+			name: "succeed: 1.5 gains an empty folder tree",
+			doc:  map[string]any{"version": "1.5", "revision": float64(3), "entries": map[string]any{}},
+			check: func(t *testing.T, doc map[string]any) {
+				assert.Equal(t, map[string]any{}, doc["folders"])
+			},
+		},
+		{
+			name: "succeed: 1.6 gains an empty trash",
+			doc:  map[string]any{"version": "1.6", "revision": float64(3), "entries": map[string]any{}, "folders": map[string]any{}},
+			check: func(t *testing.T, doc map[string]any) {
+				assert.Equal(t, map[string]any{}, doc["trash"])
+			},
+		},
+		{
+			name: "succeed: 1.7 entries become logins",
+			doc: map[string]any{
+				"version":  "1.7",
+				"revision": float64(3),
+				"entries": map[string]any{
+					"e1": map[string]any{"id": "e1"},
+				},
+				"folders": map[string]any{},
+				"trash": map[string]any{
+					"e2": map[string]any{"entry": map[string]any{"id": "e2"}},
+				},
+			},
+			check: func(t *testing.T, doc map[string]any) {
+				entry := doc["entries"].(map[string]any)["e1"].(map[string]any)
+				assert.Equal(t, "login", entry["type"])
+				trashed := doc["trash"].(map[string]any)["e2"].(map[string]any)["entry"].(map[string]any)
+				assert.Equal(t, "login", trashed["type"])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := DefaultMigrationRegistry().Migrate(test.doc)
+			assert.NoError(t, err)
+			assert.Equal(t, domain.CurrentVaultVersion, test.doc["version"])
+			test.check(t, test.doc)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"fmt"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/ritarock/passvault/domain"
 	"github.com/ritarock/passvault/service"
@@ -8,17 +10,36 @@ import (
 )
 
 type App struct {
-	app           *tview.Application
-	pages         *tview.Pages
-	listView      *ListView
-	detailView    *DetailView
-	formView      *FormView
-	listEntriesUc *service.ListEntriesUsecase
-	getEntryUc    *service.GetEntryUsecase
-	createEntryUc *service.CreateEntryUsecase
-	updateEntryUc *service.UpdateEntryUsecase
-	deleteEntryUc *service.DeleteEntryUsecase
-	passwordGen   *domain.PasswordGenerator
+	app               *tview.Application
+	pages             *tview.Pages
+	listView          *ListView
+	detailView        *DetailView
+	formView          *FormView
+	trashView         *TrashView
+	auditView         *AuditView
+	listEntriesUc     *service.ListEntriesUsecase
+	getEntryUc        *service.GetEntryUsecase
+	createEntryUc     *service.CreateEntryUsecase
+	updateEntryUc     *service.UpdateEntryUsecase
+	deleteEntryUc     *service.DeleteEntryUsecase
+	restorePasswordUc *service.RestorePasswordUsecase
+	listTagsUc        *service.ListTagsUsecase
+	renameTagUc       *service.RenameTagUsecase
+	listFoldersUc     *service.ListFoldersUsecase
+	createFolderUc    *service.CreateFolderUsecase
+	renameFolderUc    *service.RenameFolderUsecase
+	moveFolderUc      *service.MoveFolderUsecase
+	deleteFolderUc    *service.DeleteFolderUsecase
+	moveEntryUc       *service.MoveEntryUsecase
+	listTrashUc       *service.ListTrashUsecase
+	restoreEntryUc    *service.RestoreEntryUsecase
+	purgeEntryUc      *service.PurgeEntryUsecase
+	purgeTrashUc      *service.PurgeTrashUsecase
+	auditVaultUc      *service.AuditVaultUsecase
+	auditOpts         domain.AuditOptions
+	passwordGen       *domain.PasswordGenerator
+	totpGen           *domain.TOTPGenerator
+	strengthEst       *domain.StrengthEstimator
 }
 
 func NewApp(
@@ -27,25 +48,62 @@ func NewApp(
 	createEntryUc *service.CreateEntryUsecase,
 	updateEntryUc *service.UpdateEntryUsecase,
 	deleteEntryUc *service.DeleteEntryUsecase,
+	restorePasswordUc *service.RestorePasswordUsecase,
+	listTagsUc *service.ListTagsUsecase,
+	renameTagUc *service.RenameTagUsecase,
+	listFoldersUc *service.ListFoldersUsecase,
+	createFolderUc *service.CreateFolderUsecase,
+	renameFolderUc *service.RenameFolderUsecase,
+	moveFolderUc *service.MoveFolderUsecase,
+	deleteFolderUc *service.DeleteFolderUsecase,
+	moveEntryUc *service.MoveEntryUsecase,
+	listTrashUc *service.ListTrashUsecase,
+	restoreEntryUc *service.RestoreEntryUsecase,
+	purgeEntryUc *service.PurgeEntryUsecase,
+	purgeTrashUc *service.PurgeTrashUsecase,
+	auditVaultUc *service.AuditVaultUsecase,
+	auditOpts domain.AuditOptions,
+	passwordGen *domain.PasswordGenerator,
 ) *App {
 	app := &App{
-		app:           tview.NewApplication(),
-		pages:         tview.NewPages(),
-		listEntriesUc: listEntriesUc,
-		getEntryUc:    getEntryUc,
-		createEntryUc: createEntryUc,
-		updateEntryUc: updateEntryUc,
-		deleteEntryUc: deleteEntryUc,
-		passwordGen:   domain.NewPasswordGenerator(),
+		app:               tview.NewApplication(),
+		pages:             tview.NewPages(),
+		listEntriesUc:     listEntriesUc,
+		getEntryUc:        getEntryUc,
+		createEntryUc:     createEntryUc,
+		updateEntryUc:     updateEntryUc,
+		deleteEntryUc:     deleteEntryUc,
+		restorePasswordUc: restorePasswordUc,
+		listTagsUc:        listTagsUc,
+		renameTagUc:       renameTagUc,
+		listFoldersUc:     listFoldersUc,
+		createFolderUc:    createFolderUc,
+		renameFolderUc:    renameFolderUc,
+		moveFolderUc:      moveFolderUc,
+		deleteFolderUc:    deleteFolderUc,
+		moveEntryUc:       moveEntryUc,
+		listTrashUc:       listTrashUc,
+		restoreEntryUc:    restoreEntryUc,
+		purgeEntryUc:      purgeEntryUc,
+		purgeTrashUc:      purgeTrashUc,
+		auditVaultUc:      auditVaultUc,
+		auditOpts:         auditOpts,
+		passwordGen:       passwordGen,
+		totpGen:           domain.NewTOTPGenerator(),
+		strengthEst:       domain.NewStrengthEstimator(),
 	}
 
 	app.listView = NewListView(app)
 	app.detailView = NewDetailView(app)
 	app.formView = NewFormView(app)
+	app.trashView = NewTrashView(app)
+	app.auditView = NewAuditView(app)
 
 	app.pages.AddPage("list", app.listView.GetPrimitive(), true, true)
 	app.pages.AddPage("detail", app.detailView.GetPrimitive(), true, false)
 	app.pages.AddPage("form", app.formView.GetPrimitive(), true, false)
+	app.pages.AddPage("trash", app.trashView.GetPrimitive(), true, false)
+	app.pages.AddPage("audit", app.auditView.GetPrimitive(), true, false)
 
 	app.app.SetRoot(app.pages, true)
 
@@ -61,6 +119,7 @@ func (a *App) Stop() {
 }
 
 func (a *App) ShowList() {
+	a.detailView.StopTOTPTicker()
 	a.listView.Refresh()
 	a.pages.SwitchToPage("list")
 }
@@ -71,10 +130,21 @@ func (a *App) ShowDetail(id string) {
 }
 
 func (a *App) ShowForm(id string) {
+	a.detailView.StopTOTPTicker()
 	a.formView.SetEntry(id)
 	a.pages.SwitchToPage("form")
 }
 
+func (a *App) ShowTrash() {
+	a.trashView.Refresh()
+	a.pages.SwitchToPage("trash")
+}
+
+func (a *App) ShowAudit() {
+	a.auditView.Refresh()
+	a.pages.SwitchToPage("audit")
+}
+
 func (a *App) ShowError(message string) {
 	modal := tview.NewModal().
 		SetText(message).
@@ -103,7 +173,7 @@ func (a *App) ShowConfirm(message string, onConfirm func()) {
 }
 
 func (a *App) ShowPasswordOptionsDialog(onGenerate func(domain.PasswordOptions)) {
-	dialog := NewPasswordOptionsDialog(
+	dialog := NewPasswordOptionsDialog(a,
 		func(opts domain.PasswordOptions) {
 			a.pages.RemovePage("password-options")
 			onGenerate(opts)
@@ -114,3 +184,35 @@ func (a *App) ShowPasswordOptionsDialog(onGenerate func(domain.PasswordOptions))
 	)
 	a.pages.AddPage("password-options", dialog.GetPrimitive(), true, true)
 }
+
+func (a *App) ShowInputDialog(title, label, initial string, onSubmit func(string)) {
+	dialog := NewInputDialog(title, label, initial,
+		func(text string) {
+			a.pages.RemovePage("input")
+			onSubmit(text)
+		},
+		func() {
+			a.pages.RemovePage("input")
+		},
+	)
+	a.pages.AddPage("input", dialog.GetPrimitive(), true, true)
+}
+
+func (a *App) ShowFolderPicker(title string, onSelect func(folderID string)) {
+	folders, err := a.listFoldersUc.Execute()
+	if err != nil {
+		a.ShowError(fmt.Sprintf("Failed to load folders: %v", err))
+		return
+	}
+
+	picker := NewFolderPicker(title, folders,
+		func(folderID string) {
+			a.pages.RemovePage("folder-picker")
+			onSelect(folderID)
+		},
+		func() {
+			a.pages.RemovePage("folder-picker")
+		},
+	)
+	a.pages.AddPage("folder-picker", picker.GetPrimitive(), true, true)
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ritarock/passvault/domain"
+	"github.com/rivo/tview"
+)
+
+type AuditView struct {
+	app       *App
+	container *tview.Flex
+	table     *tview.Table
+	help      *tview.TextView
+	findings  []domain.AuditFinding
+}
+
+func NewAuditView(app *App) *AuditView {
+	av := &AuditView{
+		app:   app,
+		table: tview.NewTable(),
+		help:  tview.NewTextView(),
+	}
+
+	av.setupTable()
+	av.setupHelp()
+	av.setupContainer()
+
+	return av
+}
+
+func (av *AuditView) setupTable() {
+	av.table.SetBorder(true).
+		SetTitle(" Audit ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(ColorPrimary)
+
+	av.table.SetSelectable(true, false)
+	av.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(ColorPrimary).
+		Foreground(tcell.ColorWhite))
+
+	av.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'e':
+			if finding := av.selected(); finding != nil {
+				av.app.ShowForm(finding.EntryID)
+			}
+			return nil
+		case 'r':
+			av.Refresh()
+			return nil
+		case 'q':
+			av.app.ShowList()
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEnter:
+			if finding := av.selected(); finding != nil {
+				av.app.ShowDetail(finding.EntryID)
+			}
+			return nil
+		case tcell.KeyEscape:
+			av.app.ShowList()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (av *AuditView) setupHelp() {
+	av.help.SetText("[Enter] View Entry  [e] Edit Entry  [r] Rerun  [ESC] Back").
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(ColorSecondary)
+}
+
+func (av *AuditView) setupContainer() {
+	av.container = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(av.table, 0, 1, true).
+		AddItem(av.help, 1, 0, false)
+}
+
+func (av *AuditView) GetPrimitive() tview.Primitive {
+	return av.container
+}
+
+func (av *AuditView) Refresh() {
+	report, err := av.app.auditVaultUc.Execute(av.app.auditOpts)
+	if err != nil {
+		av.app.ShowError(fmt.Sprintf("Failed to audit vault: %v", err))
+		return
+	}
+
+	av.findings = report.Findings
+
+	counts := make([]string, len(domain.AuditIssues))
+	for i, issue := range domain.AuditIssues {
+		counts[i] = fmt.Sprintf("%d %s", report.Count(issue), issue)
+	}
+	av.table.SetTitle(fmt.Sprintf(" Audit: %d entries, %s ", report.Entries, strings.Join(counts, ", ")))
+
+	av.renderTable()
+}
+
+func (av *AuditView) renderTable() {
+	av.table.Clear()
+
+	for col, header := range []string{"Issue", "Title", "Detail"} {
+		av.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(ColorPrimary).
+			SetSelectable(false))
+	}
+
+	for i, finding := range av.findings {
+		row := i + 1
+		av.table.SetCell(row, 0, tview.NewTableCell(finding.Issue.Label()).
+			SetTextColor(ColorDanger))
+		av.table.SetCell(row, 1, tview.NewTableCell(finding.Title).
+			SetTextColor(tcell.ColorWhite))
+		av.table.SetCell(row, 2, tview.NewTableCell(av.detail(finding)).
+			SetTextColor(ColorSecondary))
+	}
+
+	if len(av.findings) == 0 {
+		av.table.SetCell(1, 0, tview.NewTableCell("No issues found.").
+			SetTextColor(ColorSecondary).
+			SetAlign(tview.AlignCenter))
+	}
+
+	av.table.Select(1, 0)
+}
+
+// detail adds the titles of related entries to the finding detail.
+func (av *AuditView) detail(finding domain.AuditFinding) string {
+	var titles []string
+	for _, id := range finding.RelatedIDs {
+		for _, other := range av.findings {
+			if other.EntryID == id {
+				titles = append(titles, other.Title)
+				break
+			}
+		}
+	}
+	if len(titles) == 0 {
+		return finding.Detail
+	}
+	return fmt.Sprintf("%s: %s", finding.Detail, strings.Join(titles, ", "))
+}
+
+func (av *AuditView) selected() *domain.AuditFinding {
+	row, _ := av.table.GetSelection()
+	index := row - 1
+	if index < 0 || index >= len(av.findings) {
+		return nil
+	}
+	return &av.findings[index]
+}
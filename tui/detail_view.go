@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
@@ -13,21 +14,29 @@ import (
 )
 
 type DetailView struct {
-	app       *App
-	container *tview.Flex
-	textView  *tview.TextView
-	help      *tview.TextView
-	entry     *domain.Entry
+	app        *App
+	container  *tview.Flex
+	body       *tview.Flex
+	textView   *tview.TextView
+	history    *tview.List
+	help       *tview.TextView
+	entry      *domain.Entry
+	folderPath string
+	// breach is the breach warning for the password, if any.
+	breach   string
+	stopTOTP chan struct{}
 }
 
 func NewDetailView(app *App) *DetailView {
 	dv := &DetailView{
 		app:      app,
 		textView: tview.NewTextView(),
+		history:  tview.NewList(),
 		help:     tview.NewTextView(),
 	}
 
 	dv.setupTextView()
+	dv.setupHistory()
 	dv.setupHelp()
 	dv.setupContainer()
 
@@ -42,6 +51,15 @@ func (dv *DetailView) setupTextView() {
 		SetBorderColor(ColorPrimary)
 
 	dv.textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if dv.entry != nil {
+			for _, key := range typeCopyKeys[dv.entry.Type] {
+				if event.Rune() == key.key {
+					dv.copyValue(key.label, key.value(dv.entry))
+					return nil
+				}
+			}
+		}
+
 		switch event.Rune() {
 		case 'e':
 			if dv.entry != nil {
@@ -49,14 +67,27 @@ func (dv *DetailView) setupTextView() {
 			}
 			return nil
 		case 'c':
-			dv.copyPassword()
+			if dv.entry != nil && dv.entry.Type == domain.EntryLogin {
+				dv.copyPassword()
+			}
 			return nil
 		case 'u':
-			dv.copyUsername()
+			if dv.entry != nil && dv.entry.Type == domain.EntryLogin {
+				dv.copyUsername()
+			}
 			return nil
 		case 'o':
 			dv.openURL()
 			return nil
+		case 'h':
+			dv.showHistory()
+			return nil
+		case 't':
+			dv.copyTOTP()
+			return nil
+		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			dv.copyCustomField(int(event.Rune() - '1'))
+			return nil
 		}
 
 		switch event.Key() {
@@ -69,16 +100,100 @@ func (dv *DetailView) setupTextView() {
 	})
 }
 
+func (dv *DetailView) setupHistory() {
+	dv.history.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetBorder(true).
+		SetTitle(" Password History ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(ColorPrimary)
+
+	dv.history.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		dv.restorePassword(index)
+	})
+
+	dv.history.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'c':
+			dv.copyHistoryPassword(dv.history.GetCurrentItem())
+			return nil
+		case 'h':
+			dv.hideHistory()
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape:
+			dv.hideHistory()
+			return nil
+		}
+
+		return event
+	})
+}
+
 func (dv *DetailView) setupHelp() {
-	dv.help.SetText("[e] Edit  [u] Copy Username  [c] Copy Password  [o] Open URL  [ESC] Back").
+	dv.help.SetText(detailHelp).
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(ColorSecondary)
 }
 
+const (
+	detailHelp  = "[e] Edit  [u] Copy Username  [c] Copy Password  [t] Copy TOTP  [1-9] Copy Field  [o] Open URL  [h] History  [ESC] Back"
+	historyHelp = "[Enter] Restore  [c] Copy Password  [ESC] Close History"
+)
+
+// copyKey copies one value of a non-login entry.
+type copyKey struct {
+	key   rune
+	label string
+	value func(*domain.Entry) string
+}
+
+var typeCopyKeys = map[domain.EntryType][]copyKey{
+	domain.EntryNote: {
+		{'b', "Note", func(e *domain.Entry) string { return e.Notes }},
+	},
+	domain.EntryCard: {
+		{'n', "Card number", func(e *domain.Entry) string { return e.Card.Number }},
+		{'x', "Expiry", func(e *domain.Entry) string { return e.Card.Expiry }},
+		{'v', "CVV", func(e *domain.Entry) string { return e.Card.CVV }},
+	},
+	domain.EntryIdentity: {
+		{'n', "Name", func(e *domain.Entry) string { return e.Identity.Name }},
+		{'a', "Address", func(e *domain.Entry) string { return e.Identity.Address }},
+		{'p', "Phone", func(e *domain.Entry) string { return e.Identity.Phone }},
+	},
+	domain.EntrySSHKey: {
+		{'k', "Private key", func(e *domain.Entry) string { return e.SSHKey.PrivateKey }},
+		{'p', "Public key", func(e *domain.Entry) string { return e.SSHKey.PublicKey }},
+		{'c', "Passphrase", func(e *domain.Entry) string { return e.SSHKey.Passphrase }},
+	},
+}
+
+// helpText returns the key help for the type of the shown entry.
+func (dv *DetailView) helpText() string {
+	keys, ok := typeCopyKeys[dv.entry.Type]
+	if !ok {
+		return detailHelp
+	}
+
+	var help strings.Builder
+	help.WriteString("[e] Edit")
+	for _, key := range keys {
+		help.WriteString(fmt.Sprintf("  [%c] Copy %s", key.key, key.label))
+	}
+	help.WriteString("  [1-9] Copy Field  [ESC] Back")
+	return help.String()
+}
+
 func (dv *DetailView) setupContainer() {
+	dv.body = tview.NewFlex().
+		AddItem(dv.textView, 0, 1, true)
+
 	dv.container = tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(dv.textView, 0, 1, true).
+		AddItem(dv.body, 0, 1, true).
 		AddItem(dv.help, 1, 0, false)
 }
 
@@ -95,7 +210,69 @@ func (dv *DetailView) SetEntry(id string) {
 	}
 
 	dv.entry = entry
+	dv.folderPath = ""
+	if entry.FolderID != "" {
+		if folders, err := dv.app.listFoldersUc.Execute(); err == nil {
+			dv.folderPath = folderPaths(folders)[entry.FolderID]
+		}
+	}
+	dv.breach = dv.checkBreach()
+	dv.hideHistory()
+	dv.help.SetText(dv.helpText())
 	dv.render()
+	dv.startTOTPTicker()
+}
+
+// checkBreach looks the password of a login up in the breach database,
+// when one is configured.
+func (dv *DetailView) checkBreach() string {
+	breaches := dv.app.auditOpts.Breaches
+	if breaches == nil || dv.entry.Type != domain.EntryLogin || dv.entry.Password == "" {
+		return ""
+	}
+
+	count, err := breaches.Breached(dv.entry.Password)
+	if err != nil {
+		return fmt.Sprintf("[gray]Breach check failed: %s[-]", tview.Escape(err.Error()))
+	}
+	if count > 0 {
+		return fmt.Sprintf("[red]Seen %d times in data breaches, change this password[-]", count)
+	}
+	return ""
+}
+
+// startTOTPTicker redraws the view every second while an entry with a TOTP
+// secret is shown, so that the code and its countdown stay current.
+func (dv *DetailView) startTOTPTicker() {
+	dv.StopTOTPTicker()
+	if dv.entry == nil || dv.entry.TOTP == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	dv.stopTOTP = stop
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				dv.app.app.QueueUpdateDraw(dv.render)
+			}
+		}
+	}()
+}
+
+// StopTOTPTicker stops refreshing the TOTP code. It is called whenever the
+// detail view is left.
+func (dv *DetailView) StopTOTPTicker() {
+	if dv.stopTOTP != nil {
+		close(dv.stopTOTP)
+		dv.stopTOTP = nil
+	}
 }
 
 func (dv *DetailView) render() {
@@ -106,15 +283,35 @@ func (dv *DetailView) render() {
 	var content strings.Builder
 
 	content.WriteString(fmt.Sprintf("[::b]Title:[-:-:-]\n%s\n\n", dv.entry.Title))
+	content.WriteString(fmt.Sprintf("[::b]Type:[-:-:-]\n%s\n\n", dv.entry.Type.Label()))
+
+	switch dv.entry.Type {
+	case domain.EntryNote:
+		// The body of a secure note is rendered as its notes below.
+	case domain.EntryCard:
+		dv.renderCard(&content)
+	case domain.EntryIdentity:
+		dv.renderIdentity(&content)
+	case domain.EntrySSHKey:
+		dv.renderSSHKey(&content)
+	default:
+		dv.renderLogin(&content)
+	}
 
-	if dv.entry.Username != "" {
-		content.WriteString(fmt.Sprintf("[::b]Username:[-:-:-]\n%s\n\n", dv.entry.Username))
+	for i, field := range dv.entry.CustomFields {
+		value := field.Value
+		if field.IsHidden() {
+			value = maskPassword(value)
+		}
+		content.WriteString(fmt.Sprintf("[::b]%s:[-:-:-] [gray](%s, [%d[])[-]\n%s\n\n", tview.Escape(field.Name), field.Type, i+1, tview.Escape(value)))
 	}
 
-	content.WriteString(fmt.Sprintf("[::b]Password:[-:-:-]\n%s\n\n", maskPassword(dv.entry.Password)))
+	if dv.folderPath != "" {
+		content.WriteString(fmt.Sprintf("[::b]Folder:[-:-:-]\n%s\n\n", tview.Escape(dv.folderPath)))
+	}
 
-	if dv.entry.URL != "" {
-		content.WriteString(fmt.Sprintf("[::b]URL:[-:-:-]\n%s\n\n", dv.entry.URL))
+	if len(dv.entry.Tags) > 0 {
+		content.WriteString(fmt.Sprintf("[::b]Tags:[-:-:-]\n%s\n\n", tview.Escape(strings.Join(dv.entry.Tags, ", "))))
 	}
 
 	if dv.entry.Notes != "" {
@@ -127,6 +324,103 @@ func (dv *DetailView) render() {
 	dv.textView.SetText(content.String())
 }
 
+func (dv *DetailView) renderLogin(content *strings.Builder) {
+	if dv.entry.Username != "" {
+		content.WriteString(fmt.Sprintf("[::b]Username:[-:-:-]\n%s\n\n", dv.entry.Username))
+	}
+
+	content.WriteString(fmt.Sprintf("[::b]Password:[-:-:-]\n%s\n\n", maskPassword(dv.entry.Password)))
+	if dv.breach != "" {
+		content.WriteString(dv.breach + "\n\n")
+	}
+
+	if dv.entry.TOTP != nil {
+		now := time.Now()
+		code, err := dv.app.totpGen.Generate(dv.entry.TOTP, now)
+		if err != nil {
+			code = fmt.Sprintf("[red]%v[-]", err)
+		} else {
+			code = fmt.Sprintf("%s %s", code[:len(code)/2], code[len(code)/2:])
+		}
+		remaining := dv.app.totpGen.Remaining(dv.entry.TOTP, now)
+		content.WriteString(fmt.Sprintf("[::b]TOTP:[-:-:-]\n%s  (%ds)\n\n", code, int(remaining.Round(time.Second)/time.Second)))
+	}
+
+	if n := len(dv.entry.PasswordHistory); n > 0 {
+		content.WriteString(fmt.Sprintf("[::b]Password History:[-:-:-]\n%d previous password(s), press [h[] to show\n\n", n))
+	}
+
+	if dv.entry.URL != "" {
+		content.WriteString(fmt.Sprintf("[::b]URL:[-:-:-]\n%s\n\n", dv.entry.URL))
+	}
+}
+
+func (dv *DetailView) renderCard(content *strings.Builder) {
+	card := dv.entry.Card
+	if card == nil {
+		return
+	}
+
+	content.WriteString(fmt.Sprintf("[::b]Card Number:[-:-:-]\n%s\n\n", card.MaskedNumber()))
+	content.WriteString(fmt.Sprintf("[::b]Expiry:[-:-:-]\n%s\n\n", card.Expiry))
+	if card.CVV != "" {
+		content.WriteString(fmt.Sprintf("[::b]CVV:[-:-:-]\n%s\n\n", maskPassword(card.CVV)))
+	}
+}
+
+func (dv *DetailView) renderIdentity(content *strings.Builder) {
+	identity := dv.entry.Identity
+	if identity == nil {
+		return
+	}
+
+	content.WriteString(fmt.Sprintf("[::b]Name:[-:-:-]\n%s\n\n", tview.Escape(identity.Name)))
+	if identity.Address != "" {
+		content.WriteString(fmt.Sprintf("[::b]Address:[-:-:-]\n%s\n\n", tview.Escape(identity.Address)))
+	}
+	if identity.Phone != "" {
+		content.WriteString(fmt.Sprintf("[::b]Phone:[-:-:-]\n%s\n\n", tview.Escape(identity.Phone)))
+	}
+}
+
+func (dv *DetailView) renderSSHKey(content *strings.Builder) {
+	key := dv.entry.SSHKey
+	if key == nil {
+		return
+	}
+
+	content.WriteString("[::b]Private Key:[-:-:-]\n********\n\n")
+	if key.PublicKey != "" {
+		content.WriteString(fmt.Sprintf("[::b]Public Key:[-:-:-]\n%s\n\n", tview.Escape(key.PublicKey)))
+	}
+	if key.Passphrase != "" {
+		content.WriteString(fmt.Sprintf("[::b]Passphrase:[-:-:-]\n%s\n\n", maskPassword(key.Passphrase)))
+	}
+}
+
+// copyValue copies a value of a non-login entry to the clipboard.
+func (dv *DetailView) copyValue(label, value string) {
+	if value == "" {
+		dv.app.ShowError(fmt.Sprintf("No %s to copy", strings.ToLower(label)))
+		return
+	}
+
+	if err := clipboard.WriteAll(value); err != nil {
+		dv.app.ShowError(fmt.Sprintf("Failed to copy %s: %v", strings.ToLower(label), err))
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s copied to clipboard!", label)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			dv.app.pages.RemovePage("copied")
+		})
+	modal.SetBackgroundColor(tcell.ColorDefault)
+	modal.SetBorderColor(ColorSuccess)
+	dv.app.pages.AddPage("copied", modal, true, true)
+}
+
 func (dv *DetailView) copyPassword() {
 	if dv.entry == nil {
 		return
@@ -174,6 +468,65 @@ func (dv *DetailView) copyUsername() {
 	dv.app.pages.AddPage("copied", modal, true, true)
 }
 
+func (dv *DetailView) copyCustomField(index int) {
+	if dv.entry == nil {
+		return
+	}
+
+	if index >= len(dv.entry.CustomFields) {
+		dv.app.ShowError(fmt.Sprintf("No custom field %d", index+1))
+		return
+	}
+
+	field := dv.entry.CustomFields[index]
+	if err := clipboard.WriteAll(field.Value); err != nil {
+		dv.app.ShowError(fmt.Sprintf("Failed to copy %s: %v", field.Name, err))
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s copied to clipboard!", field.Name)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			dv.app.pages.RemovePage("copied")
+		})
+	modal.SetBackgroundColor(tcell.ColorDefault)
+	modal.SetBorderColor(ColorSuccess)
+	dv.app.pages.AddPage("copied", modal, true, true)
+}
+
+func (dv *DetailView) copyTOTP() {
+	if dv.entry == nil {
+		return
+	}
+
+	if dv.entry.TOTP == nil {
+		dv.app.ShowError("No TOTP secret for this entry")
+		return
+	}
+
+	code, err := dv.app.totpGen.Generate(dv.entry.TOTP, time.Now())
+	if err != nil {
+		dv.app.ShowError(fmt.Sprintf("Failed to generate TOTP code: %v", err))
+		return
+	}
+
+	if err := clipboard.WriteAll(code); err != nil {
+		dv.app.ShowError(fmt.Sprintf("Failed to copy TOTP code: %v", err))
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText("TOTP code copied to clipboard!").
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			dv.app.pages.RemovePage("copied")
+		})
+	modal.SetBackgroundColor(tcell.ColorDefault)
+	modal.SetBorderColor(ColorSuccess)
+	dv.app.pages.AddPage("copied", modal, true, true)
+}
+
 func (dv *DetailView) openURL() {
 	if dv.entry == nil {
 		return
@@ -213,6 +566,74 @@ func (dv *DetailView) openURL() {
 	dv.app.pages.AddPage("opened", modal, true, true)
 }
 
+func (dv *DetailView) showHistory() {
+	if dv.entry == nil {
+		return
+	}
+
+	if len(dv.entry.PasswordHistory) == 0 {
+		dv.app.ShowError("No password history")
+		return
+	}
+
+	dv.history.Clear()
+	for _, record := range dv.entry.PasswordHistory {
+		text := fmt.Sprintf("%s  %s", record.ReplacedAt.Format("2006-01-02 15:04:05"), maskPassword(record.Password))
+		dv.history.AddItem(text, "", 0, nil)
+	}
+
+	dv.body.Clear().
+		AddItem(dv.textView, 0, 1, false).
+		AddItem(dv.history, 0, 1, true)
+	dv.help.SetText(historyHelp)
+	dv.app.app.SetFocus(dv.history)
+}
+
+func (dv *DetailView) hideHistory() {
+	dv.body.Clear().
+		AddItem(dv.textView, 0, 1, true)
+	dv.help.SetText(detailHelp)
+	dv.app.app.SetFocus(dv.textView)
+}
+
+func (dv *DetailView) restorePassword(index int) {
+	if dv.entry == nil || index >= len(dv.entry.PasswordHistory) {
+		return
+	}
+
+	record := dv.entry.PasswordHistory[index]
+	message := fmt.Sprintf("Restore the password replaced on %s?", record.ReplacedAt.Format("2006-01-02 15:04:05"))
+	dv.app.ShowConfirm(message, func() {
+		if err := dv.app.restorePasswordUc.Execute(dv.entry.ID, index); err != nil {
+			dv.app.ShowError(fmt.Sprintf("Failed to restore password: %v", err))
+			return
+		}
+		dv.SetEntry(dv.entry.ID)
+	})
+}
+
+func (dv *DetailView) copyHistoryPassword(index int) {
+	if dv.entry == nil || index < 0 || index >= len(dv.entry.PasswordHistory) {
+		return
+	}
+
+	if err := clipboard.WriteAll(dv.entry.PasswordHistory[index].Password); err != nil {
+		dv.app.ShowError(fmt.Sprintf("Failed to copy password: %v", err))
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText("Previous password copied to clipboard!").
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			dv.app.pages.RemovePage("copied")
+			dv.app.app.SetFocus(dv.history)
+		})
+	modal.SetBackgroundColor(tcell.ColorDefault)
+	modal.SetBorderColor(ColorSuccess)
+	dv.app.pages.AddPage("copied", modal, true, true)
+}
+
 func maskPassword(password string) string {
 	return strings.Repeat("*", len(password))
 }
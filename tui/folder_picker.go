@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ritarock/passvault/domain"
+	"github.com/rivo/tview"
+)
+
+// FolderPicker lets the user choose a destination folder. The first item
+// is the top level.
+type FolderPicker struct {
+	list     *tview.List
+	modal    *tview.Flex
+	onSelect func(folderID string)
+	onCancel func()
+}
+
+func NewFolderPicker(title string, folders []*domain.Folder, onSelect func(string), onCancel func()) *FolderPicker {
+	fp := &FolderPicker{
+		list:     tview.NewList(),
+		onSelect: onSelect,
+		onCancel: onCancel,
+	}
+
+	fp.setupList(title, folders)
+	fp.setupModal()
+
+	return fp
+}
+
+func (fp *FolderPicker) setupList(title string, folders []*domain.Folder) {
+	fp.list.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetBorder(true).
+		SetTitle(" " + title + " ").
+		SetBorderColor(ColorPrimary)
+
+	fp.list.AddItem("(Top level)", "", 0, func() {
+		fp.onSelect("")
+	})
+
+	depths := folderDepths(folders)
+	for _, folder := range folders {
+		id := folder.ID
+		text := strings.Repeat("  ", depths[id]) + tview.Escape(folder.Name)
+		fp.list.AddItem(text, "", 0, func() {
+			fp.onSelect(id)
+		})
+	}
+
+	fp.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			fp.onCancel()
+			return nil
+		}
+		return event
+	})
+}
+
+func (fp *FolderPicker) setupModal() {
+	fp.modal = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(fp.list, 15, 0, true).
+			AddItem(nil, 0, 1, false), 50, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+func (fp *FolderPicker) GetPrimitive() tview.Primitive {
+	return fp.modal
+}
+
+// folderDepths returns the nesting level of each folder. folders must list
+// parents before their children.
+func folderDepths(folders []*domain.Folder) map[string]int {
+	depths := make(map[string]int, len(folders))
+	for _, folder := range folders {
+		if folder.ParentID != "" {
+			depths[folder.ID] = depths[folder.ParentID] + 1
+		}
+	}
+	return depths
+}
+
+// folderPaths returns the full path of each folder. folders must list
+// parents before their children.
+func folderPaths(folders []*domain.Folder) map[string]string {
+	paths := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		if parent, ok := paths[folder.ParentID]; ok {
+			paths[folder.ID] = parent + domain.FolderSeparator + folder.Name
+		} else {
+			paths[folder.ID] = folder.Name
+		}
+	}
+	return paths
+}
@@ -2,9 +2,12 @@ package tui
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/ritarock/passvault/domain"
+	"github.com/ritarock/passvault/service"
 	"github.com/rivo/tview"
 )
 
@@ -15,6 +18,11 @@ type FormView struct {
 	help      *tview.TextView
 	entryID   string
 	isEdit    bool
+	draft     service.EntryInput
+	knownTags []string
+	building  bool
+	// baseItems is the number of form items before the custom field rows.
+	baseItems int
 }
 
 func NewFormView(app *App) *FormView {
@@ -31,7 +39,7 @@ func NewFormView(app *App) *FormView {
 }
 
 func (fv *FormView) setupHelp() {
-	fv.help.SetText("[Tab] Next Field  [Shift+Tab] Previous Field  [Enter] Generate/Save  [ESC] Cancel").
+	fv.help.SetText("[Tab] Next Field  [Shift+Tab] Previous Field  [Enter] Select  [Ctrl+N] Add Field  [Ctrl+D] Remove Field  [Alt+Up/Down] Move Field  [ESC] Cancel").
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(ColorSecondary)
 }
@@ -70,21 +78,154 @@ func (fv *FormView) loadEntry() {
 		return
 	}
 
-	fv.setupFormFields(entry.Title, entry.Username, entry.Password, entry.URL, entry.Notes)
+	fv.setupFormFields(entry)
 }
 
 func (fv *FormView) setupNewForm() {
-	fv.setupFormFields("", "", "", "", "")
+	fv.setupFormFields(&domain.Entry{FolderID: fv.app.listView.selectedFolder})
 }
 
-func (fv *FormView) setupFormFields(title, username, password, url, notes string) {
-	fv.form.AddInputField("Title", title, 40, nil, nil)
-	fv.form.AddInputField("Username", username, 40, nil, nil)
-	fv.form.AddPasswordField("Password", password, 40, '*', nil)
-	fv.form.AddInputField("URL", url, 40, nil, nil)
-	fv.form.AddTextArea("Notes", notes, 40, 3, 0, nil)
+// customFieldItems is the number of form items in a custom field row: a
+// name, a type and a value.
+const customFieldItems = 3
 
-	fv.form.AddButton("Generate Password", fv.generatePassword)
+func (fv *FormView) setupFormFields(entry *domain.Entry) {
+	fv.draft = service.EntryInput{
+		Type: entry.Type,
+		Details: domain.EntryDetails{
+			Card:     &domain.Card{},
+			Identity: &domain.Identity{},
+			SSHKey:   &domain.SSHKey{},
+		},
+		Title:        entry.Title,
+		Username:     entry.Username,
+		Password:     entry.Password,
+		URL:          entry.URL,
+		Notes:        entry.Notes,
+		CustomFields: append([]domain.CustomField(nil), entry.CustomFields...),
+		Tags:         entry.Tags,
+		FolderID:     entry.FolderID,
+	}
+	if fv.draft.Type == "" {
+		fv.draft.Type = domain.EntryLogin
+	}
+	if entry.Card != nil {
+		*fv.draft.Details.Card = *entry.Card
+	}
+	if entry.Identity != nil {
+		*fv.draft.Details.Identity = *entry.Identity
+	}
+	if entry.SSHKey != nil {
+		*fv.draft.Details.SSHKey = *entry.SSHKey
+	}
+	if entry.TOTP != nil {
+		fv.draft.TOTP = entry.TOTP.String()
+	}
+
+	fv.knownTags = nil
+	if tags, err := fv.app.listTagsUc.Execute(); err == nil {
+		for _, tag := range tags {
+			fv.knownTags = append(fv.knownTags, tag.Name)
+		}
+	}
+
+	fv.buildForm(0)
+}
+
+// buildForm recreates the form items from the draft and focuses the item
+// at index. It is called again whenever custom field rows change.
+func (fv *FormView) buildForm(focus int) {
+	fv.building = true
+	defer func() { fv.building = false }()
+
+	fv.form.Clear(true)
+
+	typeLabels := make([]string, len(domain.EntryTypes))
+	for i, t := range domain.EntryTypes {
+		typeLabels[i] = t.Label()
+	}
+	fv.form.AddDropDown("Type", typeLabels, slices.Index(domain.EntryTypes, fv.draft.Type), func(option string, index int) {
+		if fv.building || index < 0 {
+			return
+		}
+		fv.collect()
+		fv.draft.Type = domain.EntryTypes[index]
+		fv.buildForm(0)
+	})
+	fv.form.AddInputField("Title", fv.draft.Title, 40, nil, nil)
+
+	details := fv.draft.Details
+	switch fv.draft.Type {
+	case domain.EntryNote:
+		fv.form.AddTextArea("Notes", fv.draft.Notes, 60, 10, 0, nil)
+	case domain.EntryCard:
+		fv.form.AddInputField("Card Number", details.Card.Number, 24, nil, nil)
+		fv.form.AddInputField("Expiry", details.Card.Expiry, 6, nil, nil)
+		fv.form.GetFormItemByLabel("Expiry").(*tview.InputField).SetPlaceholder("MM/YY")
+		fv.form.AddPasswordField("CVV", details.Card.CVV, 5, '*', nil)
+		fv.form.AddTextArea("Notes", fv.draft.Notes, 40, 3, 0, nil)
+	case domain.EntryIdentity:
+		fv.form.AddInputField("Full Name", details.Identity.Name, 40, nil, nil)
+		fv.form.AddTextArea("Address", details.Identity.Address, 40, 3, 0, nil)
+		fv.form.AddInputField("Phone", details.Identity.Phone, 20, nil, nil)
+		fv.form.AddTextArea("Notes", fv.draft.Notes, 40, 3, 0, nil)
+	case domain.EntrySSHKey:
+		fv.form.AddTextArea("Private Key", details.SSHKey.PrivateKey, 70, 6, 0, nil)
+		fv.form.AddTextArea("Public Key", details.SSHKey.PublicKey, 70, 2, 0, nil)
+		fv.form.AddPasswordField("Passphrase", details.SSHKey.Passphrase, 40, '*', nil)
+		fv.form.AddTextArea("Notes", fv.draft.Notes, 40, 3, 0, nil)
+	default:
+		fv.form.AddInputField("Username", fv.draft.Username, 40, nil, nil)
+		fv.form.AddPasswordField("Password", fv.draft.Password, 40, '*', fv.updateStrength)
+		fv.form.AddTextView("Strength", "", 60, 2, true, false)
+		fv.form.AddInputField("URL", fv.draft.URL, 40, nil, nil)
+		fv.form.AddTextArea("Notes", fv.draft.Notes, 40, 3, 0, nil)
+		fv.form.AddPasswordField("TOTP Secret", fv.draft.TOTP, 40, '*', nil)
+		fv.updateStrength(fv.draft.Password)
+	}
+
+	tagsField := tview.NewInputField().
+		SetLabel("Tags").
+		SetFieldWidth(40).
+		SetText(strings.Join(fv.draft.Tags, ", ")).
+		SetPlaceholder("comma separated")
+	tagsField.SetAutocompleteFunc(func(text string) []string {
+		if fv.app.app.GetFocus() != tagsField {
+			return nil
+		}
+		return fv.completeTags(text)
+	})
+	fv.form.AddFormItem(tagsField)
+	fv.baseItems = fv.form.GetFormItemCount()
+
+	typeOptions := make([]string, len(domain.FieldTypes))
+	for i, t := range domain.FieldTypes {
+		typeOptions[i] = string(t)
+	}
+
+	for i, field := range fv.draft.CustomFields {
+		row := i
+		fv.form.AddInputField(customFieldLabel(row, "Name"), field.Name, 40, nil, nil)
+		fv.form.AddDropDown(customFieldLabel(row, "Type"), typeOptions, slices.Index(domain.FieldTypes, field.Type), func(option string, index int) {
+			if fv.building {
+				return
+			}
+			// Hidden values are masked, so the value item has to be
+			// recreated when the type changes.
+			fv.collect()
+			fv.buildForm(fv.baseItems + row*customFieldItems + 1)
+		})
+		if field.IsHidden() {
+			fv.form.AddPasswordField(customFieldLabel(row, "Value"), field.Value, 40, '*', nil)
+		} else {
+			fv.form.AddInputField(customFieldLabel(row, "Value"), field.Value, 40, nil, nil)
+		}
+	}
+
+	if fv.draft.Type == domain.EntryLogin {
+		fv.form.AddButton("Generate Password", fv.generatePassword)
+	}
+	fv.form.AddButton("Add Field", fv.addCustomField)
 	fv.form.AddButton("Save", fv.save)
 	fv.form.AddButton("Cancel", func() {
 		fv.app.ShowList()
@@ -92,35 +233,219 @@ func (fv *FormView) setupFormFields(title, username, password, url, notes string
 
 	fv.form.SetButtonsAlign(tview.AlignCenter)
 	fv.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
+		switch event.Key() {
+		case tcell.KeyEscape:
 			fv.app.ShowList()
 			return nil
+		case tcell.KeyCtrlN:
+			fv.addCustomField()
+			return nil
+		case tcell.KeyCtrlD:
+			fv.removeCustomField()
+			return nil
+		case tcell.KeyUp:
+			if event.Modifiers()&tcell.ModAlt != 0 {
+				fv.moveCustomField(-1)
+				return nil
+			}
+		case tcell.KeyDown:
+			if event.Modifiers()&tcell.ModAlt != 0 {
+				fv.moveCustomField(1)
+				return nil
+			}
 		}
 		return event
 	})
+
+	fv.form.SetFocus(focus)
+	fv.app.app.SetFocus(fv.form)
+}
+
+// text returns the text of the input field or text area with the label.
+func (fv *FormView) text(label string) string {
+	switch item := fv.form.GetFormItemByLabel(label).(type) {
+	case *tview.InputField:
+		return item.GetText()
+	case *tview.TextArea:
+		return item.GetText()
+	}
+	return ""
+}
+
+// strengthColors maps a strength score to the color of the meter.
+var strengthColors = []string{"red", "orangered", "yellow", "yellowgreen", "green"}
+
+// updateStrength renders the strength meter for password. The title,
+// username and URL count as guessable inputs.
+func (fv *FormView) updateStrength(password string) {
+	meter, ok := fv.form.GetFormItemByLabel("Strength").(*tview.TextView)
+	if !ok {
+		return
+	}
+	if password == "" {
+		meter.SetText("")
+		return
+	}
+
+	strength := fv.app.strengthEst.Estimate(password, fv.text("Title"), fv.text("Username"), fv.text("URL"))
+	color := strengthColors[strength.Score]
+	bar := strings.Repeat("█", (strength.Score+1)*4) + strings.Repeat("░", (4-strength.Score)*4)
+	text := fmt.Sprintf("[%s]%s[-] %s, cracked in %s", color, bar, strength.Label(), strength.CrackTime())
+	if strength.Warning != "" {
+		text += "\n[" + color + "]" + tview.Escape(strength.Warning) + "[-]"
+	}
+	meter.SetText(text)
+}
+
+func customFieldLabel(row int, part string) string {
+	return fmt.Sprintf("Field %d %s", row+1, part)
+}
+
+// collect copies the form contents into the draft. Fields that are not on
+// the form for the current type keep their draft values.
+func (fv *FormView) collect() {
+	fv.draft.Title = fv.text("Title")
+	fv.draft.Notes = fv.text("Notes")
+	fv.draft.Tags = splitTags(fv.text("Tags"))
+
+	details := fv.draft.Details
+	switch fv.draft.Type {
+	case domain.EntryCard:
+		details.Card.Number = fv.text("Card Number")
+		details.Card.Expiry = fv.text("Expiry")
+		details.Card.CVV = fv.text("CVV")
+	case domain.EntryIdentity:
+		details.Identity.Name = fv.text("Full Name")
+		details.Identity.Address = fv.text("Address")
+		details.Identity.Phone = fv.text("Phone")
+	case domain.EntrySSHKey:
+		details.SSHKey.PrivateKey = fv.text("Private Key")
+		details.SSHKey.PublicKey = fv.text("Public Key")
+		details.SSHKey.Passphrase = fv.text("Passphrase")
+	case domain.EntryLogin:
+		fv.draft.Username = fv.text("Username")
+		fv.draft.Password = fv.text("Password")
+		fv.draft.URL = fv.text("URL")
+		fv.draft.TOTP = fv.text("TOTP Secret")
+	}
+
+	for i := range fv.draft.CustomFields {
+		_, fieldType := fv.form.GetFormItemByLabel(customFieldLabel(i, "Type")).(*tview.DropDown).GetCurrentOption()
+		fv.draft.CustomFields[i] = domain.CustomField{
+			Name:  fv.form.GetFormItemByLabel(customFieldLabel(i, "Name")).(*tview.InputField).GetText(),
+			Type:  domain.FieldType(fieldType),
+			Value: fv.form.GetFormItemByLabel(customFieldLabel(i, "Value")).(*tview.InputField).GetText(),
+		}
+	}
+}
+
+// completeTags suggests known tags for the last, partially typed tag.
+func (fv *FormView) completeTags(text string) []string {
+	head, token := "", text
+	if i := strings.LastIndex(text, ","); i >= 0 {
+		head, token = text[:i+1]+" ", text[i+1:]
+	}
+
+	token = strings.ToLower(strings.TrimSpace(token))
+	if token == "" {
+		return nil
+	}
+
+	var entries []string
+	for _, tag := range fv.knownTags {
+		if strings.HasPrefix(tag, token) && tag != token {
+			entries = append(entries, head+tag)
+		}
+	}
+	return entries
+}
+
+func splitTags(text string) []string {
+	var tags []string
+	for _, tag := range strings.Split(text, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// focusedCustomField returns the custom field row that holds the focused
+// form item, or -1 if the focus is elsewhere.
+func (fv *FormView) focusedCustomField() int {
+	item, _ := fv.form.GetFocusedItemIndex()
+	if item < fv.baseItems {
+		return -1
+	}
+	row := (item - fv.baseItems) / customFieldItems
+	if row >= len(fv.draft.CustomFields) {
+		return -1
+	}
+	return row
+}
+
+func (fv *FormView) addCustomField() {
+	fv.collect()
+	fv.draft.CustomFields = append(fv.draft.CustomFields, domain.CustomField{Type: domain.FieldText})
+	fv.buildForm(fv.baseItems + (len(fv.draft.CustomFields)-1)*customFieldItems)
+}
+
+func (fv *FormView) removeCustomField() {
+	row := fv.focusedCustomField()
+	if row < 0 {
+		return
+	}
+
+	fv.collect()
+	fv.draft.CustomFields = slices.Delete(fv.draft.CustomFields, row, row+1)
+
+	focus := fv.baseItems - 1
+	if row > 0 {
+		focus = fv.baseItems + (row-1)*customFieldItems
+	}
+	fv.buildForm(focus)
+}
+
+func (fv *FormView) moveCustomField(delta int) {
+	row := fv.focusedCustomField()
+	target := row + delta
+	if row < 0 || target < 0 || target >= len(fv.draft.CustomFields) {
+		return
+	}
+
+	item, _ := fv.form.GetFocusedItemIndex()
+	fv.collect()
+	fields := fv.draft.CustomFields
+	fields[row], fields[target] = fields[target], fields[row]
+	fv.buildForm(item + delta*customFieldItems)
 }
 
 func (fv *FormView) save() {
-	title := fv.form.GetFormItemByLabel("Title").(*tview.InputField).GetText()
-	username := fv.form.GetFormItemByLabel("Username").(*tview.InputField).GetText()
-	password := fv.form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
-	url := fv.form.GetFormItemByLabel("URL").(*tview.InputField).GetText()
-	notes := fv.form.GetFormItemByLabel("Notes").(*tview.TextArea).GetText()
+	fv.collect()
+	input := fv.draft
+
+	// Rows that were added but left empty are dropped.
+	input.CustomFields = slices.DeleteFunc(slices.Clone(input.CustomFields), func(f domain.CustomField) bool {
+		return f.Name == "" && f.Value == ""
+	})
 
-	if title == "" {
+	if input.Title == "" {
 		fv.app.ShowError("Title is required")
 		return
 	}
-	if password == "" {
+	if input.Type == domain.EntryLogin && input.Password == "" {
 		fv.app.ShowError("Password is required")
 		return
 	}
+	if input.Type != domain.EntryLogin {
+		input.Username, input.Password, input.URL, input.TOTP = "", "", "", ""
+	}
 
 	var err error
 	if fv.isEdit {
-		err = fv.app.updateEntryUc.Execute(fv.entryID, title, username, password, url, notes)
+		err = fv.app.updateEntryUc.Execute(fv.entryID, input)
 	} else {
-		err = fv.app.createEntryUc.Execute(title, username, password, url, notes)
+		_, err = fv.app.createEntryUc.Execute(input)
 	}
 
 	if err != nil {
@@ -141,5 +466,6 @@ func (fv *FormView) generatePassword() {
 
 		passwordField := fv.form.GetFormItemByLabel("Password").(*tview.InputField)
 		passwordField.SetText(password)
+		fv.updateStrength(password)
 	})
 }
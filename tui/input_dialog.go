@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// InputDialog asks the user for a single line of text.
+type InputDialog struct {
+	form     *tview.Form
+	modal    *tview.Flex
+	onSubmit func(string)
+	onCancel func()
+}
+
+func NewInputDialog(title, label, initial string, onSubmit func(string), onCancel func()) *InputDialog {
+	id := &InputDialog{
+		form:     tview.NewForm(),
+		onSubmit: onSubmit,
+		onCancel: onCancel,
+	}
+
+	id.setupForm(title, label, initial)
+	id.setupModal()
+
+	return id
+}
+
+func (id *InputDialog) setupForm(title, label, initial string) {
+	id.form.SetTitle(" " + title + " ").
+		SetBorder(true).
+		SetBorderColor(ColorPrimary)
+
+	id.form.AddInputField(label, initial, 30, nil, nil)
+
+	id.form.AddButton("OK", func() {
+		id.onSubmit(id.form.GetFormItem(0).(*tview.InputField).GetText())
+	})
+
+	id.form.AddButton("Cancel", func() {
+		id.onCancel()
+	})
+
+	id.form.SetButtonsAlign(tview.AlignCenter)
+
+	id.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			id.onCancel()
+			return nil
+		}
+		return event
+	})
+}
+
+func (id *InputDialog) setupModal() {
+	id.modal = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(id.form, 7, 0, true).
+			AddItem(nil, 0, 1, false), 50, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+func (id *InputDialog) GetPrimitive() tview.Primitive {
+	return id.modal
+}
@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -13,21 +15,35 @@ type ListView struct {
 	app             *App
 	container       *tview.Flex
 	table           *tview.Table
+	folderTree      *tview.TreeView
+	tagList         *tview.List
 	help            *tview.TextView
 	searchField     *tview.InputField
 	entries         []*domain.Entry
 	filteredEntries []*domain.Entry
+	folders         []*domain.Folder
+	selectedFolder  string
+	typeFilter      domain.EntryType
+	tags            []domain.TagCount
+	selectedTags    map[string]bool
+	tagMatch        domain.TagMatch
 }
 
 func NewListView(app *App) *ListView {
 	lv := &ListView{
-		app:         app,
-		table:       tview.NewTable(),
-		help:        tview.NewTextView(),
-		searchField: tview.NewInputField(),
+		app:          app,
+		table:        tview.NewTable(),
+		folderTree:   tview.NewTreeView(),
+		tagList:      tview.NewList(),
+		help:         tview.NewTextView(),
+		searchField:  tview.NewInputField(),
+		selectedTags: make(map[string]bool),
+		tagMatch:     domain.TagMatchAll,
 	}
 
 	lv.setupTable()
+	lv.setupFolderTree()
+	lv.setupTagList()
 	lv.setupSearchField()
 	lv.setupHelp()
 	lv.setupContainer()
@@ -60,6 +76,24 @@ func (lv *ListView) setupTable() {
 		case '/':
 			lv.app.app.SetFocus(lv.searchField)
 			return nil
+		case 'f':
+			lv.app.app.SetFocus(lv.folderTree)
+			return nil
+		case 't':
+			lv.app.app.SetFocus(lv.tagList)
+			return nil
+		case 'm':
+			lv.moveSelected()
+			return nil
+		case 'T':
+			lv.app.ShowTrash()
+			return nil
+		case 'A':
+			lv.app.ShowAudit()
+			return nil
+		case 'y':
+			lv.cycleTypeFilter()
+			return nil
 		}
 
 		switch event.Key() {
@@ -72,6 +106,86 @@ func (lv *ListView) setupTable() {
 	})
 }
 
+func (lv *ListView) setupFolderTree() {
+	lv.folderTree.SetBorder(true).
+		SetTitle(" Folders ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(ColorPrimary)
+
+	lv.folderTree.SetChangedFunc(func(node *tview.TreeNode) {
+		lv.selectedFolder, _ = node.GetReference().(string)
+		lv.loadEntries()
+	})
+
+	lv.folderTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'n':
+			lv.createFolder()
+			return nil
+		case 'r':
+			lv.renameFolder()
+			return nil
+		case 'm':
+			lv.moveFolder()
+			return nil
+		case 'd':
+			lv.deleteFolder()
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyTab:
+			lv.app.app.SetFocus(lv.table)
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (lv *ListView) setupTagList() {
+	lv.tagList.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(ColorPrimary)
+
+	lv.tagList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		lv.toggleTag(index)
+	})
+
+	lv.tagList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case ' ':
+			lv.toggleTag(lv.tagList.GetCurrentItem())
+			return nil
+		case 'm':
+			if lv.tagMatch == domain.TagMatchAll {
+				lv.tagMatch = domain.TagMatchAny
+			} else {
+				lv.tagMatch = domain.TagMatchAll
+			}
+			lv.reload()
+			return nil
+		case 'c':
+			clear(lv.selectedTags)
+			lv.reload()
+			return nil
+		case 'r':
+			lv.renameSelectedTag()
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyTab:
+			lv.app.app.SetFocus(lv.table)
+			return nil
+		}
+
+		return event
+	})
+}
+
 func (lv *ListView) setupSearchField() {
 	lv.searchField.SetLabel(" Search: ").
 		SetFieldBackgroundColor(tcell.ColorBlack).
@@ -93,17 +207,27 @@ func (lv *ListView) setupSearchField() {
 }
 
 func (lv *ListView) setupHelp() {
-	lv.help.SetText("[/] Search  [a] Add  [Enter] View  [d] Delete  [q] Quit").
+	lv.help.SetText("[/] Search  [f] Folders  [t] Tags  [y] Type  [a] Add  [Enter] View  [m] Move  [d] Delete  [T] Trash  [A] Audit  [q] Quit\n" +
+		"Folders: [n] New  [r] Rename  [m] Move  [d] Delete  |  Tags: [Space] Toggle  [m] AND/OR  [c] Clear  [r] Rename/Merge").
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(ColorSecondary)
 }
 
 func (lv *ListView) setupContainer() {
+	sidebar := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(lv.folderTree, 0, 1, false).
+		AddItem(lv.tagList, 0, 1, false)
+
+	body := tview.NewFlex().
+		AddItem(sidebar, 28, 0, false).
+		AddItem(lv.table, 0, 1, true)
+
 	lv.container = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(lv.searchField, 1, 0, false).
-		AddItem(lv.table, 0, 1, true).
-		AddItem(lv.help, 1, 0, false)
+		AddItem(body, 0, 1, true).
+		AddItem(lv.help, 2, 0, false)
 }
 
 func (lv *ListView) GetPrimitive() tview.Primitive {
@@ -111,19 +235,267 @@ func (lv *ListView) GetPrimitive() tview.Primitive {
 }
 
 func (lv *ListView) Refresh() {
-	entries, err := lv.app.listEntriesUc.Execute()
+	lv.searchField.SetText("")
+	lv.reload()
+}
+
+// reload fetches the folders, the tags and the entries matching the
+// selected folder and tags, and reapplies the current search.
+func (lv *ListView) reload() {
+	folders, err := lv.app.listFoldersUc.Execute()
+	if err != nil {
+		lv.app.ShowError(fmt.Sprintf("Failed to load folders: %v", err))
+		return
+	}
+	lv.folders = folders
+	if !slices.ContainsFunc(folders, func(f *domain.Folder) bool { return f.ID == lv.selectedFolder }) {
+		lv.selectedFolder = ""
+	}
+
+	tags, err := lv.app.listTagsUc.Execute()
+	if err != nil {
+		lv.app.ShowError(fmt.Sprintf("Failed to load tags: %v", err))
+		return
+	}
+	lv.tags = tags
+
+	known := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		known[tag.Name] = true
+	}
+	for tag := range lv.selectedTags {
+		if !known[tag] {
+			delete(lv.selectedTags, tag)
+		}
+	}
+
+	lv.renderFolders()
+	lv.renderTags()
+	lv.loadEntries()
+}
+
+// loadEntries fetches the entries matching the selected folder and tags.
+func (lv *ListView) loadEntries() {
+	var selected []string
+	for tag := range lv.selectedTags {
+		selected = append(selected, tag)
+	}
+	slices.Sort(selected)
+
+	entries, err := lv.app.listEntriesUc.ExecuteWithFilter(domain.EntryFilter{
+		FolderID: lv.selectedFolder,
+		Tags:     selected,
+		TagMatch: lv.tagMatch,
+		Type:     lv.typeFilter,
+	})
 	if err != nil {
 		lv.app.ShowError(fmt.Sprintf("Failed to load entries: %v", err))
 		return
 	}
 
 	lv.entries = entries
-	lv.searchField.SetText("")
-	lv.filterEntries("")
-
+	lv.filterEntries(lv.searchField.GetText())
 	lv.renderTable()
 }
 
+func (lv *ListView) renderFolders() {
+	root := tview.NewTreeNode("All Entries").
+		SetReference("").
+		SetColor(ColorPrimary)
+	current := root
+
+	nodes := map[string]*tview.TreeNode{"": root}
+	for _, folder := range lv.folders {
+		node := tview.NewTreeNode(tview.Escape(folder.Name)).
+			SetReference(folder.ID)
+		nodes[folder.ID] = node
+		if parent, ok := nodes[folder.ParentID]; ok {
+			parent.AddChild(node)
+		}
+		if folder.ID == lv.selectedFolder {
+			current = node
+		}
+	}
+
+	lv.folderTree.SetRoot(root).SetCurrentNode(current)
+}
+
+func (lv *ListView) renderTags() {
+	current := lv.tagList.GetCurrentItem()
+	lv.tagList.Clear()
+
+	title := " Tags "
+	if len(lv.selectedTags) > 1 {
+		title = fmt.Sprintf(" Tags (%s) ", lv.tagMatch)
+	}
+	lv.tagList.SetTitle(title)
+
+	for _, tag := range lv.tags {
+		marker := "  "
+		if lv.selectedTags[tag.Name] {
+			marker = "✓ "
+		}
+		lv.tagList.AddItem(fmt.Sprintf("%s%s (%d)", marker, tview.Escape(tag.Name), tag.Count), "", 0, nil)
+	}
+
+	if current < lv.tagList.GetItemCount() {
+		lv.tagList.SetCurrentItem(current)
+	}
+}
+
+func (lv *ListView) toggleTag(index int) {
+	if index < 0 || index >= len(lv.tags) {
+		return
+	}
+
+	name := lv.tags[index].Name
+	if lv.selectedTags[name] {
+		delete(lv.selectedTags, name)
+	} else {
+		lv.selectedTags[name] = true
+	}
+	lv.reload()
+}
+
+func (lv *ListView) renameSelectedTag() {
+	index := lv.tagList.GetCurrentItem()
+	if index < 0 || index >= len(lv.tags) {
+		return
+	}
+
+	from := lv.tags[index].Name
+	lv.app.ShowInputDialog("Rename or Merge Tag", "New name", from, func(to string) {
+		_, err := lv.app.renameTagUc.Execute(from, to)
+		if err != nil {
+			lv.app.ShowError(fmt.Sprintf("Failed to rename tag: %v", err))
+			return
+		}
+
+		if lv.selectedTags[from] {
+			delete(lv.selectedTags, from)
+			if normalized, err := domain.NormalizeTag(to); err == nil {
+				lv.selectedTags[normalized] = true
+			}
+		}
+		lv.reload()
+		lv.app.app.SetFocus(lv.tagList)
+	})
+}
+
+// cycleTypeFilter steps the type filter through every entry type and back
+// to showing all types.
+func (lv *ListView) cycleTypeFilter() {
+	next := slices.Index(domain.EntryTypes, lv.typeFilter) + 1
+
+	title := " PassVault "
+	if next < len(domain.EntryTypes) {
+		lv.typeFilter = domain.EntryTypes[next]
+		title = fmt.Sprintf(" PassVault: %s ", lv.typeFilter.Label())
+	} else {
+		lv.typeFilter = ""
+	}
+	lv.table.SetTitle(title)
+	lv.loadEntries()
+}
+
+// currentFolder returns the folder highlighted in the tree, or nil for the
+// root.
+func (lv *ListView) currentFolder() *domain.Folder {
+	for _, folder := range lv.folders {
+		if folder.ID == lv.selectedFolder {
+			return folder
+		}
+	}
+	return nil
+}
+
+func (lv *ListView) createFolder() {
+	parentID := lv.selectedFolder
+	lv.app.ShowInputDialog("New Folder", "Name", "", func(name string) {
+		folder, err := lv.app.createFolderUc.Execute(name, parentID)
+		if err != nil {
+			lv.app.ShowError(fmt.Sprintf("Failed to create folder: %v", err))
+			return
+		}
+
+		lv.selectedFolder = folder.ID
+		lv.reload()
+		lv.app.app.SetFocus(lv.folderTree)
+	})
+}
+
+func (lv *ListView) renameFolder() {
+	folder := lv.currentFolder()
+	if folder == nil {
+		return
+	}
+
+	lv.app.ShowInputDialog("Rename Folder", "Name", folder.Name, func(name string) {
+		if err := lv.app.renameFolderUc.Execute(folder.ID, name); err != nil {
+			lv.app.ShowError(fmt.Sprintf("Failed to rename folder: %v", err))
+			return
+		}
+
+		lv.reload()
+		lv.app.app.SetFocus(lv.folderTree)
+	})
+}
+
+func (lv *ListView) moveFolder() {
+	folder := lv.currentFolder()
+	if folder == nil {
+		return
+	}
+
+	lv.app.ShowFolderPicker(fmt.Sprintf("Move '%s' to", folder.Name), func(parentID string) {
+		if err := lv.app.moveFolderUc.Execute(folder.ID, parentID); err != nil {
+			lv.app.ShowError(fmt.Sprintf("Failed to move folder: %v", err))
+			return
+		}
+
+		lv.reload()
+		lv.app.app.SetFocus(lv.folderTree)
+	})
+}
+
+func (lv *ListView) deleteFolder() {
+	folder := lv.currentFolder()
+	if folder == nil {
+		return
+	}
+
+	deleted := func() {
+		lv.selectedFolder = folder.ParentID
+		lv.reload()
+		lv.app.app.SetFocus(lv.folderTree)
+	}
+
+	lv.app.ShowConfirm(
+		fmt.Sprintf("Delete folder '%s'?", folder.Name),
+		func() {
+			err := lv.app.deleteFolderUc.Execute(folder.ID, false)
+			if errors.Is(err, domain.ErrFolderNotEmpty) {
+				lv.app.ShowConfirm(
+					fmt.Sprintf("'%s' is not empty. Delete it with all of its folders and move its entries to the trash?", folder.Name),
+					func() {
+						if err := lv.app.deleteFolderUc.Execute(folder.ID, true); err != nil {
+							lv.app.ShowError(fmt.Sprintf("Failed to delete folder: %v", err))
+							return
+						}
+						deleted()
+					},
+				)
+				return
+			}
+			if err != nil {
+				lv.app.ShowError(fmt.Sprintf("Failed to delete folder: %v", err))
+				return
+			}
+			deleted()
+		},
+	)
+}
+
 func (lv *ListView) filterEntries(query string) {
 	if query == "" {
 		lv.filteredEntries = lv.entries
@@ -135,7 +507,8 @@ func (lv *ListView) filterEntries(query string) {
 	for _, entry := range lv.entries {
 		if strings.Contains(strings.ToLower(entry.Title), query) ||
 			strings.Contains(strings.ToLower(entry.Username), query) ||
-			strings.Contains(strings.ToLower(entry.URL), query) {
+			strings.Contains(strings.ToLower(entry.URL), query) ||
+			slices.ContainsFunc(entry.Tags, func(tag string) bool { return strings.Contains(tag, query) }) {
 			lv.filteredEntries = append(lv.filteredEntries, entry)
 		}
 	}
@@ -147,16 +520,22 @@ func (lv *ListView) renderTable() {
 	lv.table.SetCell(0, 0, tview.NewTableCell("Title").
 		SetTextColor(ColorPrimary).
 		SetSelectable(false))
-	lv.table.SetCell(0, 1, tview.NewTableCell("Username").
+	lv.table.SetCell(0, 1, tview.NewTableCell("Type").
 		SetTextColor(ColorPrimary).
 		SetSelectable(false))
-	lv.table.SetCell(0, 2, tview.NewTableCell("URL").
+	lv.table.SetCell(0, 2, tview.NewTableCell("Username").
 		SetTextColor(ColorPrimary).
 		SetSelectable(false))
-	lv.table.SetCell(0, 3, tview.NewTableCell("Notes").
+	lv.table.SetCell(0, 3, tview.NewTableCell("URL").
 		SetTextColor(ColorPrimary).
 		SetSelectable(false))
-	lv.table.SetCell(0, 4, tview.NewTableCell("CreatedAt").
+	lv.table.SetCell(0, 4, tview.NewTableCell("Notes").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+	lv.table.SetCell(0, 5, tview.NewTableCell("Tags").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+	lv.table.SetCell(0, 6, tview.NewTableCell("CreatedAt").
 		SetTextColor(ColorPrimary).
 		SetSelectable(false))
 
@@ -164,13 +543,17 @@ func (lv *ListView) renderTable() {
 		row := i + 1
 		lv.table.SetCell(row, 0, tview.NewTableCell(entry.Title).
 			SetTextColor(tcell.ColorWhite))
-		lv.table.SetCell(row, 1, tview.NewTableCell(entry.Username).
+		lv.table.SetCell(row, 1, tview.NewTableCell(entry.Type.Label()).
+			SetTextColor(ColorSecondary))
+		lv.table.SetCell(row, 2, tview.NewTableCell(entrySubtitle(entry)).
+			SetTextColor(ColorSecondary))
+		lv.table.SetCell(row, 3, tview.NewTableCell(entry.URL).
 			SetTextColor(ColorSecondary))
-		lv.table.SetCell(row, 2, tview.NewTableCell(entry.URL).
+		lv.table.SetCell(row, 4, tview.NewTableCell(entry.Notes).
 			SetTextColor(ColorSecondary))
-		lv.table.SetCell(row, 3, tview.NewTableCell(entry.Notes).
+		lv.table.SetCell(row, 5, tview.NewTableCell(strings.Join(entry.Tags, ", ")).
 			SetTextColor(ColorSecondary))
-		lv.table.SetCell(row, 4, tview.NewTableCell(entry.CreatedAt.Format("2006-01-02 15:04")).
+		lv.table.SetCell(row, 6, tview.NewTableCell(entry.CreatedAt.Format("2006-01-02 15:04")).
 			SetTextColor(ColorSecondary))
 	}
 
@@ -187,6 +570,19 @@ func (lv *ListView) renderTable() {
 	lv.table.Select(1, 0)
 }
 
+// entrySubtitle returns what identifies an entry besides its title: the
+// username of a login, or the type-specific equivalent.
+func entrySubtitle(entry *domain.Entry) string {
+	switch {
+	case entry.Card != nil:
+		return entry.Card.MaskedNumber()
+	case entry.Identity != nil:
+		return entry.Identity.Name
+	default:
+		return entry.Username
+	}
+}
+
 func (lv *ListView) viewSelected() {
 	if len(lv.filteredEntries) == 0 {
 		return
@@ -205,6 +601,31 @@ func (lv *ListView) viewSelected() {
 	lv.app.ShowDetail(lv.filteredEntries[index].ID)
 }
 
+func (lv *ListView) moveSelected() {
+	if len(lv.filteredEntries) == 0 {
+		return
+	}
+
+	row, _ := lv.table.GetSelection()
+	if row < 1 {
+		return
+	}
+
+	index := row - 1
+	if index >= len(lv.filteredEntries) {
+		return
+	}
+
+	entry := lv.filteredEntries[index]
+	lv.app.ShowFolderPicker(fmt.Sprintf("Move '%s' to", entry.Title), func(folderID string) {
+		if err := lv.app.moveEntryUc.Execute(entry.ID, folderID); err != nil {
+			lv.app.ShowError(fmt.Sprintf("Failed to move entry: %v", err))
+			return
+		}
+		lv.reload()
+	})
+}
+
 func (lv *ListView) deleteSelected() {
 	if len(lv.filteredEntries) == 0 {
 		return
@@ -222,7 +643,7 @@ func (lv *ListView) deleteSelected() {
 
 	entry := lv.filteredEntries[index]
 	lv.app.ShowConfirm(
-		fmt.Sprintf("Delete '%s'?", entry.Title),
+		fmt.Sprintf("Move '%s' to the trash?", entry.Title),
 		func() {
 			if err := lv.app.deleteEntryUc.Execute(entry.ID); err != nil {
 				lv.app.ShowError(fmt.Sprintf("Failed to delete entry: %v", err))
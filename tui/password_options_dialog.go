@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/gdamore/tcell/v2"
@@ -8,16 +10,21 @@ import (
 	"github.com/rivo/tview"
 )
 
+var passwordModes = []domain.PasswordMode{domain.PasswordModeCharacters, domain.PasswordModePassphrase}
+
 type PasswordOptionsDialog struct {
+	app        *App
 	form       *tview.Form
 	modal      *tview.Flex
 	options    domain.PasswordOptions
 	onGenerate func(domain.PasswordOptions)
 	onCancel   func()
+	building   bool
 }
 
-func NewPasswordOptionsDialog(onGenerate func(domain.PasswordOptions), onCancel func()) *PasswordOptionsDialog {
+func NewPasswordOptionsDialog(app *App, onGenerate func(domain.PasswordOptions), onCancel func()) *PasswordOptionsDialog {
 	pod := &PasswordOptionsDialog{
+		app:        app,
 		form:       tview.NewForm(),
 		options:    domain.DefaultPasswordOptions(),
 		onGenerate: onGenerate,
@@ -30,55 +37,152 @@ func NewPasswordOptionsDialog(onGenerate func(domain.PasswordOptions), onCancel
 	return pod
 }
 
+// digitsOnly accepts up to maxLen digits in an input field.
+func digitsOnly(maxLen int) func(text string, lastChar rune) bool {
+	return func(text string, lastChar rune) bool {
+		if lastChar < '0' || lastChar > '9' {
+			return false
+		}
+		return len(text) <= maxLen
+	}
+}
+
 func (pod *PasswordOptionsDialog) setupForm() {
 	pod.form.SetTitle(" Password Options ").
 		SetBorder(true).
 		SetBorderColor(ColorPrimary)
 
-	pod.form.AddInputField("Length (8-64)", strconv.Itoa(pod.options.Length), 10, func(text string, lastChar rune) bool {
-		if lastChar < '0' || lastChar > '9' {
-			return false
+	pod.form.SetButtonsAlign(tview.AlignCenter)
+	pod.form.SetItemPadding(0)
+
+	pod.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			pod.onCancel()
+			return nil
 		}
-		return len(text) <= 2
-	}, func(text string) {
+		return event
+	})
+
+	pod.buildForm()
+}
+
+// buildForm recreates the form items for the selected mode.
+func (pod *PasswordOptionsDialog) buildForm() {
+	pod.building = true
+	defer func() { pod.building = false }()
+
+	pod.form.Clear(true)
+
+	pod.form.AddDropDown("Mode", []string{"Characters", "Passphrase"}, slices.Index(passwordModes, pod.options.Mode), func(option string, index int) {
+		if pod.building || index < 0 {
+			return
+		}
+		pod.options.Mode = passwordModes[index]
+		pod.buildForm()
+		pod.app.app.SetFocus(pod.form)
+	})
+
+	if pod.options.Mode == domain.PasswordModePassphrase {
+		pod.addPassphraseFields()
+	} else {
+		pod.addCharacterFields()
+	}
+
+	pod.form.AddTextView("Entropy", "", 0, 2, true, false)
+	pod.updateEntropy()
+
+	pod.form.AddButton("Generate", func() {
+		pod.onGenerate(pod.options)
+	})
+
+	pod.form.AddButton("Cancel", func() {
+		pod.onCancel()
+	})
+}
+
+func (pod *PasswordOptionsDialog) addCharacterFields() {
+	pod.form.AddInputField("Length (8-64)", strconv.Itoa(pod.options.Length), 10, digitsOnly(2), func(text string) {
 		if length, err := strconv.Atoi(text); err == nil {
 			pod.options.Length = length
+			pod.updateEntropy()
 		}
 	})
 
-	pod.form.AddCheckbox("Include lowercase (a-z)", pod.options.IncludeLowercase, func(checked bool) {
-		pod.options.IncludeLowercase = checked
-	})
+	pod.addClassFields("Include lowercase (a-z)", &pod.options.IncludeLowercase, &pod.options.MinLowercase)
+	pod.addClassFields("Include uppercase (A-Z)", &pod.options.IncludeUppercase, &pod.options.MinUppercase)
+	pod.addClassFields("Include digits (0-9)", &pod.options.IncludeDigits, &pod.options.MinDigits)
+	pod.addClassFields("Include symbols (!@#$...)", &pod.options.IncludeSymbols, &pod.options.MinSymbols)
 
-	pod.form.AddCheckbox("Include uppercase (A-Z)", pod.options.IncludeUppercase, func(checked bool) {
-		pod.options.IncludeUppercase = checked
+	pod.form.AddInputField("Symbol set", pod.options.Symbols, 20, nil, func(text string) {
+		pod.options.Symbols = text
+		pod.updateEntropy()
 	})
 
-	pod.form.AddCheckbox("Include digits (0-9)", pod.options.IncludeDigits, func(checked bool) {
-		pod.options.IncludeDigits = checked
+	pod.form.AddCheckbox("Exclude ambiguous (0O1lI)", pod.options.ExcludeAmbiguous, func(checked bool) {
+		pod.options.ExcludeAmbiguous = checked
+		pod.updateEntropy()
 	})
 
-	pod.form.AddCheckbox("Include symbols (!@#$...)", pod.options.IncludeSymbols, func(checked bool) {
-		pod.options.IncludeSymbols = checked
+	pod.form.AddInputField("Exclude characters", pod.options.ExcludeChars, 20, nil, func(text string) {
+		pod.options.ExcludeChars = text
+		pod.updateEntropy()
 	})
+}
 
-	pod.form.AddButton("Generate", func() {
-		pod.onGenerate(pod.options)
+// addClassFields adds the checkbox of a character class followed by its
+// minimum count.
+func (pod *PasswordOptionsDialog) addClassFields(label string, include *bool, min *int) {
+	pod.form.AddCheckbox(label, *include, func(checked bool) {
+		*include = checked
+		pod.updateEntropy()
 	})
 
-	pod.form.AddButton("Cancel", func() {
-		pod.onCancel()
+	pod.form.AddInputField("  at least", strconv.Itoa(*min), 4, digitsOnly(2), func(text string) {
+		// An empty field means no minimum.
+		*min, _ = strconv.Atoi(text)
+		pod.updateEntropy()
 	})
+}
 
-	pod.form.SetButtonsAlign(tview.AlignCenter)
+func (pod *PasswordOptionsDialog) addPassphraseFields() {
+	passphrase := &pod.options.Passphrase
 
-	pod.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			pod.onCancel()
-			return nil
+	pod.form.AddInputField("Words (3-20)", strconv.Itoa(passphrase.Words), 10, digitsOnly(2), func(text string) {
+		if words, err := strconv.Atoi(text); err == nil {
+			passphrase.Words = words
+			pod.updateEntropy()
 		}
-		return event
 	})
+
+	pod.form.AddInputField("Separator", passphrase.Separator, 10, nil, func(text string) {
+		passphrase.Separator = text
+	})
+
+	pod.form.AddCheckbox("Capitalize words", passphrase.Capitalize, func(checked bool) {
+		passphrase.Capitalize = checked
+	})
+
+	pod.form.AddCheckbox("Add a digit", passphrase.IncludeDigit, func(checked bool) {
+		passphrase.IncludeDigit = checked
+		pod.updateEntropy()
+	})
+
+	pod.form.AddCheckbox("Add a symbol", passphrase.IncludeSymbol, func(checked bool) {
+		passphrase.IncludeSymbol = checked
+		pod.updateEntropy()
+	})
+}
+
+func (pod *PasswordOptionsDialog) updateEntropy() {
+	entropy, ok := pod.form.GetFormItemByLabel("Entropy").(*tview.TextView)
+	if !ok {
+		return
+	}
+	if err := pod.options.Validate(); err != nil {
+		entropy.SetText("[gray]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	entropy.SetText(fmt.Sprintf("%.0f bits", pod.app.passwordGen.Entropy(pod.options)))
 }
 
 func (pod *PasswordOptionsDialog) setupModal() {
@@ -86,8 +190,8 @@ func (pod *PasswordOptionsDialog) setupModal() {
 		AddItem(nil, 0, 1, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
 			AddItem(nil, 0, 1, false).
-			AddItem(pod.form, 16, 0, true).
-			AddItem(nil, 0, 1, false), 45, 0, true).
+			AddItem(pod.form, 22, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
 		AddItem(nil, 0, 1, false)
 }
 
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ritarock/passvault/domain"
+	"github.com/rivo/tview"
+)
+
+type TrashView struct {
+	app       *App
+	container *tview.Flex
+	table     *tview.Table
+	help      *tview.TextView
+	entries   []*domain.TrashedEntry
+}
+
+func NewTrashView(app *App) *TrashView {
+	tv := &TrashView{
+		app:   app,
+		table: tview.NewTable(),
+		help:  tview.NewTextView(),
+	}
+
+	tv.setupTable()
+	tv.setupHelp()
+	tv.setupContainer()
+
+	return tv
+}
+
+func (tv *TrashView) setupTable() {
+	tv.table.SetBorder(true).
+		SetTitle(" Trash ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(ColorPrimary)
+
+	tv.table.SetSelectable(true, false)
+	tv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(ColorPrimary).
+		Foreground(tcell.ColorWhite))
+
+	tv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'r':
+			tv.restoreSelected()
+			return nil
+		case 'p':
+			tv.purgeSelected()
+			return nil
+		case 'E':
+			tv.emptyTrash()
+			return nil
+		case 'q':
+			tv.app.ShowList()
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape:
+			tv.app.ShowList()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (tv *TrashView) setupHelp() {
+	tv.help.SetText("[r] Restore  [p] Purge  [E] Empty Trash  [ESC] Back").
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(ColorSecondary)
+}
+
+func (tv *TrashView) setupContainer() {
+	tv.container = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tv.table, 0, 1, true).
+		AddItem(tv.help, 1, 0, false)
+}
+
+func (tv *TrashView) GetPrimitive() tview.Primitive {
+	return tv.container
+}
+
+func (tv *TrashView) Refresh() {
+	entries, err := tv.app.listTrashUc.Execute()
+	if err != nil {
+		tv.app.ShowError(fmt.Sprintf("Failed to load trash: %v", err))
+		return
+	}
+
+	tv.entries = entries
+	tv.renderTable()
+}
+
+func (tv *TrashView) renderTable() {
+	tv.table.Clear()
+
+	tv.table.SetCell(0, 0, tview.NewTableCell("Title").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+	tv.table.SetCell(0, 1, tview.NewTableCell("Username").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+	tv.table.SetCell(0, 2, tview.NewTableCell("URL").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+	tv.table.SetCell(0, 3, tview.NewTableCell("DeletedAt").
+		SetTextColor(ColorPrimary).
+		SetSelectable(false))
+
+	for i, trashed := range tv.entries {
+		row := i + 1
+		tv.table.SetCell(row, 0, tview.NewTableCell(trashed.Entry.Title).
+			SetTextColor(tcell.ColorWhite))
+		tv.table.SetCell(row, 1, tview.NewTableCell(trashed.Entry.Username).
+			SetTextColor(ColorSecondary))
+		tv.table.SetCell(row, 2, tview.NewTableCell(trashed.Entry.URL).
+			SetTextColor(ColorSecondary))
+		tv.table.SetCell(row, 3, tview.NewTableCell(trashed.DeletedAt.Format("2006-01-02 15:04")).
+			SetTextColor(ColorSecondary))
+	}
+
+	if len(tv.entries) == 0 {
+		tv.table.SetCell(1, 0, tview.NewTableCell("The trash is empty.").
+			SetTextColor(ColorSecondary).
+			SetAlign(tview.AlignCenter))
+	}
+
+	tv.table.Select(1, 0)
+}
+
+func (tv *TrashView) selected() *domain.TrashedEntry {
+	row, _ := tv.table.GetSelection()
+	index := row - 1
+	if index < 0 || index >= len(tv.entries) {
+		return nil
+	}
+	return tv.entries[index]
+}
+
+func (tv *TrashView) restoreSelected() {
+	trashed := tv.selected()
+	if trashed == nil {
+		return
+	}
+
+	if err := tv.app.restoreEntryUc.Execute(trashed.Entry.ID); err != nil {
+		tv.app.ShowError(fmt.Sprintf("Failed to restore entry: %v", err))
+		return
+	}
+	tv.Refresh()
+}
+
+func (tv *TrashView) purgeSelected() {
+	trashed := tv.selected()
+	if trashed == nil {
+		return
+	}
+
+	tv.app.ShowConfirm(
+		fmt.Sprintf("Permanently delete '%s'?", trashed.Entry.Title),
+		func() {
+			if err := tv.app.purgeEntryUc.Execute(trashed.Entry.ID); err != nil {
+				tv.app.ShowError(fmt.Sprintf("Failed to purge entry: %v", err))
+				return
+			}
+			tv.Refresh()
+		},
+	)
+}
+
+func (tv *TrashView) emptyTrash() {
+	if len(tv.entries) == 0 {
+		return
+	}
+
+	tv.app.ShowConfirm(
+		fmt.Sprintf("Permanently delete all %d entries in the trash?", len(tv.entries)),
+		func() {
+			if _, err := tv.app.purgeTrashUc.Execute(0); err != nil {
+				tv.app.ShowError(fmt.Sprintf("Failed to empty trash: %v", err))
+				return
+			}
+			tv.Refresh()
+		},
+	)
+}